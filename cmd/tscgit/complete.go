@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	runlesson "github.com/rohit746/tscgit/internal/run"
+)
+
+func completionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "completion",
+		Args:  "<" + strings.Join(cli.Shells, "|") + ">",
+		Short: "Print a shell completion script",
+		Long: `Prints a completion script for commands, flags, lesson and script IDs,
+including scripts from --pack files and lessons.paths. The script asks
+tscgit for candidates on every tab press, so new lessons complete without
+regenerating it.`,
+		Examples: []cli.Example{
+			{Command: "source <(tscgit completion bash)", Comment: "in ~/.bashrc"},
+			{Command: "source <(tscgit completion zsh)", Comment: "in ~/.zshrc"},
+			{Command: "tscgit completion fish > ~/.config/fish/completions/tscgit.fish"},
+			{Command: "tscgit completion powershell | Out-String | Invoke-Expression", Comment: "in $PROFILE"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					if len(ctx.Args) != 1 {
+						return ctx.UsageErrorf("completion takes exactly one shell: %s", strings.Join(cli.Shells, ", "))
+					}
+					script, err := cli.CompletionScript(ctx.Args[0], "tscgit")
+					if err != nil {
+						return ctx.Errorf("%v", err)
+					}
+					ctx.Stdout.Write([]byte(script))
+					return cli.ExitOK
+				},
+				Args: func(ctx *cli.Context, pos int) []string {
+					if pos > 0 {
+						return nil
+					}
+					return cli.Shells
+				},
+			}
+		},
+	}
+}
+
+func lessonIDs() []string {
+	var out []string
+	for _, l := range lessons.List() {
+		out = append(out, l.ID+"\t"+l.Title)
+	}
+	return out
+}
+
+func scriptIDs() []string {
+	var out []string
+	for _, s := range runlesson.List() {
+		out = append(out, s.ID+"\t"+s.Title)
+	}
+	return out
+}
+
+// revisions completes HEAD and the refs of the --path or current
+// repository.
+func revisions(ctx *cli.Context, pos int) []string {
+	if pos > 0 {
+		return nil
+	}
+	out := []string{"HEAD"}
+	tctx, cancel := lessons.TimeoutContext()
+	defer cancel()
+	repo, err := gitutil.Open(tctx, ctx.Globals.Path)
+	if err != nil {
+		return out
+	}
+	refs, _ := repo.Refs(tctx)
+	for _, r := range refs {
+		out = append(out, r.Name)
+	}
+	return out
+}
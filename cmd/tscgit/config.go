@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/config"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/progress"
+	runlesson "github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// settings is the effective configuration for this invocation. Flags
+// default to its values so they override every other layer.
+var settings = config.Defaults()
+
+// settingsErr holds a configuration error until the command is known, so
+// tscgit config can still be used to repair a broken file.
+var settingsErr error
+
+// configure loads the configuration layers, using --config when given and
+// looking for .tscgit.toml from --path, and applies them before any flag is
+// parsed.
+func configure(g *cli.Globals) {
+	cfg, err := config.Load(config.Options{UserFile: g.Config, Dir: g.Path})
+	if err != nil {
+		settingsErr = err
+		return
+	}
+	settings = cfg
+	applySettings(cfg)
+	if g.Path == "" {
+		g.Path = cfg.Repo.Path
+	}
+	g.DefaultFormat = cfg.Output.Format
+}
+
+// before reports configuration errors, applies --no-color and starts
+// tracing for --debug and --trace.
+func before(ctx *cli.Context) error {
+	if settingsErr != nil {
+		if !strings.HasPrefix(ctx.Command.Path(), "tscgit config") {
+			return settingsErr
+		}
+		fmt.Fprintf(ctx.Stderr, "warning: %v\n", settingsErr)
+	}
+	if ctx.Globals.NoColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	return startTrace(ctx.Globals)
+}
+
+func applySettings(cfg *config.Config) {
+	lessons.CheckTimeout = time.Duration(cfg.Timeouts.Check)
+	verify.DefaultOptions.CheckTimeout = lessons.CheckTimeout
+	runlesson.StepTimeout = time.Duration(cfg.Timeouts.Step)
+	runlesson.TeardownTimeout = time.Duration(cfg.Timeouts.Teardown)
+	lessons.SetDefaultBranch(cfg.Branches.Default)
+
+	switch cfg.UI.Color {
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case "always":
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+	switch cfg.UI.Theme {
+	case "dark":
+		lipgloss.SetHasDarkBackground(true)
+	case "light":
+		lipgloss.SetHasDarkBackground(false)
+	}
+}
+
+// loadProgress opens the progress store configured by progress.path.
+func loadProgress() (*progress.Store, error) {
+	if settings.Progress.Path != "" {
+		return progress.Load(settings.Progress.Path)
+	}
+	return progress.LoadDefault()
+}
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Short: "Show or change settings",
+		Long: `Settings are layered: built-in defaults, the user config file, the
+nearest .tscgit.toml between the current directory and the repository
+root, TSCGIT_* environment variables and finally command-line flags.`,
+		Commands: []*cli.Command{
+			{
+				Name:    "list",
+				Short:   "Show every setting and where it came from",
+				Formats: []string{formatText, formatJSON},
+				Define: func(fs *flag.FlagSet) cli.Action {
+					return cli.Action{Run: handleConfigList}
+				},
+			},
+			{
+				Name:     "get",
+				Args:     "<key>",
+				Short:    "Print one setting",
+				Examples: []cli.Example{{Command: "tscgit config get timeouts.check"}},
+				Define: func(fs *flag.FlagSet) cli.Action {
+					return cli.Action{Run: handleConfigGet, Args: configKeys}
+				},
+			},
+			{
+				Name:  "set",
+				Args:  "<key> <value...>",
+				Short: "Change a setting in the user or repository config file",
+				Long: `Writes key = value to the user config file, or with --repo to the
+repository's .tscgit.toml. List keys such as lessons.paths take several
+values.`,
+				Examples: []cli.Example{
+					{Command: "tscgit config set timeouts.check 10s"},
+					{Command: "tscgit config set --repo output.format text", Comment: "for everyone using this repository"},
+					{Command: "tscgit config set lessons.paths ~/packs /srv/course/intro.json"},
+				},
+				Define: func(fs *flag.FlagSet) cli.Action {
+					repoScope := fs.Bool("repo", false, "write to the repository's "+config.RepoFileName+" instead of the user config file")
+					return cli.Action{
+						Run: func(ctx *cli.Context) int {
+							return handleConfigSet(ctx, *repoScope)
+						},
+						Args: configKeys,
+					}
+				},
+			},
+		},
+	}
+}
+
+func handleConfigList(ctx *cli.Context) int {
+	entries := settings.List()
+	if ctx.Format() == formatJSON {
+		if err := writeJSON(ctx.Stdout, entries); err != nil {
+			return ctx.Errorf("%v", err)
+		}
+		return cli.ExitOK
+	}
+	for _, e := range entries {
+		fmt.Fprintf(ctx.Stdout, "%-18s = %-12q # %s\n", e.Key, e.Value, e.Source)
+	}
+	return cli.ExitOK
+}
+
+func handleConfigGet(ctx *cli.Context) int {
+	if len(ctx.Args) != 1 {
+		return ctx.UsageErrorf("config get takes exactly one key")
+	}
+	value, err := settings.Get(ctx.Args[0])
+	if err != nil {
+		return ctx.Errorf("%v\nKnown keys: %s", err, strings.Join(config.Keys(), ", "))
+	}
+	fmt.Fprintln(ctx.Stdout, value)
+	return cli.ExitOK
+}
+
+func handleConfigSet(ctx *cli.Context, repoScope bool) int {
+	if len(ctx.Args) < 2 {
+		return ctx.UsageErrorf("config set needs a key and a value")
+	}
+	key := ctx.Args[0]
+	if repoScope && config.UserOnly(key) {
+		return ctx.UsageErrorf("%s cannot be set with --repo; set it in the user config file", key)
+	}
+	// Several values are accepted for list keys such as lessons.paths.
+	value := strings.Join(ctx.Args[1:], string(os.PathListSeparator))
+
+	path := ctx.Globals.Config
+	var err error
+	if repoScope || path == "" {
+		path, err = configTarget(repoScope, ctx.Globals.Path)
+	}
+	if err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	if err := config.WriteKey(path, key, value); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	fmt.Fprintf(ctx.Stdout, "%s = %q written to %s\n", key, value, path)
+	return cli.ExitOK
+}
+
+// configKeys completes config keys and, after set's key, its values.
+func configKeys(ctx *cli.Context, pos int) []string {
+	switch pos {
+	case 0:
+		var out []string
+		for _, k := range config.Keys() {
+			out = append(out, k+"\t"+config.Help(k))
+		}
+		return out
+	case 1:
+		if ctx.Command.Name != "set" {
+			return nil
+		}
+		if allowed := config.Allowed(ctx.Args[0]); allowed != nil {
+			return allowed
+		}
+		switch ctx.Args[0] {
+		case "repo.path", "progress.path", "lessons.paths":
+			return []string{cli.CompleteFiles}
+		}
+	}
+	return nil
+}
+
+// configTarget returns the file config set writes to: the user file, or
+// the .tscgit.toml for the repository containing dir.
+func configTarget(repoScope bool, dir string) (string, error) {
+	if !repoScope {
+		return config.Options{}.UserPath()
+	}
+	if path, ok := config.FindRepoFile(dir); ok {
+		return path, nil
+	}
+	repo, err := gitutil.Open(context.Background(), dir)
+	if err != nil {
+		return "", fmt.Errorf("--repo needs a git repository: %s", verify.Describe(err))
+	}
+	return filepath.Join(repo.Root, config.RepoFileName), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/rohit746/tscgit/internal/cli"
+)
+
+// docsCommand regenerates docs/cli.md and docs/man from the command tree;
+// see the go:generate lines in main.go.
+func docsCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "docs",
+		Short:  "Generate the CLI reference",
+		Hidden: true,
+		Commands: []*cli.Command{
+			{
+				Name:  "markdown",
+				Short: "Write the markdown reference",
+				Define: func(fs *flag.FlagSet) cli.Action {
+					out := fs.String("out", "", "write to `FILE` instead of standard output")
+					return cli.Action{Run: func(ctx *cli.Context) int {
+						w := ctx.Stdout
+						if *out != "" {
+							f, err := os.Create(*out)
+							if err != nil {
+								return ctx.Errorf("%v", err)
+							}
+							defer f.Close()
+							w = f
+						}
+						if err := cli.WriteMarkdown(w, rootCommand()); err != nil {
+							return ctx.Errorf("%v", err)
+						}
+						return cli.ExitOK
+					}}
+				},
+			},
+			{
+				Name:  "man",
+				Short: "Write man pages",
+				Define: func(fs *flag.FlagSet) cli.Action {
+					out := fs.String("out", "man", "write pages into `DIR`")
+					return cli.Action{Run: func(ctx *cli.Context) int {
+						if err := cli.WriteManPages(*out, rootCommand()); err != nil {
+							return ctx.Errorf("%v", err)
+						}
+						return cli.ExitOK
+					}}
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/grade"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/similarity"
+	"github.com/rohit746/tscgit/internal/submit"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+const formatCSV = "csv"
+
+func gradeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "grade",
+		Args:  "<repos-dir|roster.csv|submission> <lesson-id...>",
+		Short: "Verify lessons against every student repository and write a gradebook",
+		Long: `Grades a class at once. The first argument is either a directory whose
+subdirectories are student repositories, named after the student, or a
+CSV roster with a header row naming a student column (student, name, login
+or github_username) and a repository column (repo, path or repository).
+Relative roster paths are resolved against the roster's directory.
+
+With --bundle, submissions are files made by tscgit submit instead of
+repositories: every *.tscgit file in the directory, named after the file,
+the files a roster lists, or a single submission file. Each one is
+restored into a temporary directory of its own and removed after grading.
+
+With --similarity, every repository is also fingerprinted by its root
+commits, commit snapshots (tree hashes), commit messages with their
+timestamps and the contents of its files, and every pair of students is
+ranked by what they share. Anything most of the class shares, such as a
+starter repository, is ignored. The ranked report is written to the file
+as JSON, and likely copies are listed on stderr. A high score is a reason
+to look closer, not proof.
+
+Repositories are graded concurrently. Missing directories, folders that
+are not git repositories and broken repositories get an error in their
+row instead of stopping the run; the exit code is 0 once the gradebook is
+written.`,
+		Formats: []string{formatCSV, formatJSON},
+		Examples: []cli.Example{
+			{Command: "tscgit grade submissions/ init-basics branch-basics --out grades.csv"},
+			{Command: "tscgit grade roster.csv init-basics --format json", Comment: "full per-check reports"},
+			{Command: "tscgit grade --bundle inbox/ init-basics --out grades.csv", Comment: "grade emailed submission files"},
+			{Command: "tscgit grade submissions/ init-basics --out grades.csv --similarity similar.json", Comment: "also flag likely copies"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			jobs := fs.Int("jobs", runtime.NumCPU(), "grade up to `N` repositories at once")
+			out := fs.String("out", "", "write the gradebook to `FILE` instead of stdout")
+			bundle := fs.Bool("bundle", false, "grade submission files from tscgit submit")
+			similar := fs.String("similarity", "", "compare repositories and write the ranked similarity report to `FILE`")
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleGrade(ctx, gradeOptions{jobs: *jobs, out: *out, bundle: *bundle, similarity: *similar})
+				},
+				Args: func(ctx *cli.Context, pos int) []string {
+					if pos == 0 {
+						return []string{cli.CompleteFiles}
+					}
+					return lessonIDs()
+				},
+				FlagArgs: map[string]func(*cli.Context) []string{
+					"out":        func(*cli.Context) []string { return []string{cli.CompleteFiles} },
+					"similarity": func(*cli.Context) []string { return []string{cli.CompleteFiles} },
+				},
+			}
+		},
+	}
+}
+
+type gradeOptions struct {
+	jobs       int
+	out        string
+	bundle     bool
+	similarity string
+}
+
+func handleGrade(ctx *cli.Context, opts gradeOptions) int {
+	if len(ctx.Args) < 2 {
+		return ctx.UsageErrorf("grade needs a submissions directory or roster and at least one lesson ID")
+	}
+	if opts.jobs < 1 {
+		return ctx.UsageErrorf("--jobs must be at least 1, got %d", opts.jobs)
+	}
+	var selected []*lessons.Lesson
+	for _, id := range ctx.Args[1:] {
+		lesson, err := lessons.Get(id)
+		if err != nil {
+			return ctx.Errorf("%v", err)
+		}
+		selected = append(selected, lesson)
+	}
+
+	subs, err := loadSubmissions(ctx.Args[0], opts.bundle)
+	if err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	if len(subs) == 0 {
+		return ctx.Errorf("no submissions found in %s", ctx.Args[0])
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	done := 0
+	book := grade.Run(sigCtx, subs, grade.Options{
+		Lessons:     selected,
+		Workers:     opts.jobs,
+		Verify:      verify.DefaultOptions,
+		Fingerprint: opts.similarity != "",
+		Progress: func(res grade.Result) {
+			done++
+			if res.Error != "" {
+				fmt.Fprintf(ctx.Stderr, "[%d/%d] %s %s: %s\n", done, len(subs), statusGlyph(verify.StatusError), res.Student, res.Error)
+				return
+			}
+			status := verify.StatusPassed
+			if res.Score.Points < res.Score.Max {
+				status = verify.StatusFailed
+			}
+			fmt.Fprintf(ctx.Stderr, "[%d/%d] %s %s: %d/%d points (%.0f%%)\n", done, len(subs), statusGlyph(status), res.Student, res.Score.Points, res.Score.Max, res.Score.Percent)
+		},
+	})
+
+	if err := writeGradebook(ctx, book, opts.out); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	if opts.similarity != "" {
+		if err := writeSimilarity(ctx, book.Similarity(), opts.similarity); err != nil {
+			return ctx.Errorf("%v", err)
+		}
+	}
+	if n := book.Errors(); n > 0 {
+		fmt.Fprintf(ctx.Stderr, "%d of %d repositories could not be graded; see the error column.\n", n, len(subs))
+	}
+	return cli.ExitOK
+}
+
+// writeGradebook writes book in the selected format to outPath, or to
+// stdout when outPath is empty.
+func writeGradebook(ctx *cli.Context, book *grade.Gradebook, outPath string) error {
+	write := book.WriteCSV
+	if ctx.Format() == formatJSON {
+		write = book.WriteJSON
+	}
+	if outPath == "" {
+		return write(ctx.Stdout)
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("cannot write gradebook: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cannot write gradebook: %w", err)
+	}
+	return nil
+}
+
+// writeSimilarity writes the JSON report to path and lists likely copies on
+// stderr.
+func writeSimilarity(ctx *cli.Context, report similarity.Report, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot write similarity report: %w", err)
+	}
+	if err := writeJSON(f, report); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot write similarity report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cannot write similarity report: %w", err)
+	}
+
+	likely := 0
+	for _, p := range report.Pairs {
+		if p.Score < similarity.Likely {
+			break
+		}
+		if likely == 0 {
+			fmt.Fprintln(ctx.Stderr, "Likely copies:")
+		}
+		likely++
+		fmt.Fprintf(ctx.Stderr, "  %5.1f  %s and %s: %s\n", p.Score, p.A, p.B, p.Describe())
+	}
+	if likely == 0 {
+		fmt.Fprintf(ctx.Stderr, "No likely copies among %d repositories.\n", report.Compared)
+	}
+	fmt.Fprintf(ctx.Stderr, "Similarity report written to %s\n", path)
+	return nil
+}
+
+// loadSubmissions reads a roster file or lists a directory of repositories.
+// With bundle set it lists submission files instead, and a file that is not
+// a .csv roster is graded as a single submission.
+func loadSubmissions(source string, bundle bool) ([]grade.Submission, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read submissions: %w", err)
+	}
+	switch {
+	case info.IsDir() && bundle:
+		return grade.DiscoverBundles(source)
+	case info.IsDir():
+		return grade.Discover(source)
+	case bundle && !strings.EqualFold(filepath.Ext(source), ".csv"):
+		student := strings.TrimSuffix(filepath.Base(source), submit.Ext)
+		return []grade.Submission{{Student: student, Path: source, Bundle: true}}, nil
+	}
+	subs, err := grade.LoadRoster(source)
+	for i := range subs {
+		subs[i].Bundle = bundle
+	}
+	return subs, err
+}
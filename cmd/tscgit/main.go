@@ -6,15 +6,24 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 
+	"github.com/rohit746/tscgit/internal/autograde"
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/doctor"
 	"github.com/rohit746/tscgit/internal/gitutil"
 	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/progress"
 	runlesson "github.com/rohit746/tscgit/internal/run"
+	browseui "github.com/rohit746/tscgit/internal/ui/browse"
+	exploreui "github.com/rohit746/tscgit/internal/ui/explore"
 	runui "github.com/rohit746/tscgit/internal/ui/run"
 	verifyui "github.com/rohit746/tscgit/internal/ui/verify"
+	"github.com/rohit746/tscgit/internal/verify"
 )
 
 // Version information set at build time via ldflags
@@ -24,125 +33,542 @@ var (
 	Date    = "unknown"
 )
 
+// The CLI reference in docs/ is generated from the command tree.
+//go:generate go run . docs markdown --out ../../docs/cli.md
+//go:generate go run . docs man --out ../../docs/man
+
 func main() {
 	os.Exit(run(os.Args[1:]))
 }
 
 func run(args []string) int {
-	if len(args) == 0 {
-		printUsage()
-		return 1
-	}
-
-	switch args[0] {
-	case "help", "--help", "-h":
-		printUsage()
-		return 0
-	case "version", "--version", "-v":
-		printVersion()
-		return 0
-	case "lessons", "list":
-		printLessons()
-		return 0
-	case "verify":
-		return handleVerify(args[1:])
-	case "run":
-		return handleRun(args[1:])
-	default:
-		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
-		printUsage()
-		return 1
-	}
-}
-
-func handleVerify(args []string) int {
-	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
-	fs.SetOutput(os.Stdout)
-	cwd := fs.String("path", "", "path to repository (defaults to current directory)")
-	if err := fs.Parse(args); err != nil {
-		if errors.Is(err, flag.ErrHelp) {
-			fs.PrintDefaults()
-			return 0
-		}
-		return 1
-	}
-	remaining := fs.Args()
-	if len(remaining) == 0 {
-		fmt.Fprintln(os.Stderr, "verify requires a lesson ID. Try 'tscgit lessons' to list available options.")
-		return 1
-	}
-
-	lessonID := remaining[0]
-	lesson, err := lessons.Get(lessonID)
+	if len(args) > 0 && (args[0] == "--version" || args[0] == "-v") {
+		args = append([]string{"version"}, args[1:]...)
+	}
+	program := &cli.Program{
+		Root:      rootCommand(),
+		Configure: configure,
+		Before:    before,
+	}
+	defer stopTrace()
+	return program.Run(args)
+}
+
+func rootCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tscgit",
+		Short: "A Git practice companion",
+		Long: `tscgit is a Git practice companion. It verifies lessons against your own
+repositories, runs guided terminal scripts and lets you look inside the
+object database. Run without a command in a terminal to browse lessons.`,
+		Define: func(fs *flag.FlagSet) cli.Action {
+			return cli.Action{Run: func(ctx *cli.Context) int {
+				if !isatty.IsTerminal(os.Stdout.Fd()) {
+					cli.WriteHelp(ctx.Stderr, ctx.Command)
+					return cli.ExitError
+				}
+				return handleBrowse(ctx, &packOptions{trusted: new(bool)})
+			}}
+		},
+		Commands: []*cli.Command{
+			lessonsCommand(),
+			listCommand(),
+			verifyCommand(),
+			gradeCommand(),
+			serveCommand(),
+			runCommand(),
+			lintCommand(),
+			browseCommand(),
+			exploreCommand(),
+			doctorCommand(),
+			submitCommand(),
+			receiptCommand(),
+			configCommand(),
+			completionCommand(),
+			versionCommand(),
+			docsCommand(),
+		},
+	}
+}
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "verify",
+		Args:  "<lesson-id>",
+		Short: "Verify lesson progress in a repository",
+		Long: `Runs the checks of a verification lesson against a repository and shows
+the results as they arrive. Progress is saved so the browser can show it.`,
+		Formats: []string{formatTUI, formatText, formatJSON, autograde.FormatClassroom, autograde.FormatGradescope},
+		Examples: []cli.Example{
+			{Command: "tscgit verify init-basics"},
+			{Command: "tscgit verify --path ~/practice branch-basics", Comment: "check another repository"},
+			{Command: "tscgit verify --format json --min-score 80 staging-basics", Comment: "autograder output; exit 0 at 80% or more"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			out := outputFlags(fs)
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleVerify(ctx, out)
+				},
+				Args: func(ctx *cli.Context, pos int) []string {
+					if pos > 0 {
+						return nil
+					}
+					return lessonIDs()
+				},
+			}
+		},
+	}
+}
+
+func handleVerify(ctx *cli.Context, out *outputOptions) int {
+	if len(ctx.Args) == 0 {
+		return ctx.UsageErrorf("verify requires a lesson ID. Try 'tscgit lessons' to list available options.")
+	}
+	if err := out.validate(ctx); err != nil {
+		return ctx.UsageErrorf("%v", err)
+	}
+	debug := ctx.Globals.Debug
+
+	lesson, err := lessons.Get(ctx.Args[0])
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		return 1
+		return ctx.Errorf("%v", err)
 	}
 
-	repo, err := gitutil.Open(context.Background(), *cwd)
+	repo, err := gitutil.Open(context.Background(), ctx.Globals.Path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to open git repository: %v\n", err)
-		return 1
+		fmt.Fprintf(ctx.Stderr, "failed to open git repository: %s\n", verify.Describe(err))
+		if debug {
+			fmt.Fprintf(ctx.Stderr, "%v\n", err)
+		}
+		return cli.ExitError
+	}
+
+	if out.format != formatTUI {
+		sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		results, err := verify.Run(sigCtx, lesson, repo, nil)
+		report := verify.NewReport(lesson, results)
+		if werr := writeVerifyReport(ctx.Stdout, out.format, report, err); werr != nil {
+			return ctx.Errorf("%v", werr)
+		}
+		if debug {
+			printDebugErrors(results)
+		}
+		if err != nil {
+			return ctx.Errorf("verification failed: %v", err)
+		}
+		rec := progress.FromVerify(report, repo.Root)
+		recordProgress(rec)
+		if !writeReceipt(ctx, out, rec, repo) {
+			return cli.ExitError
+		}
+		return out.exitCode(report.Passed, report.Score.Percent)
 	}
 
 	model := verifyui.NewModel(lesson, repo)
+	model.SetDebug(debug)
 	program := tea.NewProgram(model)
 	if _, err := program.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "verification UI failed: %v\n", err)
-		return 1
+		return ctx.Errorf("verification UI failed: %v", err)
 	}
+	if model.Done() {
+		rec := progress.FromVerify(verify.NewReport(lesson, model.Results()), repo.Root)
+		recordProgress(rec)
+		if !writeReceipt(ctx, out, rec, repo) {
+			return cli.ExitError
+		}
+	}
+
+	return out.exitCode(model.AllPassed(), model.Score().Percent)
+}
 
-	if model.AllPassed() {
-		return 0
+// listCommand keeps tscgit list, the original name of tscgit lessons,
+// working for existing scripts.
+func listCommand() *cli.Command {
+	canonical := lessonsCommand()
+	return &cli.Command{
+		Name:    "list",
+		Short:   canonical.Short,
+		Formats: canonical.Formats,
+		Hidden:  true,
+		Define:  canonical.Define,
 	}
-	return 2
 }
 
-func handleRun(args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "run requires a script ID. Try 'tscgit lessons' to list available options.")
-		return 1
+func lessonsCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "lessons",
+		Short:   "List available lessons and run scripts",
+		Formats: []string{formatText, formatJSON},
+		Examples: []cli.Example{
+			{Command: "tscgit lessons"},
+			{Command: "tscgit lessons --pack course.json", Comment: "include scripts from a pack"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			packs := packFlags(fs)
+			return cli.Action{Run: func(ctx *cli.Context) int {
+				if err := packs.load(); err != nil {
+					return ctx.Errorf("%v", err)
+				}
+				if ctx.Format() == formatJSON {
+					if err := writeJSON(ctx.Stdout, catalogEntries()); err != nil {
+						return ctx.Errorf("%v", err)
+					}
+					return cli.ExitOK
+				}
+				printLessons()
+				return cli.ExitOK
+			}}
+		},
 	}
+}
 
-	scriptID := args[0]
+func runCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "run",
+		Args:  "<script-id>",
+		Short: "Run a terminal practice script",
+		Long: `Runs the setup, graded and teardown steps of a practice script and checks
+each command's output. With --path the commands run in that directory.`,
+		Formats: []string{formatTUI, formatText, formatJSON, autograde.FormatClassroom, autograde.FormatGradescope},
+		Examples: []cli.Example{
+			{Command: "tscgit run 0", Comment: "test your setup"},
+			{Command: "tscgit run --format json 4b"},
+			{Command: "tscgit run --pack course.json intro-1", Comment: "run a script from a pack"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			packs := packFlags(fs)
+			out := outputFlags(fs)
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleRun(ctx, packs, out)
+				},
+				Args: func(ctx *cli.Context, pos int) []string {
+					if pos > 0 {
+						return nil
+					}
+					_ = packs.load()
+					return scriptIDs()
+				},
+			}
+		},
+	}
+}
+
+func handleRun(ctx *cli.Context, packs *packOptions, out *outputOptions) int {
+	if err := packs.load(); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	if len(ctx.Args) == 0 {
+		return ctx.UsageErrorf("run requires a script ID. Try 'tscgit lessons' to list available options.")
+	}
+	if err := out.validate(ctx); err != nil {
+		return ctx.UsageErrorf("%v", err)
+	}
+
+	scriptID := ctx.Args[0]
 	script, ok := runlesson.Get(scriptID)
 	if !ok {
-		fmt.Fprintf(os.Stderr, "unknown run script: %s\n", scriptID)
-		return 1
+		return ctx.Errorf("unknown run script: %s", scriptID)
+	}
+	if ctx.Globals.Path != "" {
+		if err := os.Chdir(ctx.Globals.Path); err != nil {
+			return ctx.Errorf("cannot run in %s: %v", ctx.Globals.Path, err)
+		}
+	}
+
+	if out.format != formatTUI {
+		sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		results, err := runlesson.Execute(sigCtx, script, nil)
+		report := runlesson.NewReport(script, results)
+		if werr := writeRunReport(ctx.Stdout, out.format, report, err); werr != nil {
+			return ctx.Errorf("%v", werr)
+		}
+		if err != nil && !errors.Is(err, runlesson.ErrSetupFailed) {
+			return ctx.Errorf("run failed: %v", err)
+		}
+		rec := progress.FromRun(report, "")
+		recordProgress(rec)
+		if !writeReceipt(ctx, out, rec, currentRepo()) {
+			return cli.ExitError
+		}
+		return out.exitCode(report.Passed, report.Score.Percent)
 	}
 
 	model := runui.NewModel(script)
 	program := tea.NewProgram(model)
 	if _, err := program.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "run UI failed: %v\n", err)
-		return 1
+		return ctx.Errorf("run UI failed: %v", err)
+	}
+	if model.Done() {
+		rec := progress.FromRun(runlesson.NewReport(script, model.Results()), "")
+		recordProgress(rec)
+		if !writeReceipt(ctx, out, rec, currentRepo()) {
+			return cli.ExitError
+		}
+	}
+
+	return out.exitCode(model.AllPassed(), model.Score().Percent)
+}
+
+func browseCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "browse",
+		Short: "Open the interactive lesson browser",
+		Long: `Lists lessons and run scripts with your saved progress. Type to filter,
+tab switches lists and enter starts the selection.`,
+		Formats:  []string{formatTUI},
+		Examples: []cli.Example{{Command: "tscgit browse --path ~/practice"}},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			packs := packFlags(fs)
+			return cli.Action{Run: func(ctx *cli.Context) int {
+				return handleBrowse(ctx, packs)
+			}}
+		},
+	}
+}
+
+func handleBrowse(ctx *cli.Context, packs *packOptions) int {
+	if err := packs.load(); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+
+	store, err := loadProgress()
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "warning: progress unavailable: %v\n", err)
+		store = nil
+	}
+	model := browseui.NewModel(ctx.Globals.Path, store)
+	model.SetDebug(ctx.Globals.Debug)
+	program := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		return ctx.Errorf("browser UI failed: %v", err)
 	}
+	return cli.ExitOK
+}
 
-	if model.AllPassed() {
-		return 0
+func exploreCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "explore",
+		Args:  "[rev]",
+		Short: "Walk commit, tree and blob objects",
+		Long: `Opens the object explorer at HEAD or the given revision. Enter follows a
+link from a commit to its tree and parents, from a tree to its entries.`,
+		Formats: []string{formatTUI},
+		Examples: []cli.Example{
+			{Command: "tscgit explore"},
+			{Command: "tscgit explore HEAD:contents.md", Comment: "open a blob directly"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			return cli.Action{
+				Run:  handleExplore,
+				Args: revisions,
+			}
+		},
 	}
-	return 2
 }
 
-func printUsage() {
-	fmt.Fprintf(os.Stdout, `tscgit is a Git practice companion.
+func handleExplore(ctx *cli.Context) int {
+	rev := "HEAD"
+	if len(ctx.Args) > 0 {
+		rev = ctx.Args[0]
+	}
+
+	bg := context.Background()
+	repo, err := gitutil.Open(bg, ctx.Globals.Path)
+	if err != nil {
+		return ctx.Errorf("failed to open git repository: %s", verify.Describe(err))
+	}
+	if _, err := repo.ResolveObject(bg, rev); err != nil {
+		return ctx.Errorf("cannot find object %q: %v", rev, err)
+	}
 
-Usage:
-  tscgit lessons             List available lessons
-  tscgit verify <lesson-id>  Verify lesson progress in the current repo
-  tscgit run <script-id>     Run terminal practice scripts
-  tscgit version             Show version information
+	program := tea.NewProgram(exploreui.NewModel(repo, rev), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		return ctx.Errorf("explorer UI failed: %v", err)
+	}
+	return cli.ExitOK
+}
 
-Flags:
-  tscgit verify [-path DIR] <lesson-id>
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Short: "Diagnose git, shell, identity and terminal setup",
+		Long: `Checks everything tscgit relies on and suggests a fix for each problem.
+Exits with 2 when something must be fixed.`,
+		Formats: []string{formatText, formatJSON},
+		Examples: []cli.Example{
+			{Command: "tscgit doctor"},
+			{Command: "tscgit doctor --format json > doctor.json", Comment: "collect setup reports"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			return cli.Action{Run: handleDoctor}
+		},
+	}
+}
 
-`)
+func handleDoctor(ctx *cli.Context) int {
+	report := doctor.Run(context.Background(), doctor.Options{
+		Path:          ctx.Globals.Path,
+		ProgressPath:  settings.Progress.Path,
+		DefaultBranch: settings.Branches.Default,
+	})
+	if err := writeDoctorReport(ctx.Stdout, ctx.Format(), report); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	if !report.OK {
+		return cli.ExitFailed
+	}
+	return cli.ExitOK
 }
 
-func printVersion() {
-	fmt.Fprintf(os.Stdout, "tscgit version %s\n", Version)
-	fmt.Fprintf(os.Stdout, "commit: %s\n", Commit)
-	fmt.Fprintf(os.Stdout, "built: %s\n", Date)
+// recordProgress stores rec in the configured progress file. Failures are
+// reported but never change the command's exit code.
+func recordProgress(rec progress.Record) {
+	store, err := loadProgress()
+	if err == nil {
+		store.Put(rec)
+		err = store.Save()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not save progress: %v\n", err)
+	}
+}
+
+func lintCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "lint",
+		Args:  "[script-id...]",
+		Short: "Check run scripts against the command policy",
+		Long: `Checks every step of the given scripts, or of all registered scripts,
+against its command policy without running anything. Exits with 2 when a
+step would be refused.`,
+		Examples: []cli.Example{
+			{Command: "tscgit lint"},
+			{Command: "tscgit lint --pack course.json", Comment: "vet a pack before sharing it"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			packs := packFlags(fs)
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleLint(ctx, packs)
+				},
+				Args: func(ctx *cli.Context, pos int) []string {
+					_ = packs.load()
+					return scriptIDs()
+				},
+			}
+		},
+	}
+}
+
+func handleLint(ctx *cli.Context, packs *packOptions) int {
+	if err := packs.load(); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+
+	scripts := runlesson.List()
+	if len(ctx.Args) > 0 {
+		scripts = scripts[:0]
+		for _, id := range ctx.Args {
+			script, ok := runlesson.Get(id)
+			if !ok {
+				return ctx.Errorf("unknown run script: %s", id)
+			}
+			scripts = append(scripts, script)
+		}
+	}
+
+	found := 0
+	for _, script := range scripts {
+		for _, v := range runlesson.Lint(script) {
+			found++
+			fmt.Fprintf(ctx.Stdout, "%s %s step %d: %s\n    %s\n", v.ScriptID, v.Phase, v.Step, v.Reason, v.Command)
+		}
+	}
+	if found > 0 {
+		fmt.Fprintf(ctx.Stdout, "\n%d policy violation(s) found.\n", found)
+		return cli.ExitFailed
+	}
+	fmt.Fprintf(ctx.Stdout, "%d script(s) checked, no policy violations.\n", len(scripts))
+	return cli.ExitOK
+}
+
+// packOptions collects the --pack and --trust flags shared by commands that
+// work with run scripts.
+type packOptions struct {
+	paths   []string
+	trusted *bool
+	loaded  bool
+}
+
+func packFlags(fs *flag.FlagSet) *packOptions {
+	opts := &packOptions{}
+	fs.Func("pack", "load run scripts from a pack `FILE` (repeatable)", func(v string) error {
+		opts.paths = append(opts.paths, v)
+		return nil
+	})
+	opts.trusted = fs.Bool("trust", false, "trust loaded packs and lift the command allowlist")
+	return opts
+}
+
+// load registers the packs listed in lessons.paths followed by those given
+// with --pack. Later calls do nothing.
+func (o *packOptions) load() error {
+	if o.loaded {
+		return nil
+	}
+	o.loaded = true
+	configured, err := settings.PackFiles()
+	if err != nil {
+		return err
+	}
+	for _, path := range append(configured, o.paths...) {
+		pack, err := runlesson.LoadPack(path, *o.trusted)
+		if err != nil {
+			return err
+		}
+		if err := runlesson.RegisterPack(pack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func versionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "version",
+		Short: "Show version information",
+		Define: func(fs *flag.FlagSet) cli.Action {
+			return cli.Action{Run: func(ctx *cli.Context) int {
+				fmt.Fprintf(ctx.Stdout, "tscgit version %s\n", Version)
+				fmt.Fprintf(ctx.Stdout, "commit: %s\n", Commit)
+				fmt.Fprintf(ctx.Stdout, "built: %s\n", Date)
+				return cli.ExitOK
+			}}
+		},
+	}
+}
+
+// catalogEntry is one lesson or script in tscgit lessons --format json.
+type catalogEntry struct {
+	Kind        progress.Kind `json:"kind"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+}
+
+func catalogEntries() []catalogEntry {
+	var out []catalogEntry
+	for _, lesson := range lessons.List() {
+		out = append(out, catalogEntry{Kind: progress.KindLesson, ID: lesson.ID, Title: lesson.Title, Description: strings.TrimSpace(lesson.Description)})
+	}
+	for _, script := range runlesson.List() {
+		out = append(out, catalogEntry{Kind: progress.KindScript, ID: script.ID, Title: script.Title, Description: strings.TrimSpace(script.Description)})
+	}
+	return out
 }
 
 func printLessons() {
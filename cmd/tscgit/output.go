@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/autograde"
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/doctor"
+	runlesson "github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+const (
+	formatTUI  = "tui"
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// outputOptions combines the global --format with the --min-score and
+// --receipt flags shared by verify and run.
+type outputOptions struct {
+	fs          *flag.FlagSet
+	format      string
+	minScore    float64
+	minScoreSet bool
+	// receipt is where a signed completion receipt is written after a pass.
+	receipt string
+}
+
+func outputFlags(fs *flag.FlagSet) *outputOptions {
+	opts := &outputOptions{fs: fs}
+	fs.Float64Var(&opts.minScore, "min-score", 0, "exit 0 when the score is at least `PCT` percent instead of requiring every check")
+	fs.StringVar(&opts.receipt, "receipt", "", "after a pass, write the signed completion receipt to `FILE` (needs receipts.key)")
+	return opts
+}
+
+// validate resolves the output format and checks --min-score.
+func (o *outputOptions) validate(ctx *cli.Context) error {
+	o.format = ctx.Format()
+	o.fs.Visit(func(f *flag.Flag) {
+		if f.Name == "min-score" {
+			o.minScoreSet = true
+		}
+	})
+	if o.minScoreSet && (o.minScore < 0 || o.minScore > 100) {
+		return fmt.Errorf("--min-score must be between 0 and 100, got %g", o.minScore)
+	}
+	return nil
+}
+
+// exitCode maps an outcome to the process exit code: ExitOK on success,
+// ExitFailed when checks failed or the score is below --min-score.
+func (o *outputOptions) exitCode(allPassed bool, percent float64) int {
+	if o.minScoreSet {
+		if percent >= o.minScore {
+			return cli.ExitOK
+		}
+		return cli.ExitFailed
+	}
+	if allPassed {
+		return cli.ExitOK
+	}
+	return cli.ExitFailed
+}
+
+// writeVerifyReport writes report in format. runErr is the error that
+// stopped the lesson early, if any; autograder formats report it as an error.
+func writeVerifyReport(w io.Writer, format string, report verify.Report, runErr error) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, report)
+	case autograde.FormatClassroom, autograde.FormatGradescope:
+		suite := autograde.FromVerify(report)
+		suite.Err = runErr
+		return autograde.Write(w, format, suite)
+	}
+
+	fmt.Fprintf(w, "%s\n\n", report.Title)
+	for _, check := range report.Checks {
+		detail := check.Message
+		if check.Error != "" {
+			detail = check.Hint
+		}
+		fmt.Fprintf(w, "%s %s", statusGlyph(check.Status), check.Title)
+		if detail != "" {
+			fmt.Fprintf(w, " — %s", detail)
+		}
+		fmt.Fprintln(w)
+	}
+	passed := 0
+	for _, check := range report.Checks {
+		if check.Status == verify.StatusPassed {
+			passed++
+		}
+	}
+	fmt.Fprintf(w, "\n%d/%d checks passed · %d/%d points (%.0f%%)\n", passed, len(report.Checks), report.Score.Points, report.Score.Max, report.Score.Percent)
+	return nil
+}
+
+// printDebugErrors writes the raw error and any panic stack for every failed
+// check to stderr.
+func printDebugErrors(results []verify.Result) {
+	for _, res := range results {
+		err := res.Outcome.Err
+		if err == nil {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "[%s] %s: %v\n", verify.Classify(err), res.Check.ID, err)
+		var panicErr *verify.PanicError
+		if errors.As(err, &panicErr) {
+			os.Stderr.Write(panicErr.Stack)
+		}
+	}
+}
+
+func writeRunReport(w io.Writer, format string, report runlesson.Report, runErr error) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, report)
+	case autograde.FormatClassroom, autograde.FormatGradescope:
+		suite := autograde.FromRun(report)
+		suite.Err = runErr
+		return autograde.Write(w, format, suite)
+	}
+
+	fmt.Fprintf(w, "Run Lesson %s — %s\n\n", report.Script, report.Title)
+	for _, step := range report.Steps {
+		status := verify.StatusFailed
+		if step.Passed {
+			status = verify.StatusPassed
+		}
+		prefix := ""
+		if step.Phase != runlesson.PhaseCheck {
+			prefix = "[" + string(step.Phase) + "] "
+		}
+		fmt.Fprintf(w, "%s %s%s", statusGlyph(status), prefix, step.Command)
+		if len(step.Failures) > 0 {
+			fmt.Fprintf(w, " — %s", strings.Join(step.Failures, "; "))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "\n%d/%d steps passed\n", report.Score.Points, report.Score.Max)
+	return nil
+}
+
+func writeDoctorReport(w io.Writer, format string, report doctor.Report) error {
+	if format == formatJSON {
+		return writeJSON(w, report)
+	}
+
+	fmt.Fprintf(w, "tscgit doctor\n\n")
+	for _, f := range report.Findings {
+		glyph := "✔"
+		switch f.Status {
+		case doctor.StatusWarn:
+			glyph = "!"
+		case doctor.StatusFail:
+			glyph = "✘"
+		}
+		fmt.Fprintf(w, "%s %-20s %s\n", glyph, f.Title, f.Detail)
+		if f.Fix != "" {
+			fmt.Fprintf(w, "  fix: %s\n", f.Fix)
+		}
+	}
+	if report.OK {
+		fmt.Fprintf(w, "\nEverything tscgit needs is in place.\n")
+	} else {
+		fmt.Fprintf(w, "\nFix the items marked ✘ before continuing.\n")
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func statusGlyph(status string) string {
+	switch status {
+	case verify.StatusPassed:
+		return "✔"
+	case verify.StatusSkipped:
+		return "⊘"
+	default:
+		return "✘"
+	}
+}
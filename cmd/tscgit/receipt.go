@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/config"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/receipt"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+func receiptCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "receipt",
+		Short: "Create signing keys and check completion receipts",
+		Long: `After a passing verify or run, tscgit writes a completion receipt signed
+with the key named by receipts.key: an Ed25519 private key or an HMAC
+secret. The receipt records the lesson, every check result, the commit HEAD
+pointed at, the time, the tscgit version and the student's git user.name
+and user.email. Instructors check receipts with the public key or secret.
+
+Sign where students cannot read the key, for example in a CI job that
+holds it as a secret; a key on the student's machine proves little.`,
+		Commands: []*cli.Command{
+			{
+				Name:  "keygen",
+				Short: "Create an Ed25519 key pair for signing receipts",
+				Examples: []cli.Example{
+					{Command: "tscgit receipt keygen --out course", Comment: "writes course.key and course.pub"},
+				},
+				Define: func(fs *flag.FlagSet) cli.Action {
+					prefix := fs.String("out", "tscgit-receipt", "write `PREFIX`.key and PREFIX.pub")
+					return cli.Action{Run: func(ctx *cli.Context) int {
+						return handleReceiptKeygen(ctx, *prefix)
+					}}
+				},
+			},
+			{
+				Name:    "verify",
+				Args:    "<receipt.json...>",
+				Short:   "Check receipt signatures and show what they certify",
+				Formats: []string{formatText, formatJSON},
+				Long: `Checks each receipt against --key: an Ed25519 public (or private) key in
+PEM form, or the HMAC secret. --key is required, so whoever checks receipts
+names the key they trust rather than picking up receipts.key. Exits with 2 if
+any receipt is invalid.`,
+				Examples: []cli.Example{
+					{Command: "tscgit receipt verify --key course.pub receipts/*.json"},
+				},
+				Define: func(fs *flag.FlagSet) cli.Action {
+					keyPath := fs.String("key", "", "public key or HMAC secret `FILE` (required)")
+					return cli.Action{
+						Run: func(ctx *cli.Context) int {
+							return handleReceiptVerify(ctx, *keyPath)
+						},
+						Args: func(*cli.Context, int) []string { return []string{cli.CompleteFiles} },
+						FlagArgs: map[string]func(*cli.Context) []string{
+							"key": func(*cli.Context) []string { return []string{cli.CompleteFiles} },
+						},
+					}
+				},
+			},
+		},
+	}
+}
+
+func handleReceiptKeygen(ctx *cli.Context, prefix string) int {
+	privPEM, pubPEM, err := receipt.GenerateKey()
+	if err != nil {
+		return ctx.Errorf("%v", err)
+	}
+	privPath, pubPath := prefix+".key", prefix+".pub"
+	// O_EXCL keeps an existing key, and the receipts it signed, valid.
+	f, err := os.OpenFile(privPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return ctx.Errorf("cannot write private key: %v", err)
+	}
+	if _, err := f.Write(privPEM); err != nil {
+		f.Close()
+		return ctx.Errorf("cannot write private key: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return ctx.Errorf("cannot write private key: %v", err)
+	}
+	if err := os.WriteFile(pubPath, pubPEM, 0o644); err != nil {
+		return ctx.Errorf("cannot write public key: %v", err)
+	}
+	fmt.Fprintf(ctx.Stdout, "private key: %s (keep it secret; set receipts.key to it where receipts are signed)\n", privPath)
+	fmt.Fprintf(ctx.Stdout, "public key:  %s (share it with whoever checks receipts)\n", pubPath)
+	return cli.ExitOK
+}
+
+// receiptCheck is the outcome of checking one receipt file.
+type receiptCheck struct {
+	File    string           `json:"file"`
+	Valid   bool             `json:"valid"`
+	Error   string           `json:"error,omitempty"`
+	Receipt *receipt.Receipt `json:"receipt,omitempty"`
+}
+
+func handleReceiptVerify(ctx *cli.Context, keyPath string) int {
+	if len(ctx.Args) == 0 {
+		return ctx.UsageErrorf("receipt verify needs at least one receipt file")
+	}
+	if keyPath == "" {
+		return ctx.UsageErrorf("receipt verify needs --key")
+	}
+	key, err := receipt.LoadKey(keyPath)
+	if err != nil {
+		return ctx.Errorf("%v", err)
+	}
+
+	checks := make([]receiptCheck, 0, len(ctx.Args))
+	allValid := true
+	for _, file := range ctx.Args {
+		check := receiptCheck{File: file}
+		r, err := verifyReceiptFile(file, key)
+		if err != nil {
+			check.Error = err.Error()
+			allValid = false
+		} else {
+			check.Valid, check.Receipt = true, &r
+		}
+		checks = append(checks, check)
+	}
+
+	if ctx.Format() == formatJSON {
+		if err := writeJSON(ctx.Stdout, checks); err != nil {
+			return ctx.Errorf("%v", err)
+		}
+	} else {
+		for _, c := range checks {
+			if !c.Valid {
+				fmt.Fprintf(ctx.Stdout, "%s %s: %s\n", statusGlyph(verify.StatusFailed), c.File, c.Error)
+				continue
+			}
+			r := c.Receipt
+			head := r.Head
+			if len(head) > 12 {
+				head = head[:12]
+			}
+			if head == "" {
+				head = "no commit"
+			}
+			fmt.Fprintf(ctx.Stdout, "%s %s: %s %s passed %d/%d by %s <%s> at %s on %s (tscgit %s)\n",
+				statusGlyph(verify.StatusPassed), c.File, r.Kind, r.ID, r.Points, r.Max,
+				r.Student.Name, r.Student.Email, head, r.IssuedAt.Format(time.RFC3339), r.ToolVersion)
+		}
+	}
+	if !allValid {
+		return cli.ExitFailed
+	}
+	return cli.ExitOK
+}
+
+func verifyReceiptFile(path string, key *receipt.Key) (receipt.Receipt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return receipt.Receipt{}, err
+	}
+	signed, err := receipt.Parse(data)
+	if err != nil {
+		return receipt.Receipt{}, err
+	}
+	r, err := signed.Verify(key)
+	if err != nil {
+		return r, err
+	}
+	if !r.Passed {
+		return r, fmt.Errorf("receipt records a failed attempt")
+	}
+	return r, nil
+}
+
+// writeReceipt signs a receipt for a passing attempt when receipts.key is
+// set. Failures are warnings unless --receipt asked for the file
+// explicitly, in which case writeReceipt returns false.
+func writeReceipt(ctx *cli.Context, out *outputOptions, rec progress.Record, repo *gitutil.Repository) bool {
+	if !rec.Passed {
+		return true
+	}
+	if settings.Receipts.Key == "" {
+		if out.receipt != "" {
+			fmt.Fprintln(ctx.Stderr, "cannot write receipt: no signing key; set receipts.key (tscgit config set receipts.key FILE)")
+			return false
+		}
+		return true
+	}
+	path, err := issueReceipt(rec, repo, out.receipt)
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "warning: could not write receipt: %v\n", err)
+		return out.receipt == ""
+	}
+	fmt.Fprintf(ctx.Stderr, "receipt written to %s\n", path)
+	return true
+}
+
+func issueReceipt(rec progress.Record, repo *gitutil.Repository, path string) (string, error) {
+	key, err := receipt.LoadKey(settings.Receipts.Key)
+	if err != nil {
+		return "", err
+	}
+	gitCtx, cancel := lessons.TimeoutContext()
+	defer cancel()
+	head, student := receiptIdentity(gitCtx, repo)
+
+	now := time.Now()
+	signed, err := receipt.Sign(receipt.New(rec, head, student, Version, now), key)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		dir, err := receiptsDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%s-%s.json", rec.Kind, rec.ID, now.UTC().Format("20060102T150405Z")))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := writeJSON(&buf, signed); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// receiptIdentity reads HEAD and the git identity, from the repository when
+// there is one and from the global configuration otherwise.
+func receiptIdentity(ctx context.Context, repo *gitutil.Repository) (head string, student receipt.Student) {
+	lookup := func(key string) string {
+		v, _, _ := gitutil.GlobalConfig(ctx, key)
+		return v
+	}
+	if repo != nil {
+		if h, err := repo.Head(ctx); err == nil {
+			head = h.Hash
+		}
+		lookup = func(key string) string {
+			v, _, _ := repo.Config(ctx, key)
+			return v
+		}
+	}
+	return head, receipt.Student{Name: lookup("user.name"), Email: lookup("user.email")}
+}
+
+// currentRepo opens the repository around the working directory, or returns
+// nil when there is none; run scripts do not need one.
+func currentRepo() *gitutil.Repository {
+	ctx, cancel := lessons.TimeoutContext()
+	defer cancel()
+	repo, err := gitutil.Open(ctx, "")
+	if err != nil {
+		return nil
+	}
+	return repo
+}
+
+func receiptsDir() (string, error) {
+	if settings.Receipts.Dir != "" {
+		return settings.Receipts.Dir, nil
+	}
+	cfg, err := config.DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(cfg), "receipts"), nil
+}
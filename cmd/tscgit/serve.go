@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/dashboard"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+)
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Args:  "[gradebook.json|progress.json...]",
+		Short: "Serve a class dashboard and JSON API on localhost",
+		Long: `Serves a dashboard of pass rates per lesson, the checks students are
+stuck on most and each student's results, read from JSON gradebooks
+written by tscgit grade --format json and from progress files. A progress
+file belongs to the student named after the file, or after its folder when
+it is called progress.json. Later files override earlier ones for the same
+student and lesson. Without files, your own progress is shown.
+
+Files are read again on every request, so the pages follow regrades as
+they are written. The same data is available as JSON under /api/overview,
+/api/students and /api/students/NAME.
+
+The server listens on localhost only unless --addr names another
+interface; anyone who can reach it can see every grade.`,
+		Examples: []cli.Example{
+			{Command: "tscgit grade submissions/ init-basics --format json --out grades.json && tscgit serve grades.json"},
+			{Command: "tscgit serve --addr 127.0.0.1:9000 grades.json lab/*/progress.json"},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			addr := fs.String("addr", "127.0.0.1:8080", "listen on `HOST:PORT`")
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleServe(ctx, *addr)
+				},
+				Args: func(*cli.Context, int) []string { return []string{cli.CompleteFiles} },
+			}
+		},
+	}
+}
+
+func handleServe(ctx *cli.Context, addr string) int {
+	load := func() (*dashboard.Class, error) { return dashboard.Load(ctx.Args) }
+	if len(ctx.Args) == 0 {
+		load = ownProgress
+	}
+	// Fail before listening when a file is unreadable from the start.
+	if _, err := load(); err != nil {
+		return ctx.Errorf("%v", err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return ctx.Errorf("cannot listen: %v", err)
+	}
+	if host, _, _ := net.SplitHostPort(addr); !isLoopback(host) {
+		fmt.Fprintf(ctx.Stderr, "warning: listening on %s; anyone who can reach it can see every grade\n", addr)
+	}
+	fmt.Fprintf(ctx.Stdout, "Dashboard at http://%s/ (Ctrl+C to stop)\n", ln.Addr())
+
+	srv := &http.Server{Handler: dashboard.NewHandler(load), ReadHeaderTimeout: 10 * time.Second}
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return ctx.Errorf("dashboard server failed: %v", err)
+	}
+	return cli.ExitOK
+}
+
+// ownProgress shows the configured progress store as a class of one, named
+// after the git identity.
+func ownProgress() (*dashboard.Class, error) {
+	store, err := loadProgress()
+	if err != nil {
+		return nil, err
+	}
+	gitCtx, cancel := lessons.TimeoutContext()
+	defer cancel()
+	name, _, _ := gitutil.GlobalConfig(gitCtx, "user.name")
+	if name == "" {
+		name = "me"
+	}
+	class := &dashboard.Class{Sources: []string{store.Path()}}
+	class.Add(dashboard.FromProgress(name, store.List(), store.Path()))
+	return class, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
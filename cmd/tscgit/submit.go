@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/doctor"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/submit"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+func submitCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "submit",
+		Short: "Package your repository and results into one file to hand in",
+		Long: `Writes a submission file holding a git bundle of every branch, tag and
+stash, snapshots of the reflogs, your latest verify and run results and the
+tscgit doctor report. Instructors grade it with tscgit grade --bundle,
+which restores the repository exactly as you left it.
+
+Only committed work is included: commit your changes before submitting.`,
+		Formats: []string{formatText, formatJSON},
+		Examples: []cli.Example{
+			{Command: "tscgit submit --out ada" + submit.Ext},
+			{Command: "tscgit submit --path ~/practice --out ~/Desktop/ada" + submit.Ext},
+		},
+		Define: func(fs *flag.FlagSet) cli.Action {
+			out := fs.String("out", "", "write the submission to `FILE` (default: the repository's folder name"+submit.Ext+")")
+			return cli.Action{
+				Run: func(ctx *cli.Context) int {
+					return handleSubmit(ctx, *out)
+				},
+				FlagArgs: map[string]func(*cli.Context) []string{
+					"out": func(*cli.Context) []string { return []string{cli.CompleteFiles} },
+				},
+			}
+		},
+	}
+}
+
+func handleSubmit(ctx *cli.Context, outPath string) int {
+	if len(ctx.Args) > 0 {
+		return ctx.UsageErrorf("submit takes no arguments; use --path to choose the repository")
+	}
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	repo, err := gitutil.Open(sigCtx, ctx.Globals.Path)
+	if err != nil {
+		return ctx.Errorf("failed to open git repository: %s", verify.Describe(err))
+	}
+	if outPath == "" {
+		outPath = filepath.Base(repo.Root) + submit.Ext
+	}
+
+	info := submit.Info{
+		ToolVersion: Version,
+		Results:     repoResults(ctx, repo),
+		Doctor: doctor.Run(sigCtx, doctor.Options{
+			Path:          repo.Root,
+			ProgressPath:  settings.Progress.Path,
+			DefaultBranch: settings.Branches.Default,
+		}),
+		Now: time.Now(),
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return ctx.Errorf("cannot write submission: %v", err)
+	}
+	manifest, err := submit.Create(sigCtx, repo, info, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("cannot write submission: %w", cerr)
+	}
+	if err != nil {
+		os.Remove(outPath)
+		if gitutil.IsNoCommits(err) {
+			return ctx.Errorf("nothing to submit: the repository has no commits yet")
+		}
+		return ctx.Errorf("%v", err)
+	}
+
+	if ctx.Format() == formatJSON {
+		if err := writeJSON(ctx.Stdout, struct {
+			File string `json:"file"`
+			*submit.Manifest
+		}{outPath, manifest}); err != nil {
+			return ctx.Errorf("%v", err)
+		}
+	} else {
+		where := "detached HEAD"
+		if manifest.Branch != "" {
+			where = "branch " + manifest.Branch
+		}
+		fmt.Fprintf(ctx.Stdout, "%s wrote %s: %s at %.12s, %d saved results\n",
+			statusGlyph(verify.StatusPassed), outPath, where, manifest.Head, len(info.Results))
+	}
+	if manifest.Dirty {
+		fmt.Fprintln(ctx.Stderr, "warning: the working tree has uncommitted changes, which are not included; commit them and submit again")
+	}
+	return cli.ExitOK
+}
+
+// repoResults returns the saved lesson results for repo and every script
+// result; scripts run in sandboxes of their own, not in a repository.
+func repoResults(ctx *cli.Context, repo *gitutil.Repository) []progress.Record {
+	store, err := loadProgress()
+	if err != nil {
+		fmt.Fprintf(ctx.Stderr, "warning: results not included: %v\n", err)
+		return nil
+	}
+	var out []progress.Record
+	for _, rec := range store.List() {
+		if rec.Repo == "" || rec.Repo == repo.Root {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
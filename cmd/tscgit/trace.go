@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rohit746/tscgit/internal/cli"
+	"github.com/rohit746/tscgit/internal/trace"
+)
+
+// traceKeep is how many commands the debug pane can show.
+const traceKeep = 200
+
+var (
+	tracer    *trace.Recorder
+	traceFile *os.File
+)
+
+// startTrace turns on command tracing for --debug and --trace. With --trace
+// every command is appended to the file as a JSON line; --debug alone only
+// keeps recent commands in memory for the debug pane.
+func startTrace(g *cli.Globals) error {
+	if !g.Debug && g.Trace == "" {
+		return nil
+	}
+	var w io.Writer
+	switch g.Trace {
+	case "":
+	case "-":
+		w = os.Stderr
+	default:
+		f, err := os.OpenFile(g.Trace, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("open trace file: %w", err)
+		}
+		traceFile, w = f, f
+	}
+	tracer = trace.NewRecorder(w, traceKeep)
+	trace.Start(tracer)
+	return nil
+}
+
+// stopTrace closes the trace file and reports write errors, which would
+// otherwise leave a silently incomplete log.
+func stopTrace() {
+	if tracer == nil {
+		return
+	}
+	trace.Start(nil)
+	err := tracer.Err()
+	if traceFile != nil {
+		if cerr := traceFile.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("trace: close: %w", cerr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
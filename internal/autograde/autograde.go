@@ -0,0 +1,222 @@
+// Package autograde converts verify and run reports into the result formats
+// autograding harnesses expect: GitHub Classroom's autograding result and
+// the Gradescope-style results.json used by many generic graders.
+package autograde
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// Output formats.
+const (
+	FormatClassroom  = "classroom"
+	FormatGradescope = "gradescope"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatClassroom, FormatGradescope}
+
+// Test is one graded item: a lesson check or a run script step.
+type Test struct {
+	Name string
+	// Status is one of the verify.Status* values.
+	Status   string
+	Score    int
+	MaxScore int
+	Output   string
+	Duration time.Duration
+}
+
+// Suite is a harness-neutral view of a verify or run report.
+type Suite struct {
+	Name     string
+	Score    int
+	MaxScore int
+	Tests    []Test
+	// Err is set when the run stopped early, for example because a setup
+	// hook failed.
+	Err error
+}
+
+// FromVerify converts a lesson report. Optional checks are worth nothing
+// and bonus checks add to the score without raising the maximum, matching
+// verify.Grade.
+func FromVerify(report verify.Report) Suite {
+	s := Suite{Name: report.Title, Score: report.Score.Points, MaxScore: report.Score.Max}
+	for _, c := range report.Checks {
+		t := Test{
+			Name:     c.Title,
+			Status:   c.Status,
+			Score:    c.Points,
+			Output:   c.Message,
+			Duration: time.Duration(c.DurationMS) * time.Millisecond,
+		}
+		if !c.Optional && !c.Bonus {
+			t.MaxScore = c.Weight
+		}
+		if c.Error != "" {
+			t.Output = c.Hint
+		}
+		s.Tests = append(s.Tests, t)
+	}
+	return s
+}
+
+// FromRun converts a run script report. Only graded steps become tests;
+// setup and teardown steps are not scored.
+func FromRun(report run.Report) Suite {
+	s := Suite{Name: report.Title, Score: report.Score.Points, MaxScore: report.Score.Max}
+	for _, step := range report.Steps {
+		if step.Phase == run.PhaseSetup || step.Phase == run.PhaseTeardown {
+			continue
+		}
+		t := Test{
+			Name:     step.Command,
+			Status:   verify.StatusFailed,
+			MaxScore: 1,
+			Output:   strings.Join(step.Failures, "\n"),
+			Duration: time.Duration(step.DurationMS) * time.Millisecond,
+		}
+		if step.Passed {
+			t.Status, t.Score = verify.StatusPassed, 1
+		}
+		s.Tests = append(s.Tests, t)
+	}
+	return s
+}
+
+// ClassroomResult is GitHub Classroom's autograding result. Workflows pass
+// it base64-encoded to the autograding-grading-reporter action.
+type ClassroomResult struct {
+	Version  int             `json:"version"`
+	Status   string          `json:"status"`
+	MaxScore int             `json:"max_score"`
+	Tests    []ClassroomTest `json:"tests"`
+}
+
+// ClassroomTest is one test of a ClassroomResult.
+type ClassroomTest struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	Message       string `json:"message,omitempty"`
+	ExecutionTime string `json:"execution_time"`
+	Score         int    `json:"score"`
+}
+
+// Classroom converts s. The result fails unless the passing tests cover
+// the whole maximum score, so bonus points cannot hide a failed required
+// test, and it is an error when the run stopped early.
+func Classroom(s Suite) ClassroomResult {
+	res := ClassroomResult{Version: 1, Status: "pass", MaxScore: s.MaxScore, Tests: []ClassroomTest{}}
+	covered := 0
+	for _, t := range s.Tests {
+		ct := ClassroomTest{
+			Name:          t.Name,
+			Status:        "fail",
+			Message:       t.Output,
+			ExecutionTime: fmt.Sprintf("%.2fs", t.Duration.Seconds()),
+			Score:         t.Score,
+		}
+		switch t.Status {
+		case verify.StatusPassed:
+			ct.Status = "pass"
+		case verify.StatusError:
+			ct.Status = "error"
+		}
+		if ct.Status == "pass" {
+			covered += t.MaxScore
+		}
+		res.Tests = append(res.Tests, ct)
+	}
+	switch {
+	case s.Err != nil:
+		res.Status = "error"
+	case covered < s.MaxScore:
+		res.Status = "fail"
+	}
+	return res
+}
+
+// GradescopeResult is a Gradescope results.json.
+type GradescopeResult struct {
+	Score      float64          `json:"score"`
+	Output     string           `json:"output,omitempty"`
+	Visibility string           `json:"visibility"`
+	Tests      []GradescopeTest `json:"tests"`
+}
+
+// GradescopeTest is one test of a GradescopeResult.
+type GradescopeTest struct {
+	Name       string  `json:"name"`
+	Number     string  `json:"number"`
+	Score      float64 `json:"score"`
+	MaxScore   float64 `json:"max_score"`
+	Status     string  `json:"status"`
+	Output     string  `json:"output,omitempty"`
+	Visibility string  `json:"visibility"`
+}
+
+// Gradescope converts s. Tests are numbered from 1 in report order.
+func Gradescope(s Suite) GradescopeResult {
+	res := GradescopeResult{
+		Score:      float64(s.Score),
+		Output:     s.Name,
+		Visibility: "visible",
+		Tests:      []GradescopeTest{},
+	}
+	if s.Err != nil {
+		res.Output += "\n" + s.Err.Error()
+	}
+	for i, t := range s.Tests {
+		gt := GradescopeTest{
+			Name:       t.Name,
+			Number:     strconv.Itoa(i + 1),
+			Score:      float64(t.Score),
+			MaxScore:   float64(t.MaxScore),
+			Status:     "failed",
+			Output:     t.Output,
+			Visibility: "visible",
+		}
+		if t.Status == verify.StatusPassed {
+			gt.Status = "passed"
+		}
+		res.Tests = append(res.Tests, gt)
+	}
+	return res
+}
+
+// Write encodes s in format, checks it against the bundled schema for that
+// format and writes it to w.
+func Write(w io.Writer, format string, s Suite) error {
+	var v any
+	switch format {
+	case FormatClassroom:
+		v = Classroom(s)
+	case FormatGradescope:
+		v = Gradescope(s)
+	default:
+		return fmt.Errorf("autograde: unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("autograde: encode %s result: %w", format, err)
+	}
+	if err := Validate(format, buf.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("autograde: write %s result: %w", format, err)
+	}
+	return nil
+}
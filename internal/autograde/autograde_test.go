@@ -0,0 +1,121 @@
+package autograde
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+func sampleVerifyReport() verify.Report {
+	lesson := &lessons.Lesson{
+		ID:    "sample",
+		Title: "Sample lesson",
+		Checks: []lessons.Check{
+			{ID: "a", Title: "Passes", Weight: 2},
+			{ID: "b", Title: "Fails"},
+			{ID: "c", Title: "Errors"},
+			{ID: "d", Title: "Bonus", Bonus: true},
+		},
+	}
+	results := []verify.Result{
+		{Check: lesson.Checks[0], Outcome: lessons.CheckResult{Passed: true, Message: "ok"}},
+		{Check: lesson.Checks[1], Outcome: lessons.CheckResult{Message: "missing README.md"}},
+		{Check: lesson.Checks[2], Outcome: lessons.CheckResult{Err: errors.New("boom")}},
+		{Check: lesson.Checks[3], Outcome: lessons.CheckResult{Passed: true}},
+	}
+	return verify.NewReport(lesson, results)
+}
+
+func TestClassroomFromVerify(t *testing.T) {
+	res := Classroom(FromVerify(sampleVerifyReport()))
+	if res.Status != "fail" || res.MaxScore != 4 || len(res.Tests) != 4 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	want := []struct {
+		status string
+		score  int
+	}{{"pass", 2}, {"fail", 0}, {"error", 0}, {"pass", 1}}
+	for i, w := range want {
+		if got := res.Tests[i]; got.Status != w.status || got.Score != w.score {
+			t.Errorf("test %d = %+v, want %s/%d", i, got, w.status, w.score)
+		}
+	}
+	if res.Tests[1].Message != "missing README.md" {
+		t.Errorf("failure message lost: %q", res.Tests[1].Message)
+	}
+
+	// A lesson stopped by a failed setup hook has no tests to cover its
+	// maximum score.
+	if got := Classroom(Suite{MaxScore: 2}).Status; got != "fail" {
+		t.Errorf("suite without tests: status %q", got)
+	}
+	if got := Classroom(Suite{MaxScore: 2, Err: errors.New("setup failed")}).Status; got != "error" {
+		t.Errorf("stopped suite: status %q", got)
+	}
+}
+
+func TestGradescopeFromRun(t *testing.T) {
+	report := run.Report{
+		Title: "Script",
+		Score: run.Score{Points: 1, Max: 2},
+		Steps: []run.StepReport{
+			{Command: "git init", Phase: run.PhaseSetup, Passed: true},
+			{Command: "git status", Phase: run.PhaseCheck, Passed: true},
+			{Command: "git log", Phase: run.PhaseCheck, Failures: []string{"expected exit code 0, got 128"}},
+		},
+	}
+	res := Gradescope(FromRun(report))
+	if res.Score != 1 || len(res.Tests) != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if got := res.Tests[1]; got.Number != "2" || got.Status != "failed" || got.MaxScore != 1 || !strings.Contains(got.Output, "exit code") {
+		t.Fatalf("unexpected failed test %+v", got)
+	}
+}
+
+func TestWriteMatchesBundledSchemas(t *testing.T) {
+	suites := map[string]Suite{
+		"verify": FromVerify(sampleVerifyReport()),
+		"run":    FromRun(run.Report{Steps: []run.StepReport{{Command: "ls", Phase: run.PhaseCheck, Passed: true}}}),
+		"empty":  {},
+	}
+	for name, s := range suites {
+		for _, format := range Formats {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, s); err != nil {
+				t.Fatalf("%s as %s: %v", name, format, err)
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Fatalf("%s as %s: invalid JSON", name, format)
+			}
+		}
+	}
+	if err := Write(&bytes.Buffer{}, "junit", Suite{}); err == nil {
+		t.Fatal("unknown format should fail")
+	}
+}
+
+func TestValidateRejectsMismatches(t *testing.T) {
+	cases := []struct {
+		format, doc, want string
+	}{
+		{FormatClassroom, `{"version":1,"status":"pass","tests":[]}`, `missing required property "max_score"`},
+		{FormatClassroom, `{"version":2,"status":"pass","max_score":1,"tests":[]}`, "$.version"},
+		{FormatClassroom, `{"version":1,"status":"ok","max_score":1,"tests":[]}`, "$.status"},
+		{FormatClassroom, `{"version":1,"status":"pass","max_score":1.5,"tests":[{"name":"x","status":"pass","extra":1}]}`, `unexpected property "extra"`},
+		{FormatGradescope, `{"tests":[{"name":"x","score":-1,"max_score":"1"}]}`, "$.tests[0].max_score: want type number"},
+		{FormatGradescope, `{"tests":[{"name":"x","score":-1,"max_score":1}]}`, "below the minimum"},
+	}
+	for _, tc := range cases {
+		err := Validate(tc.format, []byte(tc.doc))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Validate(%s, %s) = %v, want error containing %q", tc.format, tc.doc, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package autograde
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//go:embed schemas/*.schema.json
+var schemaFS embed.FS
+
+// Schema returns the bundled JSON Schema for format.
+func Schema(format string) ([]byte, error) {
+	data, err := schemaFS.ReadFile("schemas/" + format + ".schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("autograde: no schema for format %q", format)
+	}
+	return data, nil
+}
+
+// Validate checks a JSON document against the bundled schema for format, so
+// output can be verified without network access to the harness. Only the
+// schema keywords the bundled schemas use are supported: type, const, enum,
+// minimum, required, properties, additionalProperties and items.
+func Validate(format string, doc []byte) error {
+	raw, err := Schema(format)
+	if err != nil {
+		return err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return fmt.Errorf("autograde: parse %s schema: %w", format, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("autograde: parse %s result: %w", format, err)
+	}
+	if problems := validate(schema, v, "$"); len(problems) > 0 {
+		return fmt.Errorf("autograde: %s result does not match its schema: %s", format, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validate(schema map[string]any, v any, path string) []string {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if want, ok := schema["type"]; ok && !matchesType(want, v) {
+		fail("want type %v, got %s", want, typeOf(v))
+		return problems
+	}
+	if want, ok := schema["const"]; ok && !equalJSON(want, v) {
+		fail("want %v, got %v", want, v)
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equalJSON(e, v) }) {
+		fail("%v is not one of %v", v, enum)
+	}
+	if min, ok := schema["minimum"].(float64); ok {
+		if n, isNum := v.(json.Number); isNum {
+			if f, err := n.Float64(); err == nil && f < min {
+				fail("%v is below the minimum %v", f, min)
+			}
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, present := v[name.(string)]; !present {
+					fail("missing required property %q", name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]any)
+			if !ok {
+				if additional, set := schema["additionalProperties"].(bool); set && !additional {
+					fail("unexpected property %q", k)
+				}
+				continue
+			}
+			problems = append(problems, validate(sub, v[k], path+"."+k)...)
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+func matchesType(want, v any) bool {
+	switch want := want.(type) {
+	case string:
+		t := typeOf(v)
+		return t == want || (want == "number" && t == "integer")
+	case []any:
+		return slices.ContainsFunc(want, func(w any) bool { return matchesType(w, v) })
+	}
+	return false
+}
+
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// equalJSON compares a schema value, decoded with float64 numbers, with a
+// document value decoded with json.Number.
+func equalJSON(schemaValue, v any) bool {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return err == nil && schemaValue == f
+	}
+	return reflect.DeepEqual(schemaValue, v)
+}
@@ -0,0 +1,348 @@
+// Package cli is a small command-tree framework on top of the standard flag
+// package. Each Command describes its usage, examples and flags once; help
+// text, man pages, the markdown reference and shell completion are all
+// generated from those definitions.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Exit codes shared by every command.
+const (
+	// ExitOK means the command did what was asked and every check passed.
+	ExitOK = 0
+	// ExitError covers usage mistakes, unreadable repositories or packs,
+	// and internal failures.
+	ExitError = 1
+	// ExitFailed means the command ran but found problems: failed checks,
+	// a score below --min-score, policy violations or doctor failures.
+	ExitFailed = 2
+)
+
+// ExitCodes documents the exit codes for help output and generated docs.
+var ExitCodes = []struct {
+	Code    int
+	Meaning string
+}{
+	{ExitOK, "success; every required check passed"},
+	{ExitError, "usage error, unreadable repository, pack or config, or internal failure"},
+	{ExitFailed, "checks failed, the score is below --min-score, lint found violations or doctor found a problem"},
+}
+
+// Example is one command line shown in help output.
+type Example struct {
+	Command string
+	Comment string
+}
+
+// Action is what a command does once its flags are parsed. Run is
+// required for leaf commands; the completion hooks are optional.
+type Action struct {
+	Run func(ctx *Context) int
+	// Args completes the positional argument at index pos; ctx holds the
+	// flags and arguments typed before it.
+	Args func(ctx *Context, pos int) []string
+	// FlagArgs completes the values of the command's own flags, by name.
+	FlagArgs map[string]func(ctx *Context) []string
+}
+
+// Command is a node of the command tree.
+type Command struct {
+	Name string
+	// Args is the positional argument synopsis, e.g. "<lesson-id>".
+	Args     string
+	Short    string
+	Long     string
+	Examples []Example
+	// Formats lists the values --format accepts, the first being the
+	// fallback when the configured default does not apply. Commands
+	// without alternative output leave it empty and ignore --format.
+	Formats []string
+	// Hidden commands work but are left out of help and docs.
+	Hidden bool
+	// Define registers the command's flags on fs and returns its action.
+	// It is called afresh for every parse, so flag variables can live in
+	// the closure.
+	Define   func(fs *flag.FlagSet) Action
+	Commands []*Command
+
+	parent *Command
+}
+
+// Path returns the command's full name, e.g. "tscgit config set".
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+// Find returns the visible or hidden subcommand called name.
+func (c *Command) Find(name string) *Command {
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Visible returns the subcommands shown in help and docs.
+func (c *Command) Visible() []*Command {
+	var out []*Command
+	for _, sub := range c.Commands {
+		if !sub.Hidden {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+func (c *Command) link() {
+	for _, sub := range c.Commands {
+		sub.parent = c
+		sub.link()
+	}
+}
+
+// Globals holds the flags every command accepts.
+type Globals struct {
+	Path    string
+	Format  string
+	NoColor bool
+	Debug   bool
+	Trace   string
+	Config  string
+	// DefaultFormat is used when --format is not given and the command
+	// supports it.
+	DefaultFormat string
+}
+
+func (g *Globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.Path, "path", g.Path, "repository `DIR` to work in (defaults to the current directory)")
+	fs.StringVar(&g.Format, "format", g.Format, "output `FORMAT`, for commands with more than one")
+	fs.BoolVar(&g.NoColor, "no-color", g.NoColor, "disable colored output")
+	fs.BoolVar(&g.Debug, "debug", g.Debug, "show raw errors and stack traces, and keep a command trace for the debug pane")
+	fs.StringVar(&g.Trace, "trace", g.Trace, "append a JSON line for every git and shell command to `FILE` (- for stderr)")
+	fs.StringVar(&g.Config, "config", g.Config, "read settings from `FILE` instead of the user config file")
+}
+
+func isGlobal(name string) bool {
+	switch name {
+	case "path", "format", "no-color", "debug", "trace", "config":
+		return true
+	}
+	return false
+}
+
+// Context is passed to a running command.
+type Context struct {
+	Command *Command
+	Globals *Globals
+	Args    []string
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// Format returns the output format: --format when given, otherwise the
+// configured default if the command supports it, otherwise the command's
+// first format.
+func (c *Context) Format() string {
+	if c.Globals.Format != "" {
+		return c.Globals.Format
+	}
+	if slices.Contains(c.Command.Formats, c.Globals.DefaultFormat) {
+		return c.Globals.DefaultFormat
+	}
+	if len(c.Command.Formats) > 0 {
+		return c.Command.Formats[0]
+	}
+	return ""
+}
+
+// Errorf prints an error message and returns ExitError.
+func (c *Context) Errorf(format string, args ...any) int {
+	fmt.Fprintf(c.Stderr, format+"\n", args...)
+	return ExitError
+}
+
+// UsageErrorf prints an error followed by a pointer to the command's help
+// and returns ExitError.
+func (c *Context) UsageErrorf(format string, args ...any) int {
+	fmt.Fprintf(c.Stderr, format+"\n", args...)
+	fmt.Fprintf(c.Stderr, "Run '%s -h' for usage.\n", c.Command.Path())
+	return ExitError
+}
+
+// Program runs a command tree.
+type Program struct {
+	Root *Command
+	// Configure runs before any flag is parsed, with Globals.Config already
+	// taken from the command line, so configuration can supply defaults.
+	Configure func(g *Globals)
+	// Before runs after parsing, just before the command.
+	Before func(ctx *Context) error
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run executes the command selected by args and returns the exit code.
+func (p *Program) Run(args []string) int {
+	p.Root.link()
+	if p.Stdout == nil {
+		p.Stdout = os.Stdout
+	}
+	if p.Stderr == nil {
+		p.Stderr = os.Stderr
+	}
+	g := &Globals{Config: scanFlag(args, "config"), Path: scanFlag(args, "path")}
+	if p.Configure != nil {
+		p.Configure(g)
+	}
+	if len(args) > 0 && args[0] == completeCommand {
+		return p.complete(g, args[1:])
+	}
+
+	cmd, action, rest, code, done := p.resolve(g, args)
+	if done {
+		return code
+	}
+	ctx := &Context{Command: cmd, Globals: g, Args: rest, Stdout: p.Stdout, Stderr: p.Stderr}
+	if g.Format != "" && len(cmd.Formats) > 0 && !slices.Contains(cmd.Formats, g.Format) {
+		return ctx.UsageErrorf("unknown format %q (want %s)", g.Format, strings.Join(cmd.Formats, ", "))
+	}
+	if p.Before != nil {
+		if err := p.Before(ctx); err != nil {
+			return ctx.Errorf("%v", err)
+		}
+	}
+	if action.Run == nil {
+		p.writeHelp(p.Stderr, cmd)
+		return ExitError
+	}
+	return action.Run(ctx)
+}
+
+// resolve walks args down the command tree, parsing flags at each level.
+// done reports that help or an error was already printed.
+func (p *Program) resolve(g *Globals, args []string) (cmd *Command, action Action, rest []string, code int, done bool) {
+	cmd = p.Root
+	rest = args
+	for {
+		fs, act := newFlagSet(cmd, g)
+		if len(cmd.Commands) == 0 {
+			positional, err := parseInterspersed(fs, rest)
+			if err != nil {
+				return cmd, act, nil, p.flagError(cmd, err), true
+			}
+			return cmd, act, positional, 0, false
+		}
+
+		if err := fs.Parse(rest); err != nil {
+			return cmd, act, nil, p.flagError(cmd, err), true
+		}
+		rest = fs.Args()
+		if len(rest) == 0 {
+			return cmd, act, rest, 0, false
+		}
+		if rest[0] == "help" {
+			return cmd, act, nil, p.help(cmd, rest[1:]), true
+		}
+		sub := cmd.Find(rest[0])
+		if sub == nil {
+			fmt.Fprintf(p.Stderr, "unknown command: %s\n\n", rest[0])
+			p.writeHelp(p.Stderr, cmd)
+			return cmd, act, nil, ExitError, true
+		}
+		cmd, rest = sub, rest[1:]
+	}
+}
+
+func (p *Program) flagError(cmd *Command, err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		p.writeHelp(p.Stdout, cmd)
+		return ExitOK
+	}
+	fmt.Fprintf(p.Stderr, "%v\n", err)
+	fmt.Fprintf(p.Stderr, "Run '%s -h' for usage.\n", cmd.Path())
+	return ExitError
+}
+
+// help prints help for the command named by path below cmd.
+func (p *Program) help(cmd *Command, path []string) int {
+	for _, name := range path {
+		sub := cmd.Find(name)
+		if sub == nil {
+			fmt.Fprintf(p.Stderr, "unknown command: %s\n", name)
+			return ExitError
+		}
+		cmd = sub
+	}
+	p.writeHelp(p.Stdout, cmd)
+	return ExitOK
+}
+
+// newFlagSet builds the flag set for cmd: the global flags followed by the
+// command's own.
+func newFlagSet(cmd *Command, g *Globals) (*flag.FlagSet, Action) {
+	fs := flag.NewFlagSet(cmd.Path(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	g.register(fs)
+	var action Action
+	if cmd.Define != nil {
+		action = cmd.Define(fs)
+	}
+	return fs, action
+}
+
+// parseInterspersed parses flags wherever they appear among the positional
+// arguments. Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var tail []string
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, tail = args[:i], args[i+1:]
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, tail...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// scanFlag finds the last value of a global flag such as --config or
+// --path before flags are parsed, so Configure can locate the configuration
+// that provides flag defaults.
+func scanFlag(args []string, flagName string) string {
+	value := ""
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		name, v, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || name != flagName {
+			continue
+		}
+		if hasValue {
+			value = v
+		} else if i+1 < len(args) {
+			value = args[i+1]
+			i++
+		}
+	}
+	return value
+}
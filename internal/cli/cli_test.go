@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func testTree(got *[]string, gotGlobals *Globals) *Command {
+	return &Command{
+		Name:  "prog",
+		Short: "Test program",
+		Commands: []*Command{
+			{
+				Name:    "check",
+				Args:    "<id>",
+				Short:   "Check something",
+				Formats: []string{"tui", "json"},
+				Examples: []Example{
+					{Command: "prog check a", Comment: "check a"},
+				},
+				Define: func(fs *flag.FlagSet) Action {
+					min := fs.Int("min", 0, "minimum `N`")
+					return Action{
+						Run: func(ctx *Context) int {
+							*got = append([]string{ctx.Format(), strings.Repeat("x", *min)}, ctx.Args...)
+							*gotGlobals = *ctx.Globals
+							return ExitFailed
+						},
+						Args: func(ctx *Context, pos int) []string {
+							return []string{"alpha\tfirst", "beta\tsecond"}
+						},
+					}
+				},
+			},
+			{
+				Name:  "group",
+				Short: "A group",
+				Commands: []*Command{
+					{Name: "leaf", Short: "A leaf", Define: func(fs *flag.FlagSet) Action {
+						return Action{Run: func(*Context) int { return ExitOK }}
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestRunParsesGlobalAndInterspersedFlags(t *testing.T) {
+	var got []string
+	var globals Globals
+	var stdout, stderr bytes.Buffer
+	var configSeen, pathSeen string
+	p := &Program{
+		Root:   testTree(&got, &globals),
+		Stdout: &stdout, Stderr: &stderr,
+		Configure: func(g *Globals) {
+			configSeen, pathSeen = g.Config, g.Path
+			g.DefaultFormat = "json"
+		},
+	}
+	code := p.Run([]string{"--debug", "check", "one", "--min", "2", "--config=custom.toml", "--path", "/repo", "--", "--two"})
+	if code != ExitFailed {
+		t.Fatalf("exit code = %d, stderr %q", code, stderr.String())
+	}
+	if strings.Join(got, ",") != "json,xx,one,--two" {
+		t.Fatalf("unexpected run: %q", got)
+	}
+	if configSeen != "custom.toml" || pathSeen != "/repo" {
+		t.Fatalf("Configure saw config %q and path %q before parsing", configSeen, pathSeen)
+	}
+	if !globals.Debug || globals.Path != "/repo" {
+		t.Fatalf("globals not applied: %+v", globals)
+	}
+
+	if code := p.Run([]string{"check", "--format", "text", "x"}); code != ExitError {
+		t.Fatalf("unsupported format: exit code = %d", code)
+	}
+	if code := p.Run([]string{"nope"}); code != ExitError {
+		t.Fatalf("unknown command: exit code = %d", code)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	var got []string
+	var stdout bytes.Buffer
+	p := &Program{Root: testTree(&got, &Globals{}), Stdout: &stdout, Stderr: &bytes.Buffer{}}
+	if code := p.Run([]string{"help", "check"}); code != ExitOK {
+		t.Fatalf("help exit code = %d", code)
+	}
+	for _, want := range []string{"prog check [flags] <id>", "# check a", "--min N", "--no-color", "Output formats: tui, json"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("help missing %q:\n%s", want, stdout.String())
+		}
+	}
+	stdout.Reset()
+	if code := p.Run([]string{"group", "leaf", "-h"}); code != ExitOK || !strings.Contains(stdout.String(), "prog group leaf") {
+		t.Fatalf("-h exit code = %d:\n%s", code, stdout.String())
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	var got []string
+	p := &Program{Root: testTree(&got, &Globals{})}
+	cases := []struct {
+		words []string
+		cur   string
+		want  string
+	}{
+		{nil, "c", "check\tCheck something"},
+		{[]string{"check"}, "b", "beta\tsecond"},
+		{[]string{"check", "--format"}, "", "tui,json"},
+		{[]string{"check", "--path"}, "", CompleteFiles},
+		{[]string{"check"}, "--mi", "--min\tminimum N"},
+		{[]string{"group"}, "", "leaf\tA leaf"},
+	}
+	for _, tc := range cases {
+		out := strings.Join(p.Candidates(&Globals{}, tc.words, tc.cur), ",")
+		if out != tc.want {
+			t.Errorf("Candidates(%q, %q) = %q, want %q", tc.words, tc.cur, out, tc.want)
+		}
+	}
+}
+
+func TestGeneratedDocs(t *testing.T) {
+	var got []string
+	root := testTree(&got, &Globals{})
+	var md, man bytes.Buffer
+	if err := WriteMarkdown(&md, root); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "## prog group leaf") || !strings.Contains(md.String(), "| `--min N` | minimum N |") {
+		t.Fatalf("unexpected markdown:\n%s", md.String())
+	}
+	if err := WriteMan(&man, root.Commands[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(man.String(), ".TH PROG-CHECK 1") || !strings.Contains(man.String(), `.B \-\-min N`) {
+		t.Fatalf("unexpected man page:\n%s", man.String())
+	}
+	for _, shell := range Shells {
+		script, err := CompletionScript(shell, "prog")
+		if err != nil || !strings.Contains(script, "prog __complete") {
+			t.Fatalf("%s completion script: %v", shell, err)
+		}
+	}
+}
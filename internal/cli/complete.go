@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// completeCommand is the hidden command the completion scripts call as
+// PROG __complete --cur=WORD [words before WORD...]. It prints one
+// candidate per line, optionally followed by a tab and a description.
+const completeCommand = "__complete"
+
+// CompleteFiles is returned by completion hooks to make the shell fall
+// back to file name completion.
+const CompleteFiles = ":files"
+
+// Shells lists the shells CompletionScript supports.
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// CompletionScript returns the completion script for shell, calling back
+// into the program named prog for candidates.
+func CompletionScript(shell, prog string) (string, error) {
+	script, ok := scripts[shell]
+	if !ok {
+		return "", fmt.Errorf("cli: unsupported shell %q (want %s)", shell, strings.Join(Shells, ", "))
+	}
+	return strings.ReplaceAll(script, "{{prog}}", prog), nil
+}
+
+func (p *Program) complete(g *Globals, args []string) int {
+	cur := ""
+	if len(args) > 0 {
+		if v, ok := strings.CutPrefix(args[0], "--cur="); ok {
+			cur, args = v, args[1:]
+		}
+	}
+	for _, c := range p.Candidates(g, args, cur) {
+		fmt.Fprintln(p.Stdout, c)
+	}
+	return ExitOK
+}
+
+// Candidates returns completions for cur given the words typed before it.
+func (p *Program) Candidates(g *Globals, words []string, cur string) []string {
+	p.Root.link()
+	cmd := p.Root
+	fs, action := newFlagSet(cmd, g)
+	var positional []string
+	var pending *flag.Flag
+	for _, w := range words {
+		if pending != nil {
+			_ = fs.Set(pending.Name, w)
+			pending = nil
+			continue
+		}
+		if len(w) > 1 && strings.HasPrefix(w, "-") {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			if f := fs.Lookup(name); f != nil {
+				switch {
+				case hasValue:
+					_ = fs.Set(name, value)
+				case isBoolFlag(f):
+					_ = fs.Set(name, "true")
+				default:
+					pending = f
+				}
+			}
+			continue
+		}
+		if len(cmd.Commands) > 0 && len(positional) == 0 {
+			if sub := cmd.Find(w); sub != nil {
+				cmd = sub
+				fs, action = newFlagSet(cmd, g)
+				continue
+			}
+		}
+		positional = append(positional, w)
+	}
+
+	ctx := &Context{Command: cmd, Globals: g, Args: positional}
+	switch {
+	case pending != nil:
+		return filterPrefix(flagValues(ctx, action, pending.Name), cur)
+	case strings.HasPrefix(cur, "-"):
+		var out []string
+		fs.VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			out = append(out, "--"+f.Name+"\t"+usage)
+		})
+		return filterPrefix(out, strings.TrimLeft(cur, "-"), "--")
+	case len(cmd.Visible()) > 0 && len(positional) == 0:
+		var out []string
+		for _, sub := range cmd.Visible() {
+			out = append(out, sub.Name+"\t"+sub.Short)
+		}
+		return filterPrefix(out, cur)
+	case action.Args != nil:
+		return filterPrefix(action.Args(ctx, len(positional)), cur)
+	}
+	return nil
+}
+
+func flagValues(ctx *Context, action Action, name string) []string {
+	switch name {
+	case "path", "config", "trace":
+		return []string{CompleteFiles}
+	case "format":
+		return ctx.Command.Formats
+	}
+	if fn, ok := action.FlagArgs[name]; ok {
+		return fn(ctx)
+	}
+	return nil
+}
+
+// filterPrefix keeps candidates whose name starts with prefix after
+// removing strip. A CompleteFiles candidate wins outright.
+func filterPrefix(candidates []string, prefix string, strip ...string) []string {
+	if slices.Contains(candidates, CompleteFiles) {
+		return []string{CompleteFiles}
+	}
+	var out []string
+	for _, c := range candidates {
+		name, _, _ := strings.Cut(c, "\t")
+		for _, s := range strip {
+			name = strings.TrimPrefix(name, s)
+		}
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+var scripts = map[string]string{
+	"bash": `# bash completion for {{prog}}
+# Add to ~/.bashrc:  source <({{prog}} completion bash)
+_{{prog}}() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local out
+    out=$({{prog}} __complete "--cur=$cur" "${COMP_WORDS[@]:1:COMP_CWORD-1}" 2>/dev/null) || return
+    if [[ "$out" == ":files" ]]; then
+        compopt -o filenames 2>/dev/null
+        COMPREPLY=($(compgen -f -- "$cur"))
+        return
+    fi
+    local IFS=$'\n'
+    COMPREPLY=($(printf '%s\n' "$out" | cut -f1))
+}
+complete -F _{{prog}} {{prog}}
+`,
+	"zsh": `#compdef {{prog}}
+# zsh completion for {{prog}}
+# Add to ~/.zshrc:  source <({{prog}} completion zsh)
+_{{prog}}() {
+    local -a lines candidates
+    local line
+    lines=("${(@f)$({{prog}} __complete "--cur=${words[CURRENT]}" "${(@)words[2,CURRENT-1]}" 2>/dev/null)}")
+    if [[ "${lines[1]}" == ":files" ]]; then
+        _files
+        return
+    fi
+    for line in $lines; do
+        [[ -z "$line" ]] && continue
+        if [[ "$line" == *$'\t'* ]]; then
+            candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${line//:/\\:}")
+        fi
+    done
+    _describe '{{prog}}' candidates
+}
+if [[ "$funcstack[1]" == "_{{prog}}" ]]; then
+    _{{prog}} "$@"
+else
+    compdef _{{prog}} {{prog}}
+fi
+`,
+	"fish": `# fish completion for {{prog}}
+# Save as ~/.config/fish/completions/{{prog}}.fish
+function __{{prog}}_complete
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    set -l cur (commandline -ct)
+    set -l out ({{prog}} __complete "--cur=$cur" $tokens 2>/dev/null)
+    if test "$out[1]" = ":files"
+        __fish_complete_path "$cur"
+        return
+    end
+    printf '%s\n' $out
+end
+complete -c {{prog}} -f -a '(__{{prog}}_complete)'
+`,
+	"powershell": `# PowerShell completion for {{prog}}
+# Add to $PROFILE:  {{prog}} completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName {{prog}} -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $words.Count -gt 0) {
+        $words = @($words | Select-Object -SkipLast 1)
+    }
+    $out = @(& {{prog}} __complete "--cur=$wordToComplete" @words 2>$null)
+    if ($out.Count -gt 0 -and $out[0] -eq ':files') {
+        return
+    }
+    foreach ($line in $out) {
+        $parts = $line -split "` + "`" + `t", 2
+        $name = $parts[0]
+        $desc = if ($parts.Count -gt 1) { $parts[1] } else { $name }
+        [System.Management.Automation.CompletionResult]::new($name, $name, 'ParameterValue', $desc)
+    }
+}
+`,
+}
@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// Synopsis returns the usage line, e.g. "tscgit verify [flags] <lesson-id>".
+func (c *Command) Synopsis() string {
+	parts := []string{c.Path()}
+	if len(c.Visible()) > 0 {
+		parts = append(parts, "<command>")
+	}
+	parts = append(parts, "[flags]")
+	if c.Args != "" {
+		parts = append(parts, c.Args)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) description() string {
+	if c.Long != "" {
+		return strings.TrimSpace(c.Long)
+	}
+	return c.Short
+}
+
+// flagDoc is one flag as shown in help and docs.
+type flagDoc struct {
+	Name    string
+	Value   string
+	Usage   string
+	Default string
+}
+
+func (f flagDoc) spec() string {
+	if f.Value == "" {
+		return "--" + f.Name
+	}
+	return "--" + f.Name + " " + f.Value
+}
+
+// flagDocs lists the command's own flags, or the global flags when global is
+// set, using pristine defaults.
+func flagDocs(cmd *Command, global bool) []flagDoc {
+	fs, _ := newFlagSet(cmd, &Globals{})
+	var out []flagDoc
+	fs.VisitAll(func(f *flag.Flag) {
+		if isGlobal(f.Name) != global {
+			return
+		}
+		value, usage := flag.UnquoteUsage(f)
+		if isBoolFlag(f) {
+			value = ""
+		} else if value != "" {
+			value = strings.ToUpper(value)
+		}
+		def := f.DefValue
+		if def == "false" || def == "0" {
+			def = ""
+		}
+		out = append(out, flagDoc{Name: f.Name, Value: value, Usage: usage, Default: def})
+	})
+	return out
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (p *Program) writeHelp(w io.Writer, cmd *Command) {
+	WriteHelp(w, cmd)
+}
+
+// WriteHelp prints the help text for cmd.
+func WriteHelp(w io.Writer, cmd *Command) {
+	fmt.Fprintf(w, "%s\n\nUsage:\n  %s\n", cmd.description(), cmd.Synopsis())
+	if len(cmd.Formats) > 0 {
+		fmt.Fprintf(w, "\nOutput formats: %s\n", strings.Join(cmd.Formats, ", "))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if subs := cmd.Visible(); len(subs) > 0 {
+		fmt.Fprintf(tw, "\nCommands:\n")
+		for _, sub := range subs {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Short)
+		}
+		tw.Flush()
+	}
+	if len(cmd.Examples) > 0 {
+		fmt.Fprintf(w, "\nExamples:\n")
+		for _, ex := range cmd.Examples {
+			if ex.Comment != "" {
+				fmt.Fprintf(w, "  # %s\n", ex.Comment)
+			}
+			fmt.Fprintf(w, "  %s\n", ex.Command)
+		}
+	}
+	writeFlags := func(title string, docs []flagDoc) {
+		if len(docs) == 0 {
+			return
+		}
+		fmt.Fprintf(tw, "\n%s:\n", title)
+		for _, d := range docs {
+			usage := d.Usage
+			if d.Default != "" {
+				usage += fmt.Sprintf(" (default %s)", d.Default)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", d.spec(), usage)
+		}
+		tw.Flush()
+	}
+	writeFlags("Flags", flagDocs(cmd, false))
+	writeFlags("Global flags", flagDocs(cmd, true))
+
+	if cmd.parent == nil {
+		fmt.Fprintf(tw, "\nExit codes:\n")
+		for _, e := range ExitCodes {
+			fmt.Fprintf(tw, "  %d\t%s\n", e.Code, e.Meaning)
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "\nRun '%s help <command>' for more about a command.\n", cmd.Name)
+	}
+}
+
+// walk calls fn for cmd and every visible descendant, parents first.
+func walk(cmd *Command, fn func(*Command)) {
+	fn(cmd)
+	for _, sub := range cmd.Visible() {
+		walk(sub, fn)
+	}
+}
+
+// WriteMarkdown renders the reference for the whole tree as one markdown
+// document.
+func WriteMarkdown(w io.Writer, root *Command) error {
+	root.link()
+	bw := &errWriter{w: w}
+	bw.printf("# %s command reference\n\n", root.Name)
+	bw.printf("<!-- Generated by `%s docs markdown`. Do not edit. -->\n\n", root.Name)
+	bw.printf("%s\n\n", root.description())
+
+	bw.printf("## Global flags\n\nEvery command accepts these flags, before or after its arguments.\n\n")
+	writeMarkdownFlags(bw, flagDocs(root, true))
+	bw.printf("## Exit codes\n\n| Code | Meaning |\n| --- | --- |\n")
+	for _, e := range ExitCodes {
+		bw.printf("| `%d` | %s |\n", e.Code, e.Meaning)
+	}
+	bw.printf("\n")
+
+	walk(root, func(cmd *Command) {
+		if cmd == root {
+			return
+		}
+		bw.printf("## %s\n\n%s\n\n```\n%s\n```\n\n", cmd.Path(), cmd.Short, cmd.Synopsis())
+		if cmd.Long != "" {
+			bw.printf("%s\n\n", strings.TrimSpace(cmd.Long))
+		}
+		if len(cmd.Formats) > 0 {
+			bw.printf("Output formats: %s.\n\n", "`"+strings.Join(cmd.Formats, "`, `")+"`")
+		}
+		if subs := cmd.Visible(); len(subs) > 0 {
+			bw.printf("| Command | Description |\n| --- | --- |\n")
+			for _, sub := range subs {
+				bw.printf("| `%s` | %s |\n", sub.Name, sub.Short)
+			}
+			bw.printf("\n")
+		}
+		if docs := flagDocs(cmd, false); len(docs) > 0 {
+			bw.printf("**Flags**\n\n")
+			writeMarkdownFlags(bw, docs)
+		}
+		if len(cmd.Examples) > 0 {
+			bw.printf("**Examples**\n\n```bash\n")
+			for _, ex := range cmd.Examples {
+				if ex.Comment != "" {
+					bw.printf("# %s\n", ex.Comment)
+				}
+				bw.printf("%s\n", ex.Command)
+			}
+			bw.printf("```\n\n")
+		}
+	})
+	return bw.err
+}
+
+func writeMarkdownFlags(bw *errWriter, docs []flagDoc) {
+	bw.printf("| Flag | Description |\n| --- | --- |\n")
+	for _, d := range docs {
+		bw.printf("| `%s` | %s |\n", d.spec(), strings.ReplaceAll(d.Usage, "|", "\\|"))
+	}
+	bw.printf("\n")
+}
+
+// WriteManPages writes one roff man page per visible command into dir,
+// named like tscgit.1 and tscgit-config-set.1.
+func WriteManPages(dir string, root *Command) error {
+	root.link()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("cli: create man dir: %w", err)
+	}
+	var err error
+	walk(root, func(cmd *Command) {
+		if err != nil {
+			return
+		}
+		name := strings.ReplaceAll(cmd.Path(), " ", "-")
+		var f *os.File
+		f, err = os.Create(filepath.Join(dir, name+".1"))
+		if err != nil {
+			err = fmt.Errorf("cli: write man page: %w", err)
+			return
+		}
+		err = WriteMan(f, cmd)
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("cli: write man page: %w", cerr)
+		}
+	})
+	return err
+}
+
+// WriteMan renders the man page for cmd.
+func WriteMan(w io.Writer, cmd *Command) error {
+	bw := &errWriter{w: w}
+	root := cmd
+	for root.parent != nil {
+		root = root.parent
+	}
+	name := strings.ReplaceAll(cmd.Path(), " ", "-")
+	bw.printf(".\\\" Generated by %s docs man. Do not edit.\n", root.Name)
+	bw.printf(".TH %s 1 \"\" \"%s\" \"%s Manual\"\n", strings.ToUpper(name), root.Name, root.Name)
+	bw.printf(".SH NAME\n%s \\- %s\n", roff(name), roff(cmd.Short))
+	bw.printf(".SH SYNOPSIS\n.B %s\n", roff(cmd.Synopsis()))
+	bw.printf(".SH DESCRIPTION\n%s\n", roffParagraphs(cmd.description()))
+	if len(cmd.Formats) > 0 {
+		bw.printf(".PP\nOutput formats: %s.\n", roff(strings.Join(cmd.Formats, ", ")))
+	}
+	if subs := cmd.Visible(); len(subs) > 0 {
+		bw.printf(".SH COMMANDS\n")
+		for _, sub := range subs {
+			bw.printf(".TP\n.B %s\n%s\n", roff(sub.Name), roff(sub.Short))
+		}
+	}
+	writeManFlags := func(title string, docs []flagDoc) {
+		if len(docs) == 0 {
+			return
+		}
+		bw.printf(".SH %s\n", title)
+		for _, d := range docs {
+			bw.printf(".TP\n.B %s\n%s\n", roff(d.spec()), roff(d.Usage))
+		}
+	}
+	writeManFlags("OPTIONS", flagDocs(cmd, false))
+	writeManFlags("GLOBAL OPTIONS", flagDocs(cmd, true))
+	if len(cmd.Examples) > 0 {
+		bw.printf(".SH EXAMPLES\n")
+		for _, ex := range cmd.Examples {
+			if ex.Comment != "" {
+				bw.printf(".PP\n%s\n", roff(ex.Comment))
+			}
+			bw.printf(".PP\n.RS\n.nf\n%s\n.fi\n.RE\n", roff(ex.Command))
+		}
+	}
+	bw.printf(".SH EXIT STATUS\n")
+	for _, e := range ExitCodes {
+		bw.printf(".TP\n.B %d\n%s\n", e.Code, roff(e.Meaning))
+	}
+	var also []string
+	if cmd.parent != nil {
+		also = append(also, strings.ReplaceAll(cmd.parent.Path(), " ", "-"))
+	}
+	for _, sub := range cmd.Visible() {
+		also = append(also, strings.ReplaceAll(sub.Path(), " ", "-"))
+	}
+	if len(also) > 0 {
+		for i, a := range also {
+			also[i] = "\\fB" + roff(a) + "\\fR(1)"
+		}
+		bw.printf(".SH SEE ALSO\n%s\n", strings.Join(also, ", "))
+	}
+	return bw.err
+}
+
+// roff escapes text for a man page line.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
+
+func roffParagraphs(s string) string {
+	paras := strings.Split(s, "\n\n")
+	for i, p := range paras {
+		lines := strings.Split(p, "\n")
+		for j, l := range lines {
+			lines[j] = roff(strings.TrimSpace(l))
+		}
+		paras[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(paras, "\n.PP\n")
+}
+
+// errWriter remembers the first write error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...any) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, args...)
+	}
+}
@@ -0,0 +1,479 @@
+// Package config loads tscgit settings from built-in defaults, the user
+// config file, a per-repository .tscgit.toml and TSCGIT_* environment
+// variables, each layer overriding the one before. Command-line flags are
+// applied last by the commands themselves.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// RepoFileName is the per-repository config file, looked up from the working
+// directory towards the repository root.
+const RepoFileName = ".tscgit.toml"
+
+// EnvFile names the environment variable that overrides the user config
+// file location.
+const EnvFile = "TSCGIT_CONFIG"
+
+// Duration is a time.Duration written as a Go duration string such as "5s".
+type Duration time.Duration
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := parseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Config holds every setting.
+type Config struct {
+	Timeouts struct {
+		// Check bounds the git calls of a single lesson check.
+		Check Duration `toml:"check"`
+		// Step bounds a single run script command.
+		Step Duration `toml:"step"`
+		// Teardown bounds a run script's teardown phase.
+		Teardown Duration `toml:"teardown"`
+	} `toml:"timeouts"`
+	Output struct {
+		// Format is the default -format for verify and run.
+		Format string `toml:"format"`
+	} `toml:"output"`
+	UI struct {
+		// Color is auto, always or never.
+		Color string `toml:"color"`
+		// Theme is dark, light or auto.
+		Theme string `toml:"theme"`
+	} `toml:"ui"`
+	Repo struct {
+		// Path is the repository used when --path is not given.
+		Path string `toml:"path"`
+	} `toml:"repo"`
+	Lessons struct {
+		// Paths lists pack files, or directories of *.json packs, loaded by
+		// every command that accepts -pack. Relative entries in a file are
+		// resolved against that file's directory.
+		Paths []string `toml:"paths"`
+	} `toml:"lessons"`
+	Progress struct {
+		// Path is the progress file; empty uses the user config directory.
+		Path string `toml:"path"`
+	} `toml:"progress"`
+	Branches struct {
+		// Default is the trunk branch lessons compare against and doctor
+		// suggests for init.defaultBranch.
+		Default string `toml:"default"`
+	} `toml:"branches"`
+	Receipts struct {
+		// Key is the Ed25519 private key or HMAC secret that signs
+		// completion receipts; no receipts are written without one.
+		Key string `toml:"key"`
+		// Dir is where receipts are written; empty uses the user config
+		// directory.
+		Dir string `toml:"dir"`
+	} `toml:"receipts"`
+
+	// sources maps each key to the layer that last set it.
+	sources map[string]string
+}
+
+// Defaults returns the built-in settings.
+func Defaults() *Config {
+	c := &Config{sources: map[string]string{}}
+	c.Timeouts.Check = Duration(5 * time.Second)
+	c.Timeouts.Step = Duration(15 * time.Second)
+	c.Timeouts.Teardown = Duration(15 * time.Second)
+	c.Output.Format = "tui"
+	c.UI.Color = "auto"
+	c.UI.Theme = "dark"
+	c.Branches.Default = "main"
+	return c
+}
+
+// field describes one dotted key.
+type field struct {
+	key     string
+	help    string
+	allowed []string
+	// userOnly keys load code or choose the signing key, so a
+	// repository's RepoFileName may not set them.
+	userOnly bool
+	ptr      func(*Config) any
+}
+
+var fields = []field{
+	{key: "timeouts.check", help: "time budget for one lesson check", ptr: func(c *Config) any { return &c.Timeouts.Check }},
+	{key: "timeouts.step", help: "time budget for one run script command", ptr: func(c *Config) any { return &c.Timeouts.Step }},
+	{key: "timeouts.teardown", help: "time budget for a run script's teardown", ptr: func(c *Config) any { return &c.Timeouts.Teardown }},
+	{key: "output.format", help: "default output format for verify and run", allowed: []string{"tui", "text", "json", "classroom", "gradescope"}, ptr: func(c *Config) any { return &c.Output.Format }},
+	{key: "ui.color", help: "when to use color", allowed: []string{"auto", "always", "never"}, ptr: func(c *Config) any { return &c.UI.Color }},
+	{key: "ui.theme", help: "terminal background the palette is tuned for", allowed: []string{"dark", "light", "auto"}, ptr: func(c *Config) any { return &c.UI.Theme }},
+	{key: "repo.path", help: "repository used when --path is not given", ptr: func(c *Config) any { return &c.Repo.Path }},
+	{key: "lessons.paths", help: "pack files or directories loaded automatically", userOnly: true, ptr: func(c *Config) any { return &c.Lessons.Paths }},
+	{key: "progress.path", help: "progress file location", ptr: func(c *Config) any { return &c.Progress.Path }},
+	{key: "branches.default", help: "trunk branch name used by lessons and doctor", ptr: func(c *Config) any { return &c.Branches.Default }},
+	{key: "receipts.key", help: "key file that signs completion receipts", userOnly: true, ptr: func(c *Config) any { return &c.Receipts.Key }},
+	{key: "receipts.dir", help: "directory completion receipts are written to", ptr: func(c *Config) any { return &c.Receipts.Dir }},
+}
+
+func lookup(key string) (field, error) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("config: unknown key %q", key)
+}
+
+// Keys lists every supported key in display order.
+func Keys() []string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+// Help describes key, or returns "" for unknown keys.
+func Help(key string) string {
+	f, err := lookup(key)
+	if err != nil {
+		return ""
+	}
+	if len(f.allowed) > 0 {
+		return f.help + " (" + strings.Join(f.allowed, ", ") + ")"
+	}
+	return f.help
+}
+
+// Allowed lists the accepted values of key, or nil when any value is
+// accepted.
+func Allowed(key string) []string {
+	f, err := lookup(key)
+	if err != nil {
+		return nil
+	}
+	return f.allowed
+}
+
+// UserOnly reports whether key may only be set in the user file, the
+// environment or on the command line, and not in a repository's RepoFileName.
+func UserOnly(key string) bool {
+	f, err := lookup(key)
+	return err == nil && f.userOnly
+}
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	return "TSCGIT_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Get formats the value of key. Lists are joined with the OS path list
+// separator, matching how they are read from the environment.
+func (c *Config) Get(key string) (string, error) {
+	f, err := lookup(key)
+	if err != nil {
+		return "", err
+	}
+	switch p := f.ptr(c).(type) {
+	case *Duration:
+		return p.String(), nil
+	case *[]string:
+		return strings.Join(*p, string(os.PathListSeparator)), nil
+	default:
+		return *p.(*string), nil
+	}
+}
+
+// Set parses value into key and records source as its origin.
+func (c *Config) Set(key, value, source string) error {
+	f, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	switch p := f.ptr(c).(type) {
+	case *Duration:
+		d, err := parseDuration(value)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", key, err)
+		}
+		*p = d
+	case *[]string:
+		*p = splitList(value)
+	default:
+		if len(f.allowed) > 0 && !slices.Contains(f.allowed, value) {
+			return fmt.Errorf("config: %s must be one of %s, got %q", key, strings.Join(f.allowed, ", "), value)
+		}
+		*p.(*string) = value
+	}
+	c.sources[key] = source
+	return nil
+}
+
+// Source names the layer that set key: "default", a file path or an
+// environment variable.
+func (c *Config) Source(key string) string {
+	if s, ok := c.sources[key]; ok {
+		return s
+	}
+	return "default"
+}
+
+// Options locates the config layers. Empty fields use the real locations.
+type Options struct {
+	// UserFile is the user config file. It defaults to $TSCGIT_CONFIG or
+	// DefaultPath.
+	UserFile string
+	// Dir is where the search for RepoFileName starts.
+	Dir string
+	// LookupEnv reads environment variables; nil uses os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+}
+
+// DefaultPath returns the user config file inside the user's config
+// directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("config: locate config dir: %w", err)
+	}
+	return filepath.Join(dir, "tscgit", "config.toml"), nil
+}
+
+// UserPath resolves the user config file for opts.
+func (o Options) UserPath() (string, error) {
+	if o.UserFile != "" {
+		return o.UserFile, nil
+	}
+	if v, ok := o.lookupEnv(EnvFile); ok && v != "" {
+		return v, nil
+	}
+	return DefaultPath()
+}
+
+func (o Options) lookupEnv(name string) (string, bool) {
+	if o.LookupEnv != nil {
+		return o.LookupEnv(name)
+	}
+	return os.LookupEnv(name)
+}
+
+// Load builds the effective configuration. Missing files are skipped;
+// malformed files, unknown keys and invalid values are errors.
+func Load(opts Options) (*Config, error) {
+	c := Defaults()
+	user, err := opts.UserPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.mergeFile(user, false); err != nil {
+		return nil, err
+	}
+	if repoFile, ok := FindRepoFile(opts.Dir); ok {
+		if err := c.mergeFile(repoFile, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range fields {
+		name := EnvName(f.key)
+		if v, ok := opts.lookupEnv(name); ok {
+			if err := c.Set(f.key, v, "env "+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// mergeFile overlays the keys present in path, which is a repository's
+// RepoFileName when repo is set.
+func (c *Config) mergeFile(path string, repo bool) error {
+	md, err := toml.DecodeFile(path, c)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("config: %s: unknown key %q", path, undecoded[0].String())
+	}
+	for _, key := range md.Keys() {
+		if len(key) != 2 {
+			continue
+		}
+		f, err := lookup(key.String())
+		if err != nil {
+			continue
+		}
+		if repo && f.userOnly {
+			return fmt.Errorf("config: %s: %s can only be set in the user config file or the environment", path, f.key)
+		}
+		if len(f.allowed) > 0 {
+			if v := *f.ptr(c).(*string); !slices.Contains(f.allowed, v) {
+				return fmt.Errorf("config: %s: %s must be one of %s, got %q", path, f.key, strings.Join(f.allowed, ", "), v)
+			}
+		}
+		if d, ok := f.ptr(c).(*Duration); ok && *d <= 0 {
+			return fmt.Errorf("config: %s: %s must be positive", path, f.key)
+		}
+		c.sources[f.key] = path
+	}
+	// Relative pack paths are relative to the file that lists them.
+	if c.sources["lessons.paths"] == path {
+		for i, p := range c.Lessons.Paths {
+			if !filepath.IsAbs(p) {
+				c.Lessons.Paths[i] = filepath.Join(filepath.Dir(path), p)
+			}
+		}
+	}
+	return nil
+}
+
+// FindRepoFile looks for RepoFileName in dir and its parents, stopping at
+// the first directory that contains .git.
+func FindRepoFile(dir string) (string, bool) {
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", false
+		}
+		dir = wd
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, RepoFileName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// WriteKey sets key to value in the TOML file at path, creating the file if
+// needed and keeping every other key it holds.
+func WriteKey(path, key, value string) error {
+	f, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	if err := Defaults().Set(key, value, ""); err != nil {
+		return err
+	}
+
+	doc := map[string]any{}
+	if _, err := toml.DecodeFile(path, &doc); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	section, name, _ := strings.Cut(f.key, ".")
+	table, _ := doc[section].(map[string]any)
+	if table == nil {
+		table = map[string]any{}
+		doc[section] = table
+	}
+	if _, ok := f.ptr(&Config{}).(*[]string); ok {
+		table[name] = splitList(value)
+	} else {
+		table[name] = value
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("config: create config dir: %w", err)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	if err := toml.NewEncoder(out).Encode(doc); err != nil {
+		out.Close()
+		return fmt.Errorf("config: write %s: %w", path, err)
+	}
+	return out.Close()
+}
+
+// Entry is one key with its effective value and origin.
+type Entry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// List returns every key with its value and source.
+func (c *Config) List() []Entry {
+	entries := make([]Entry, 0, len(fields))
+	for _, f := range fields {
+		v, _ := c.Get(f.key)
+		entries = append(entries, Entry{Key: f.key, Value: v, Source: c.Source(f.key)})
+	}
+	return entries
+}
+
+// PackFiles expands Lessons.Paths into pack files. Directories contribute
+// their *.json files in name order.
+func (c *Config) PackFiles() ([]string, error) {
+	var files []string
+	for _, p := range c.Lessons.Paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("config: lessons.paths: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("config: lessons.paths: %w", err)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func parseDuration(s string) (Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive, got %s", s)
+	}
+	return Duration(d), nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, string(os.PathListSeparator)) {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadLayers(t *testing.T) {
+	root := t.TempDir()
+	user := filepath.Join(root, "user.toml")
+	writeFile(t, user, "[timeouts]\ncheck = \"8s\"\nstep = \"30s\"\n\n[ui]\ntheme = \"light\"\n")
+
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "docs")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	repoFile := filepath.Join(repo, RepoFileName)
+	writeFile(t, repoFile, "[timeouts]\nstep = \"45s\"\n\n[branches]\ndefault = \"trunk\"\n")
+
+	env := map[string]string{"TSCGIT_BRANCHES_DEFAULT": "develop"}
+	cfg, err := Load(Options{UserFile: user, Dir: sub, LookupEnv: func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := map[string][2]string{
+		"timeouts.check":   {"8s", user},
+		"timeouts.step":    {"45s", repoFile},
+		"ui.theme":         {"light", user},
+		"branches.default": {"develop", "env TSCGIT_BRANCHES_DEFAULT"},
+		"output.format":    {"tui", "default"},
+	}
+	for key, w := range want {
+		got, err := cfg.Get(key)
+		if err != nil {
+			t.Fatalf("Get(%s): %v", key, err)
+		}
+		if got != w[0] || cfg.Source(key) != w[1] {
+			t.Errorf("%s = %q from %q, want %q from %q", key, got, cfg.Source(key), w[0], w[1])
+		}
+	}
+	if time.Duration(cfg.Timeouts.Check) != 8*time.Second {
+		t.Errorf("Timeouts.Check = %v", cfg.Timeouts.Check)
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":   "[ui]\ncolour = \"never\"\n",
+		"bad enum":      "[output]\nformat = \"yaml\"\n",
+		"bad duration":  "[timeouts]\ncheck = \"soon\"\n",
+		"zero duration": "[timeouts]\ncheck = \"0s\"\n",
+	}
+	for name, body := range cases {
+		path := filepath.Join(t.TempDir(), "config.toml")
+		writeFile(t, path, body)
+		_, err := Load(Options{UserFile: path, Dir: t.TempDir(), LookupEnv: noEnv})
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoadRejectsUserOnlyKeysInRepoFile(t *testing.T) {
+	for _, body := range []string{"[lessons]\npaths = [\"evil.json\"]\n", "[receipts]\nkey = \"student.pem\"\n"} {
+		repo := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(repo, RepoFileName), body)
+		if _, err := Load(Options{UserFile: filepath.Join(repo, "none.toml"), Dir: repo, LookupEnv: noEnv}); err == nil {
+			t.Errorf("expected %q in %s to be rejected", body, RepoFileName)
+		}
+
+		user := filepath.Join(t.TempDir(), "config.toml")
+		writeFile(t, user, body)
+		if _, err := Load(Options{UserFile: user, Dir: t.TempDir(), LookupEnv: noEnv}); err != nil {
+			t.Errorf("expected %q in the user file to load: %v", body, err)
+		}
+	}
+}
+
+func TestWriteKeyKeepsOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.toml")
+	if err := WriteKey(path, "ui.color", "never"); err != nil {
+		t.Fatalf("WriteKey: %v", err)
+	}
+	paths := strings.Join([]string{"a", "b"}, string(os.PathListSeparator))
+	if err := WriteKey(path, "lessons.paths", paths); err != nil {
+		t.Fatalf("WriteKey: %v", err)
+	}
+	if err := WriteKey(path, "ui.color", "rainbow"); err == nil {
+		t.Fatalf("expected invalid value to be rejected")
+	}
+
+	cfg, err := Load(Options{UserFile: path, Dir: t.TempDir(), LookupEnv: noEnv})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	dir := filepath.Dir(path)
+	want := filepath.Join(dir, "a") + "," + filepath.Join(dir, "b")
+	if cfg.UI.Color != "never" || strings.Join(cfg.Lessons.Paths, ",") != want {
+		t.Fatalf("unexpected config: color=%q paths=%v", cfg.UI.Color, cfg.Lessons.Paths)
+	}
+}
+
+func noEnv(string) (string, bool) { return "", false }
+
+func writeFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
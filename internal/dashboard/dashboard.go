@@ -0,0 +1,369 @@
+// Package dashboard summarises a class from the files the CLI writes, JSON
+// gradebooks from tscgit grade and progress files, and serves the summary
+// as web pages and a JSON API.
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/grade"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// Check is the latest status of one check or script step.
+type Check struct {
+	ID      string `json:"id"`
+	Title   string `json:"title,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Result is a student's latest outcome of one lesson or script.
+type Result struct {
+	Kind    progress.Kind `json:"kind"`
+	ID      string        `json:"id"`
+	Title   string        `json:"title"`
+	Passed  bool          `json:"passed"`
+	Points  int           `json:"points"`
+	Max     int           `json:"max"`
+	Percent float64       `json:"percent"`
+	// Attempts is only known for results from a progress file.
+	Attempts int     `json:"attempts,omitempty"`
+	Error    string  `json:"error,omitempty"`
+	Checks   []Check `json:"checks"`
+}
+
+// Student is everything known about one student.
+type Student struct {
+	Name string `json:"name"`
+	// Sources are the files the student's results were read from.
+	Sources []string `json:"sources"`
+	// Error is set when the student's repository could not be graded.
+	Error   string   `json:"error,omitempty"`
+	Passed  int      `json:"passed"`
+	Results []Result `json:"results"`
+}
+
+// Class is the set of students the dashboard shows, in the order they were
+// first seen.
+type Class struct {
+	Students []*Student `json:"students"`
+	Sources  []string   `json:"sources"`
+}
+
+// Student returns the student called name, or nil.
+func (c *Class) Student(name string) *Student {
+	for _, s := range c.Students {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Add merges s into the class. Results from a later source replace earlier
+// results for the same lesson or script.
+func (c *Class) Add(s Student) {
+	cur := c.Student(s.Name)
+	if cur == nil {
+		cur = &Student{Name: s.Name, Sources: []string{}, Results: []Result{}}
+		c.Students = append(c.Students, cur)
+	}
+	cur.Sources = append(cur.Sources, s.Sources...)
+	if s.Error != "" {
+		cur.Error = s.Error
+	}
+	for _, r := range s.Results {
+		i := slices.IndexFunc(cur.Results, func(old Result) bool { return old.Kind == r.Kind && old.ID == r.ID })
+		if i >= 0 {
+			cur.Results[i] = withTitles(r, cur.Results[i])
+		} else {
+			cur.Results = append(cur.Results, r)
+		}
+	}
+	cur.Passed = 0
+	for _, r := range cur.Results {
+		if r.Passed {
+			cur.Passed++
+		}
+	}
+}
+
+// withTitles fills titles r lacks from an older result for the same lesson,
+// such as a gradebook entry for a pack lesson this tscgit does not know.
+func withTitles(r, old Result) Result {
+	if r.Title == r.ID {
+		r.Title = old.Title
+	}
+	for i, c := range r.Checks {
+		if c.Title != "" {
+			continue
+		}
+		if j := slices.IndexFunc(old.Checks, func(o Check) bool { return o.ID == c.ID }); j >= 0 {
+			r.Checks[i].Title = old.Checks[j].Title
+		}
+	}
+	return r
+}
+
+// FromGradebook converts every row of a gradebook.
+func FromGradebook(book *grade.Gradebook, source string) []Student {
+	students := make([]Student, 0, len(book.Students))
+	for _, row := range book.Students {
+		s := Student{Name: row.Student, Sources: []string{source}, Error: row.Error}
+		for _, lr := range row.Lessons {
+			r := Result{
+				Kind:    progress.KindLesson,
+				ID:      lr.Lesson,
+				Title:   lr.Title,
+				Passed:  lr.Passed,
+				Points:  lr.Score.Points,
+				Max:     lr.Score.Max,
+				Percent: lr.Score.Percent,
+				Error:   lr.Error,
+				Checks:  make([]Check, 0, len(lr.Checks)),
+			}
+			for _, c := range lr.Checks {
+				msg := c.Message
+				if c.Error != "" {
+					msg = c.Error
+				}
+				r.Checks = append(r.Checks, Check{ID: c.ID, Title: c.Title, Status: c.Status, Message: msg})
+			}
+			s.Results = append(s.Results, r)
+		}
+		students = append(students, s)
+	}
+	return students
+}
+
+// FromProgress converts the records of one student's progress file. Titles
+// come from the registered lessons and scripts when they are known.
+func FromProgress(name string, records []progress.Record, source string) Student {
+	s := Student{Name: name, Sources: []string{source}}
+	for _, rec := range records {
+		r := Result{
+			Kind:     rec.Kind,
+			ID:       rec.ID,
+			Title:    rec.ID,
+			Passed:   rec.Passed,
+			Points:   rec.Points,
+			Max:      rec.Max,
+			Percent:  rec.Percent,
+			Attempts: rec.Attempts,
+			Checks:   []Check{},
+		}
+		var checks []lessons.Check
+		switch rec.Kind {
+		case progress.KindLesson:
+			if lesson, err := lessons.Get(rec.ID); err == nil {
+				r.Title, checks = lesson.Title, lesson.Checks
+			}
+		case progress.KindScript:
+			if script, ok := run.Get(rec.ID); ok {
+				r.Title = script.Title
+			}
+		}
+		// Keep the lesson's check order; anything else follows sorted.
+		seen := map[string]bool{}
+		for _, c := range checks {
+			if status, ok := rec.Checks[c.ID]; ok {
+				r.Checks = append(r.Checks, Check{ID: c.ID, Title: c.Title, Status: status})
+				seen[c.ID] = true
+			}
+		}
+		for _, id := range sortedIDs(rec.Checks) {
+			if !seen[id] {
+				r.Checks = append(r.Checks, Check{ID: id, Status: rec.Checks[id]})
+			}
+		}
+		s.Results = append(s.Results, r)
+	}
+	return s
+}
+
+// sortedIDs orders check IDs, comparing script step numbers numerically.
+func sortedIDs(checks map[string]string) []string {
+	ids := make([]string, 0, len(checks))
+	for id := range checks {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if len(a) != len(b) && isDigits(a) && isDigits(b) {
+			return len(a) - len(b)
+		}
+		return strings.Compare(a, b)
+	})
+	return ids
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// LoadFile reads a JSON gradebook or a progress file. A progress file
+// belongs to the student named after the file, or after its directory
+// when the file is called progress.json.
+func LoadFile(path string) ([]Student, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard: %w", err)
+	}
+	var probe struct {
+		Students json.RawMessage `json:"students"`
+		Records  json.RawMessage `json:"records"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("dashboard: %s: %w", path, err)
+	}
+	switch {
+	case probe.Students != nil:
+		book, err := grade.ReadJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("dashboard: %s: %w", path, err)
+		}
+		return FromGradebook(book, path), nil
+	case probe.Records != nil:
+		store, err := progress.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("dashboard: %w", err)
+		}
+		return []Student{FromProgress(ProgressOwner(path), store.List(), path)}, nil
+	}
+	return nil, fmt.Errorf("dashboard: %s is neither a JSON gradebook nor a progress file", path)
+}
+
+// ProgressOwner names the student a progress file belongs to.
+func ProgressOwner(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name == "progress" {
+		if dir := filepath.Base(filepath.Dir(path)); dir != "." && dir != string(filepath.Separator) {
+			return dir
+		}
+	}
+	return name
+}
+
+// Load builds a class from files. Later files override earlier ones for the
+// same student and lesson.
+func Load(paths []string) (*Class, error) {
+	class := &Class{Students: []*Student{}, Sources: paths}
+	for _, path := range paths {
+		students, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range students {
+			class.Add(s)
+		}
+	}
+	return class, nil
+}
+
+// LessonSummary is how a class did on one lesson or script.
+type LessonSummary struct {
+	Kind  progress.Kind `json:"kind"`
+	ID    string        `json:"id"`
+	Title string        `json:"title"`
+	// Attempted counts students with a result; Passed those who passed.
+	Attempted      int     `json:"attempted"`
+	Passed         int     `json:"passed"`
+	PassRate       float64 `json:"passRate"`
+	AveragePercent float64 `json:"averagePercent"`
+}
+
+// StuckCheck is a check students fail, counting failed and errored runs.
+type StuckCheck struct {
+	Kind        progress.Kind `json:"kind"`
+	Lesson      string        `json:"lesson"`
+	LessonTitle string        `json:"lessonTitle"`
+	Check       string        `json:"check"`
+	Title       string        `json:"title"`
+	Stuck       int           `json:"stuck"`
+	Attempted   int           `json:"attempted"`
+	// Students lists who is stuck, in class order.
+	Students []string `json:"students"`
+}
+
+// Overview is the class-wide summary.
+type Overview struct {
+	Students int             `json:"students"`
+	Errors   int             `json:"errors"`
+	Lessons  []LessonSummary `json:"lessons"`
+	Stuck    []StuckCheck    `json:"stuck"`
+	Sources  []string        `json:"sources"`
+}
+
+// Overview computes pass rates per lesson, in first-seen order, and the
+// checks students are stuck on, most common first.
+func (c *Class) Overview() Overview {
+	ov := Overview{Students: len(c.Students), Lessons: []LessonSummary{}, Stuck: []StuckCheck{}, Sources: c.Sources}
+	lessonIndex := map[string]int{}
+	stuckIndex := map[string]int{}
+	var percents []float64
+	for _, s := range c.Students {
+		if s.Error != "" {
+			ov.Errors++
+		}
+		for _, r := range s.Results {
+			key := string(r.Kind) + ":" + r.ID
+			i, ok := lessonIndex[key]
+			if !ok {
+				i = len(ov.Lessons)
+				lessonIndex[key] = i
+				ov.Lessons = append(ov.Lessons, LessonSummary{Kind: r.Kind, ID: r.ID, Title: r.Title})
+				percents = append(percents, 0)
+			}
+			ov.Lessons[i].Attempted++
+			percents[i] += r.Percent
+			if r.Passed {
+				ov.Lessons[i].Passed++
+			}
+
+			for _, check := range r.Checks {
+				if check.Status == verify.StatusSkipped {
+					continue
+				}
+				ckey := key + "/" + check.ID
+				j, ok := stuckIndex[ckey]
+				if !ok {
+					j = len(ov.Stuck)
+					stuckIndex[ckey] = j
+					ov.Stuck = append(ov.Stuck, StuckCheck{Kind: r.Kind, Lesson: r.ID, LessonTitle: r.Title, Check: check.ID, Title: check.Title, Students: []string{}})
+				}
+				sc := &ov.Stuck[j]
+				sc.Attempted++
+				if sc.Title == "" {
+					sc.Title = check.Title
+				}
+				if check.Status != verify.StatusPassed {
+					sc.Stuck++
+					sc.Students = append(sc.Students, s.Name)
+				}
+			}
+		}
+	}
+	for i := range ov.Lessons {
+		l := &ov.Lessons[i]
+		l.PassRate = float64(l.Passed) * 100 / float64(l.Attempted)
+		l.AveragePercent = percents[i] / float64(l.Attempted)
+	}
+	ov.Stuck = slices.DeleteFunc(ov.Stuck, func(sc StuckCheck) bool { return sc.Stuck == 0 })
+	slices.SortStableFunc(ov.Stuck, func(a, b StuckCheck) int {
+		if a.Stuck != b.Stuck {
+			return b.Stuck - a.Stuck
+		}
+		// Among equal counts, a check fewer students reached is harder.
+		return a.Attempted - b.Attempted
+	})
+	return ov
+}
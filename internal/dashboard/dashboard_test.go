@@ -0,0 +1,136 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/grade"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+func report(passed bool, statuses ...string) grade.LessonResult {
+	r := verify.Report{Lesson: "readme", Title: "Readme", Passed: passed, Score: verify.Score{Max: 2}}
+	for i, status := range statuses {
+		id := []string{"has-readme", "committed"}[i]
+		r.Checks = append(r.Checks, verify.CheckReport{ID: id, Title: "Check " + id, Status: status})
+		if status == verify.StatusPassed {
+			r.Score.Points++
+		}
+	}
+	r.Score.Percent = float64(r.Score.Points) * 50
+	return grade.LessonResult{Report: r}
+}
+
+func sampleClass(t *testing.T) (dir string, paths []string) {
+	t.Helper()
+	dir = t.TempDir()
+	book := &grade.Gradebook{Students: []grade.Result{
+		{Student: "alice", Lessons: []grade.LessonResult{report(true, verify.StatusPassed, verify.StatusPassed)}},
+		{Student: "bob", Lessons: []grade.LessonResult{report(false, verify.StatusPassed, verify.StatusFailed)}},
+		{Student: "carol", Lessons: []grade.LessonResult{report(false, verify.StatusFailed, verify.StatusSkipped)}},
+		{Student: "dave", Error: "directory not found", Lessons: []grade.LessonResult{}},
+	}}
+	gradebook := filepath.Join(dir, "grades.json")
+	f, err := os.Create(gradebook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := book.WriteJSON(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// bob's own progress file has since recorded a pass.
+	store, err := progress.Load(filepath.Join(dir, "bob", "progress.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Put(progress.Record{Kind: progress.KindLesson, ID: "readme", Passed: true, Points: 2, Max: 2, Percent: 100,
+		Checks: map[string]string{"has-readme": verify.StatusPassed, "committed": verify.StatusPassed}})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return dir, []string{gradebook, store.Path()}
+}
+
+func TestOverview(t *testing.T) {
+	_, paths := sampleClass(t)
+	class, err := Load(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ov := class.Overview()
+	if ov.Students != 4 || ov.Errors != 1 || len(ov.Lessons) != 1 {
+		t.Fatalf("unexpected overview %+v", ov)
+	}
+	if l := ov.Lessons[0]; l.Attempted != 3 || l.Passed != 2 || l.PassRate < 66 || l.PassRate > 67 {
+		t.Fatalf("pass rate = %+v", l)
+	}
+	// Only carol is still stuck; bob's progress file replaced his gradebook
+	// result, and skipped checks do not count.
+	if len(ov.Stuck) != 1 || ov.Stuck[0].Check != "has-readme" || strings.Join(ov.Stuck[0].Students, ",") != "carol" || ov.Stuck[0].Attempted != 3 {
+		t.Fatalf("stuck = %+v", ov.Stuck)
+	}
+	bob := class.Student("bob")
+	if len(bob.Sources) != 2 || bob.Results[0].Attempts != 1 || bob.Passed != 1 {
+		t.Fatalf("bob = %+v", bob)
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("missing file should fail")
+	}
+	other := filepath.Join(t.TempDir(), "other.json")
+	os.WriteFile(other, []byte(`{"version":1}`), 0o644)
+	if _, err := LoadFile(other); err == nil || !strings.Contains(err.Error(), "neither") {
+		t.Fatalf("unrelated JSON: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	_, paths := sampleClass(t)
+	srv := httptest.NewServer(NewHandler(func() (*Class, error) { return Load(paths) }))
+	defer srv.Close()
+
+	get := func(path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+
+	resp, body := get("/")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "Class overview") || !strings.Contains(body, `href="/students/carol"`) {
+		t.Fatalf("overview page: %d\n%s", resp.StatusCode, body)
+	}
+	resp, body = get("/students/bob")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "1 attempt<") || !strings.Contains(body, "Check committed") {
+		t.Fatalf("student page: %d\n%s", resp.StatusCode, body)
+	}
+
+	resp, body = get("/api/overview")
+	var ov Overview
+	if err := json.Unmarshal([]byte(body), &ov); err != nil || resp.Header.Get("Content-Type") != "application/json" || ov.Students != 4 {
+		t.Fatalf("api overview: %v %+v", err, ov)
+	}
+	resp, body = get("/api/students/nobody")
+	if resp.StatusCode != http.StatusNotFound || !strings.Contains(body, `"error"`) {
+		t.Fatalf("unknown student: %d %s", resp.StatusCode, body)
+	}
+	if resp, _ = get("/nowhere"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown page: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,120 @@
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.0f%%", v) },
+	"status":  statusClass,
+	"join":    strings.Join,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// stuckShown is how many stuck checks the overview page lists.
+const stuckShown = 10
+
+// Loader reads the class afresh; the server calls it on every request so
+// the pages follow the files as the CLI rewrites them.
+type Loader func() (*Class, error)
+
+// NewHandler serves the dashboard pages and the JSON API:
+//
+//	GET /                     overview page
+//	GET /students/{name}      one student's results
+//	GET /api/overview         Overview
+//	GET /api/students         every Student
+//	GET /api/students/{name}  one Student
+func NewHandler(load Loader) http.Handler {
+	mux := http.NewServeMux()
+	withClass := func(h func(http.ResponseWriter, *http.Request, *Class)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			class, err := load()
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			h(w, r, class)
+		}
+	}
+
+	mux.HandleFunc("GET /{$}", withClass(func(w http.ResponseWriter, r *http.Request, c *Class) {
+		ov := c.Overview()
+		stuck := ov.Stuck
+		if len(stuck) > stuckShown {
+			stuck = stuck[:stuckShown]
+		}
+		render(w, "overview", map[string]any{"Overview": ov, "Stuck": stuck, "Students": c.Students, "Now": time.Now()})
+	}))
+	mux.HandleFunc("GET /students/{name}", withClass(func(w http.ResponseWriter, r *http.Request, c *Class) {
+		s := c.Student(r.PathValue("name"))
+		if s == nil {
+			writeError(w, r, http.StatusNotFound, fmt.Sprintf("no student named %q", r.PathValue("name")))
+			return
+		}
+		render(w, "student", map[string]any{"Student": s, "Now": time.Now()})
+	}))
+	mux.HandleFunc("GET /api/overview", withClass(func(w http.ResponseWriter, r *http.Request, c *Class) {
+		writeJSON(w, http.StatusOK, c.Overview())
+	}))
+	mux.HandleFunc("GET /api/students", withClass(func(w http.ResponseWriter, r *http.Request, c *Class) {
+		writeJSON(w, http.StatusOK, c.Students)
+	}))
+	mux.HandleFunc("GET /api/students/{name}", withClass(func(w http.ResponseWriter, r *http.Request, c *Class) {
+		s := c.Student(r.PathValue("name"))
+		if s == nil {
+			writeError(w, r, http.StatusNotFound, fmt.Sprintf("no student named %q", r.PathValue("name")))
+			return
+		}
+		writeJSON(w, http.StatusOK, s)
+	}))
+	return mux
+}
+
+func render(w http.ResponseWriter, name string, data any) {
+	var buf strings.Builder
+	if err := pages.ExecuteTemplate(&buf, name, data); err != nil {
+		http.Error(w, "dashboard: render "+name+": "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, buf.String())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError answers API requests with JSON and page requests with text.
+func writeError(w http.ResponseWriter, r *http.Request, code int, msg string) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		writeJSON(w, code, map[string]string{"error": msg})
+		return
+	}
+	http.Error(w, msg, code)
+}
+
+func statusClass(status string) string {
+	switch status {
+	case verify.StatusPassed:
+		return "pass"
+	case verify.StatusSkipped:
+		return "skip"
+	default:
+		return "fail"
+	}
+}
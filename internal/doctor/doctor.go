@@ -0,0 +1,286 @@
+// Package doctor inspects the environment tscgit runs in and suggests fixes
+// for anything that would make lessons or scripts fail.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// Status is the outcome of a single finding.
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// MinGitVersion is the oldest git tscgit supports; git switch and git
+// restore, used throughout the lessons, arrived in 2.23.
+var MinGitVersion = gitutil.Version{Major: 2, Minor: 23}
+
+// Finding is one diagnostic with a suggested fix when it is not OK.
+type Finding struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// Report collects every finding. OK is false when any finding failed.
+type Report struct {
+	OK       bool      `json:"ok"`
+	Findings []Finding `json:"findings"`
+}
+
+// Options selects what Run inspects. Empty fields use the defaults tscgit
+// itself would use.
+type Options struct {
+	// Path is the directory checked for a repository.
+	Path string
+	// ConfigDir is tscgit's configuration directory.
+	ConfigDir string
+	// ProgressPath is the progress file.
+	ProgressPath string
+	// Terminal is the file whose terminal capabilities are reported.
+	Terminal *os.File
+	// DefaultBranch is suggested when init.defaultBranch is unset.
+	DefaultBranch string
+}
+
+// Run performs every check.
+func Run(ctx context.Context, opts Options) Report {
+	if opts.Terminal == nil {
+		opts.Terminal = os.Stdout
+	}
+	if opts.DefaultBranch == "" {
+		opts.DefaultBranch = "main"
+	}
+	findings := []Finding{checkGit(ctx), checkShell()}
+	findings = append(findings, checkIdentity(ctx)...)
+	findings = append(findings, checkDefaultBranch(ctx, opts.DefaultBranch), checkTerminal(opts.Terminal))
+
+	configDir, err := resolveConfigDir(opts.ConfigDir)
+	if err != nil {
+		findings = append(findings, Finding{ID: "config-dir", Title: "Config directory", Status: StatusFail, Detail: err.Error(), Fix: "Set HOME (or XDG_CONFIG_HOME / %AppData%) so tscgit can store its settings."})
+	} else {
+		findings = append(findings, checkWritable("config-dir", "Config directory", configDir))
+	}
+	progressPath := opts.ProgressPath
+	if progressPath == "" {
+		progressPath, err = progress.DefaultPath()
+	}
+	if err != nil {
+		findings = append(findings, Finding{ID: "progress-dir", Title: "Progress directory", Status: StatusFail, Detail: err.Error(), Fix: "Set HOME (or XDG_CONFIG_HOME / %AppData%) so tscgit can save progress."})
+	} else {
+		findings = append(findings, checkWritable("progress-dir", "Progress directory", filepath.Dir(progressPath)))
+	}
+
+	findings = append(findings, checkRepository(ctx, opts.Path))
+
+	report := Report{OK: true, Findings: findings}
+	for _, f := range findings {
+		if f.Status == StatusFail {
+			report.OK = false
+		}
+	}
+	return report
+}
+
+func resolveConfigDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config dir: %w", err)
+	}
+	return filepath.Join(base, "tscgit"), nil
+}
+
+func checkGit(ctx context.Context) Finding {
+	f := Finding{ID: "git", Title: "Git"}
+	path, err := exec.LookPath("git")
+	if err != nil {
+		f.Status, f.Detail = StatusFail, "git was not found on your PATH."
+		f.Fix = "Install Git from https://git-scm.com and open a new terminal."
+		return f
+	}
+	v, err := gitutil.GitVersion(ctx)
+	if err != nil {
+		f.Status, f.Detail = StatusFail, fmt.Sprintf("%s did not report its version: %v", path, err)
+		f.Fix = "Reinstall Git from https://git-scm.com."
+		return f
+	}
+	return versionFinding(f, path, v)
+}
+
+func versionFinding(f Finding, path string, v gitutil.Version) Finding {
+	f.Detail = fmt.Sprintf("%s (%s)", path, v)
+	if !v.AtLeast(MinGitVersion.Major, MinGitVersion.Minor, MinGitVersion.Patch) {
+		f.Status = StatusFail
+		f.Detail += fmt.Sprintf("; tscgit needs %s or newer", MinGitVersion)
+		f.Fix = "Upgrade Git from https://git-scm.com (or your package manager)."
+		return f
+	}
+	f.Status = StatusOK
+	return f
+}
+
+func checkShell() Finding {
+	shell, args := run.DefaultShell()
+	f := Finding{ID: "shell", Title: "Script shell"}
+	path, err := exec.LookPath(shell)
+	if err != nil {
+		f.Status, f.Detail = StatusFail, fmt.Sprintf("%s was not found on your PATH; run scripts cannot execute.", shell)
+		if shell == "sh" {
+			f.Fix = "Install a POSIX shell (sh) or add it to PATH."
+		} else {
+			f.Fix = "Install PowerShell from https://aka.ms/powershell or add it to PATH."
+		}
+		return f
+	}
+	f.Status, f.Detail = StatusOK, fmt.Sprintf("%s %s (%s)", shell, strings.Join(args, " "), path)
+	return f
+}
+
+func checkIdentity(ctx context.Context) []Finding {
+	var findings []Finding
+	for _, key := range []struct{ key, title, example string }{
+		{"user.name", "Git user.name", `"Your Name"`},
+		{"user.email", "Git user.email", "you@example.com"},
+	} {
+		f := Finding{ID: key.key, Title: key.title}
+		value, ok, err := gitutil.GlobalConfig(ctx, key.key)
+		switch {
+		case err != nil:
+			f.Status, f.Detail = StatusWarn, verify.Describe(err)
+		case !ok || value == "":
+			f.Status, f.Detail = StatusFail, key.key+" is not set, so git commit will refuse to run."
+			f.Fix = fmt.Sprintf("git config --global %s %s", key.key, key.example)
+		default:
+			f.Status, f.Detail = StatusOK, value
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func checkDefaultBranch(ctx context.Context, suggested string) Finding {
+	f := Finding{ID: "init.defaultBranch", Title: "Default branch"}
+	value, ok, err := gitutil.GlobalConfig(ctx, "init.defaultBranch")
+	switch {
+	case err != nil:
+		f.Status, f.Detail = StatusWarn, verify.Describe(err)
+	case !ok || value == "":
+		f.Status, f.Detail = StatusWarn, "init.defaultBranch is not set; new repositories start on git's built-in default."
+		f.Fix = "git config --global init.defaultBranch " + suggested
+	default:
+		f.Status, f.Detail = StatusOK, value
+	}
+	return f
+}
+
+func checkTerminal(out *os.File) Finding {
+	f := Finding{ID: "terminal", Title: "Terminal"}
+	if !term.IsTerminal(out.Fd()) {
+		f.Status, f.Detail = StatusWarn, "output is not a terminal; interactive screens are unavailable."
+		f.Fix = "Run tscgit in a terminal, or use --format text or --format json for scripted output."
+		return f
+	}
+	profile := colorProfileName(lipgloss.ColorProfile())
+	width, height, err := term.GetSize(out.Fd())
+	if err != nil {
+		f.Status, f.Detail = StatusWarn, fmt.Sprintf("TTY, %s color, size unknown: %v", profile, err)
+		return f
+	}
+	f.Detail = fmt.Sprintf("TTY, %s color, %dx%d", profile, width, height)
+	switch {
+	case width < 80:
+		f.Status, f.Fix = StatusWarn, "Widen the terminal to at least 80 columns so lesson screens fit."
+	case lipgloss.ColorProfile() == termenv.Ascii:
+		f.Status, f.Fix = StatusWarn, "Set TERM=xterm-256color (or use a terminal with color support) to see status colors."
+	default:
+		f.Status = StatusOK
+	}
+	return f
+}
+
+func colorProfileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "true"
+	case termenv.ANSI256:
+		return "256"
+	case termenv.ANSI:
+		return "16"
+	default:
+		return "no"
+	}
+}
+
+// checkWritable checks that dir, or the nearest parent that exists when dir
+// has not been created yet, accepts a scratch file. It creates nothing that
+// outlives the check.
+func checkWritable(id, title, dir string) Finding {
+	f := Finding{ID: id, Title: title, Detail: dir}
+	fix := fmt.Sprintf("Make %s writable, or point tscgit somewhere writable (for example by setting XDG_CONFIG_HOME).", dir)
+	probe := dir
+	for {
+		info, err := os.Stat(probe)
+		if err == nil && !info.IsDir() {
+			f.Status, f.Detail, f.Fix = StatusFail, fmt.Sprintf("cannot create %s: %s is not a directory", dir, probe), fix
+			return f
+		}
+		if err == nil {
+			break
+		}
+		parent := filepath.Dir(probe)
+		if !errors.Is(err, fs.ErrNotExist) || parent == probe {
+			f.Status, f.Detail, f.Fix = StatusFail, fmt.Sprintf("cannot create %s: %v", dir, err), fix
+			return f
+		}
+		probe = parent
+	}
+	tmp, err := os.CreateTemp(probe, ".doctor-*")
+	if err != nil {
+		f.Status, f.Detail, f.Fix = StatusFail, fmt.Sprintf("cannot write to %s: %v", probe, err), fix
+		return f
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	if probe != dir {
+		f.Detail = fmt.Sprintf("%s (not created yet; %s is writable)", dir, probe)
+	}
+	f.Status = StatusOK
+	return f
+}
+
+func checkRepository(ctx context.Context, path string) Finding {
+	f := Finding{ID: "repository", Title: "Repository"}
+	repo, err := gitutil.Open(ctx, path)
+	if err != nil {
+		f.Status, f.Detail = StatusWarn, verify.Describe(err)
+		f.Fix = "cd into your practice repository (or run git init there) before tscgit verify."
+		return f
+	}
+	f.Status, f.Detail = StatusOK, repo.Root
+	return f
+}
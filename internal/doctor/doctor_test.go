@@ -0,0 +1,61 @@
+package doctor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func TestVersionFinding(t *testing.T) {
+	old := versionFinding(Finding{ID: "git"}, "/usr/bin/git", gitutil.Version{Major: 2, Minor: 17, Patch: 1})
+	if old.Status != StatusFail || old.Fix == "" {
+		t.Fatalf("expected old git to fail with a fix, got %+v", old)
+	}
+	current := versionFinding(Finding{ID: "git"}, "/usr/bin/git", gitutil.Version{Major: 2, Minor: 43})
+	if current.Status != StatusOK || current.Detail != "/usr/bin/git (2.43.0)" {
+		t.Fatalf("unexpected finding for current git: %+v", current)
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	if f := checkWritable("config-dir", "Config directory", dir); f.Status != StatusOK {
+		t.Fatalf("expected writable dir, got %+v", f)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected scratch file to be removed, got %v %v", entries, err)
+	}
+
+	missing := filepath.Join(dir, "tscgit", "progress")
+	if f := checkWritable("progress-dir", "Progress directory", missing); f.Status != StatusOK {
+		t.Fatalf("expected a missing dir under a writable parent to pass, got %+v", f)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tscgit")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected checkWritable not to create directories, got %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if f := checkWritable("config-dir", "Config directory", filepath.Join(file, "sub")); f.Status != StatusFail || f.Fix == "" {
+		t.Fatalf("expected failure with a fix, got %+v", f)
+	}
+}
+
+func TestCheckTerminalOutsideTerminal(t *testing.T) {
+	out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	f := checkTerminal(out)
+	if f.Status != StatusWarn || f.Fix != "Run tscgit in a terminal, or use --format text or --format json for scripted output." {
+		t.Fatalf("expected a warning pointing at --format, got %+v", f)
+	}
+}
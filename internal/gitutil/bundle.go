@@ -0,0 +1,82 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitDir returns the absolute path of the repository's git directory.
+func (r *Repository) GitDir(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CreateBundle writes a bundle of every ref and HEAD to path. Commits that
+// are only reachable from reflogs are included too, so a restored
+// repository can still resolve every reflog entry.
+func (r *Repository) CreateBundle(ctx context.Context, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("gitutil: %w", err)
+	}
+	if _, err := r.git(ctx, "bundle", "create", "--quiet", abs, "--all", "--reflog"); err != nil {
+		if IsNoCommits(err) {
+			return ErrNoCommits
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneBundle restores the bundle at path into a new repository at dir.
+// Every ref in the bundle is recreated under its own name, unlike git
+// clone, which turns branches into remote-tracking branches. HEAD is
+// attached to branch and checked out; an empty branch detaches HEAD at the
+// commit the bundle recorded for it.
+func CloneBundle(ctx context.Context, path, dir, branch string) (*Repository, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gitutil: create %s: %w", dir, err)
+	}
+	// Reflogs are restored as files, so keep the files ref storage even
+	// where init.defaultRefFormat asks for another.
+	if _, err := gitCommand(ctx, dir, "-c", "init.defaultRefFormat=files", "init", "--quiet"); err != nil {
+		return nil, err
+	}
+	repo := &Repository{Root: dir}
+	if _, err := repo.git(ctx, "fetch", "--quiet", "--update-head-ok", abs, "+refs/*:refs/*"); err != nil {
+		return nil, err
+	}
+
+	if branch != "" {
+		if _, err := repo.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+			return nil, err
+		}
+	} else {
+		out, err := repo.git(ctx, "bundle", "list-heads", abs, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		hash, _, _ := strings.Cut(strings.TrimSpace(out), " ")
+		if hash == "" {
+			return nil, errors.New("gitutil: bundle does not record HEAD")
+		}
+		if _, err := repo.git(ctx, "update-ref", "--no-deref", "HEAD", hash); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := repo.git(ctx, "reset", "--quiet", "--hard"); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
@@ -0,0 +1,37 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GlobalConfig reads key from the user's global git configuration. ok is
+// false when the key is not set.
+func GlobalConfig(ctx context.Context, key string) (value string, ok bool, err error) {
+	out, err := gitCommand(ctx, "", "config", "--global", "--get", key)
+	if err != nil {
+		// git config exits with 1 when the key is missing.
+		var gitErr *Error
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("gitutil: %w", err)
+	}
+	return strings.TrimSpace(out), true, nil
+}
+
+// Config reads key from the configuration in effect for the repository,
+// including global and system settings. ok is false when the key is not set.
+func (r *Repository) Config(ctx context.Context, key string) (value string, ok bool, err error) {
+	out, err := r.git(ctx, "config", "--get", key)
+	if err != nil {
+		var gitErr *Error
+		if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return strings.TrimSpace(out), true, nil
+}
@@ -0,0 +1,249 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/trace"
+)
+
+var (
+	// ErrNotRepository marks errors from commands run outside a git
+	// repository.
+	ErrNotRepository = errors.New("gitutil: not a git repository")
+	// ErrNoCommits marks errors caused by HEAD not pointing at a commit yet.
+	ErrNoCommits = errors.New("gitutil: repository has no commits")
+)
+
+// Error describes a git command that failed. It unwraps to the underlying
+// exec error and, when a follow-up probe identified the cause, to
+// ErrNotRepository or ErrNoCommits.
+type Error struct {
+	Args []string
+	Dir  string
+	// ExitCode is git's exit status, or -1 if git could not be started.
+	ExitCode int
+	Stderr   string
+	Err      error
+	cause    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("git command failed: git %s: %v: %s", strings.Join(e.Args, " "), e.Err, e.Stderr)
+}
+
+func (e *Error) Unwrap() []error {
+	if e.cause != nil {
+		return []error{e.Err, e.cause}
+	}
+	return []error{e.Err}
+}
+
+// configOverrides pin configuration that would otherwise change the output
+// checks parse. They are passed with -c on every gitutil call.
+var configOverrides = []string{
+	"color.ui=false",
+	"core.pager=cat",
+	"core.quotePath=false",
+	"log.showSignature=false",
+}
+
+// safetyOverrides keep a repository's own configuration from running
+// programs while tscgit reads it, so repositories handed in by someone else,
+// as in tscgit grade, can be checked in place: no fsmonitor or hooks, and no
+// transports beyond local paths. Unlike configOverrides they are not
+// exported by Environ, so a student's own commands still run their hooks;
+// UntrustedEnviron exports them for scripts from packs that are not trusted.
+var safetyOverrides = []string{
+	"core.attributesFile=" + os.DevNull,
+	"core.fsmonitor=false",
+	"core.hooksPath=" + os.DevNull,
+	"protocol.allow=never",
+	"protocol.file.allow=always",
+}
+
+// driverKeys matches the configuration keys that name programs git runs for
+// paths given a filter, diff or merge driver in .gitattributes. Attributes
+// come from the work tree itself, so instead of ignoring them, Open pins
+// every such key the repository defines to an empty command.
+const driverKeys = `^(filter|diff|merge)\..+\.(clean|smudge|process|textconv|command|driver)$|^diff\.external$`
+
+// driverOverrides returns the -c settings that turn off the drivers defined
+// in the configuration of the repository at root. git skips empty filters;
+// an empty diff driver makes the diff fail instead, which gitutil avoids by
+// passing --no-ext-diff and --no-textconv wherever it reads patches.
+func driverOverrides(ctx context.Context, root string) ([]string, error) {
+	out, err := gitCommand(ctx, root, "config", "--null", "--name-only", "--get-regexp", driverKeys)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gitutil: list configured drivers: %w", err)
+	}
+	var overrides []string
+	for _, key := range strings.Split(out, "\x00") {
+		if key == "" {
+			continue
+		}
+		if strings.Contains(key, "=") {
+			return nil, fmt.Errorf("gitutil: configured driver %q cannot be turned off", key)
+		}
+		overrides = append(overrides, key+"=")
+		if section, _, ok := strings.Cut(key, "."); ok && section == "filter" {
+			overrides = append(overrides, key[:strings.LastIndex(key, ".")]+".required=false")
+		}
+	}
+	slices.Sort(overrides)
+	return slices.Compact(overrides), nil
+}
+
+// pinnedEnv lists variables set for every git process. Locale variables are
+// replaced by LC_ALL=C so messages and dates are not translated.
+var pinnedEnv = []string{
+	"LC_ALL=C",
+	"GIT_TERMINAL_PROMPT=0",
+	"GIT_PAGER=cat",
+	"PAGER=cat",
+	"NO_COLOR=1",
+}
+
+// Environ returns base with the locale, prompt, pager and color settings
+// pinned for git, and with gitutil's configuration overrides exported through
+// GIT_CONFIG_PARAMETERS so git commands started from a shell honour them too.
+func Environ(base []string) []string {
+	return exportConfig(commandEnv(base), configOverrides)
+}
+
+// UntrustedEnviron is Environ with the safety overrides exported as well, so
+// git commands run by an untrusted script cannot start hooks or other
+// programs named in the repository's configuration.
+func UntrustedEnviron(base []string) []string {
+	return exportConfig(commandEnv(base), slices.Concat(configOverrides, safetyOverrides))
+}
+
+func exportConfig(env, overrides []string) []string {
+	params := make([]string, 0, len(overrides))
+	for _, kv := range overrides {
+		params = append(params, "'"+kv+"'")
+	}
+	joined := strings.Join(params, " ")
+	for i, kv := range env {
+		if existing, ok := strings.CutPrefix(kv, "GIT_CONFIG_PARAMETERS="); ok && existing != "" {
+			env[i] = "GIT_CONFIG_PARAMETERS=" + existing + " " + joined
+			return env
+		}
+	}
+	return append(env, "GIT_CONFIG_PARAMETERS="+joined)
+}
+
+func commandEnv(base []string) []string {
+	env := make([]string, 0, len(base)+len(pinnedEnv))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "LANG" || name == "LANGUAGE" || strings.HasPrefix(name, "LC_") || hasEnvName(pinnedEnv, name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, pinnedEnv...)
+}
+
+func hasEnvName(env []string, name string) bool {
+	for _, kv := range env {
+		if n, _, _ := strings.Cut(kv, "="); n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func gitCommand(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitCommandInput(ctx, dir, "", args...)
+}
+
+// gitCommandInput is gitCommand with stdin as the command's input.
+func gitCommandInput(ctx context.Context, dir, stdin string, args ...string) (string, error) {
+	stdout, gitErr := execGit(ctx, dir, stdin, args)
+	if gitErr == nil {
+		return stdout, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", fmt.Errorf("git command interrupted: git %s: %w", strings.Join(args, " "), ctxErr)
+	}
+	// git exits with 128 when it dies; probe the repository to find out
+	// whether that was because there is no repository or no commits.
+	if gitErr.ExitCode == 128 {
+		gitErr.cause = probe(ctx, dir)
+	}
+	return "", gitErr
+}
+
+// execGit runs git with the pinned environment and configuration.
+func execGit(ctx context.Context, dir, stdin string, args []string) (string, *Error) {
+	full := make([]string, 0, 2*(len(configOverrides)+len(safetyOverrides))+len(args))
+	for _, kv := range slices.Concat(configOverrides, safetyOverrides) {
+		full = append(full, "-c", kv)
+	}
+	full = append(full, args...)
+
+	cmd := exec.CommandContext(ctx, "git", full...)
+	if dir != "" {
+		cmd.Dir = dir
+	}
+	cmd.Env = commandEnv(os.Environ())
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	code := 0
+	if err != nil {
+		code = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+	}
+	trace.Command(trace.KindGit, cmd, start, code, stdout.String(), stderr.String(), err)
+	if err != nil {
+		return "", &Error{Args: args, Dir: dir, ExitCode: code, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return stdout.String(), nil
+}
+
+// probe explains a fatal git error by checking, in order, whether dir is
+// inside a repository and whether HEAD resolves to a commit.
+func probe(ctx context.Context, dir string) error {
+	if _, err := execGit(ctx, dir, "", []string{"rev-parse", "--git-dir"}); err != nil {
+		if err.ExitCode > 0 {
+			return ErrNotRepository
+		}
+		return nil
+	}
+	if _, err := execGit(ctx, dir, "", []string{"rev-parse", "--verify", "--quiet", "HEAD"}); err != nil && err.ExitCode == 1 {
+		return ErrNoCommits
+	}
+	return nil
+}
+
+// IsNoCommits reports whether the error corresponds to a repository without commits.
+func IsNoCommits(err error) bool {
+	return errors.Is(err, ErrNoCommits)
+}
+
+// IsNotRepository reports whether the error indicates the directory is not inside a git repository.
+func IsNotRepository(err error) bool {
+	return errors.Is(err, ErrNotRepository)
+}
@@ -0,0 +1,464 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	records := []string{
+		"# branch.oid 1111111111111111111111111111111111111111",
+		"# branch.head main",
+		"# branch.upstream origin/main",
+		"# branch.ab +2 -1",
+		"1 A. N... 000000 100644 100644 0000000000000000000000000000000000000000 2222222222222222222222222222222222222222 contents.md",
+		"1 MM N... 100644 100644 100644 3333333333333333333333333333333333333333 4444444444444444444444444444444444444444 titles.md",
+		"2 R. N... 100644 100644 100644 5555555555555555555555555555555555555555 5555555555555555555555555555555555555555 R100 new name.md",
+		"old name.md",
+		"u UU N... 100644 100644 100644 100644 6666666666666666666666666666666666666666 7777777777777777777777777777777777777777 8888888888888888888888888888888888888888 conflict.txt",
+		"? notes.txt",
+	}
+	status, err := parseStatus(strings.Join(records, "\x00") + "\x00")
+	if err != nil {
+		t.Fatalf("parseStatus: %v", err)
+	}
+
+	if b := status.Branch; b.Head != "main" || b.Upstream != "origin/main" || b.Ahead != 2 || b.Behind != 1 || b.Detached {
+		t.Fatalf("unexpected branch header: %+v", b)
+	}
+	if got := len(status.Staged()); got != 3 {
+		t.Fatalf("expected 3 staged entries, got %d", got)
+	}
+	if mod := status.Modified(); len(mod) != 1 || mod[0].Path != "titles.md" {
+		t.Fatalf("unexpected modified entries: %+v", mod)
+	}
+	if ren := status.Renamed(); len(ren) != 1 || ren[0].Path != "new name.md" || ren[0].OrigPath != "old name.md" || ren[0].Score != "R100" {
+		t.Fatalf("unexpected renamed entries: %+v", ren)
+	}
+	if un := status.Unmerged(); len(un) != 1 || un[0].Path != "conflict.txt" || un[0].Index != StatusUnmerged {
+		t.Fatalf("unexpected unmerged entries: %+v", un)
+	}
+	if ut := status.Untracked(); len(ut) != 1 || ut[0].Path != "notes.txt" {
+		t.Fatalf("unexpected untracked entries: %+v", ut)
+	}
+	if e, ok := status.Entry("contents.md"); !ok || e.Index != StatusAdded || e.Worktree != StatusUnmodified {
+		t.Fatalf("unexpected contents.md entry: %+v", e)
+	}
+}
+
+func TestParseStatusInitialDetached(t *testing.T) {
+	status, err := parseStatus("# branch.oid (initial)\x00# branch.head (detached)\x00")
+	if err != nil {
+		t.Fatalf("parseStatus: %v", err)
+	}
+	if status.Branch.OID != "" || !status.Branch.Detached || !status.Clean() {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]string{
+		"git version 2.43.0\n":               "2.43.0",
+		"git version 2.39.3 (Apple Git-146)": "2.39.3",
+		"git version 2.45.1.windows.1":       "2.45.1",
+		"git version 2.50":                   "2.50.0",
+	}
+	for out, want := range cases {
+		v, err := ParseVersion(out)
+		if err != nil || v.String() != want {
+			t.Fatalf("ParseVersion(%q) = %v, %v; want %s", out, v, err, want)
+		}
+	}
+	v, _ := ParseVersion("git version 2.11.0")
+	if !v.AtLeast(2, 11, 0) || v.AtLeast(2, 11, 1) || !v.AtLeast(1, 99, 0) || v.AtLeast(3, 0, 0) {
+		t.Fatalf("unexpected AtLeast results for %s", v)
+	}
+	if _, err := ParseVersion("hub version 1"); err == nil {
+		t.Fatalf("expected unexpected output to fail")
+	}
+}
+
+func TestEnvironPinsLocale(t *testing.T) {
+	env := Environ([]string{"LANG=de_DE.UTF-8", "LC_MESSAGES=fr_FR", "GIT_PAGER=less", "HOME=/home/x", "GIT_CONFIG_PARAMETERS='user.name=x'"})
+	joined := strings.Join(env, "\n")
+	for _, banned := range []string{"LANG=", "LC_MESSAGES=", "GIT_PAGER=less"} {
+		if strings.Contains(joined, banned) {
+			t.Fatalf("expected %s to be removed:\n%s", banned, joined)
+		}
+	}
+	for _, want := range []string{"LC_ALL=C", "GIT_TERMINAL_PROMPT=0", "HOME=/home/x", "GIT_CONFIG_PARAMETERS='user.name=x' 'color.ui=false'"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %s in environment:\n%s", want, joined)
+		}
+	}
+}
+
+func TestUntrustedEnvironExportsSafetyOverrides(t *testing.T) {
+	joined := strings.Join(UntrustedEnviron(nil), "\n")
+	for _, want := range []string{"'core.fsmonitor=false'", "'core.hooksPath=" + os.DevNull + "'", "'protocol.allow=never'"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %s in environment:\n%s", want, joined)
+		}
+	}
+	if strings.Contains(strings.Join(Environ(nil), "\n"), "core.hooksPath") {
+		t.Fatalf("expected Environ to leave hooks alone")
+	}
+}
+
+func TestErrorsAreClassifiedByProbes(t *testing.T) {
+	ctx := context.Background()
+	if _, err := GitVersion(ctx); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+
+	_, err := Open(ctx, t.TempDir())
+	if !IsNotRepository(err) {
+		t.Fatalf("expected ErrNotRepository, got %v", err)
+	}
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || gitErr.ExitCode != 128 {
+		t.Fatalf("expected *Error with exit code 128, got %#v", err)
+	}
+
+	dir := t.TempDir()
+	if _, err := gitCommand(ctx, dir, "init", "--quiet"); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	repo, err := Open(ctx, dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := repo.git(ctx, "log", "-1"); !IsNoCommits(err) {
+		t.Fatalf("expected ErrNoCommits, got %v", err)
+	}
+	if n, err := repo.CommitCount(ctx); err != nil || n != 0 {
+		t.Fatalf("CommitCount = %d, %v", n, err)
+	}
+}
+
+func TestParseReflog(t *testing.T) {
+	zero := strings.Repeat("0", 40)
+	a, b := strings.Repeat("a", 40), strings.Repeat("b", 40)
+	data := zero + " " + a + " Ada Lovelace <ada@example.com> 1760000000 +0130\tcommit (initial): A\n" +
+		a + " " + b + " Ada Lovelace <ada@example.com> 1760000060 +0130\tcommit: B: add notes\n" +
+		b + " " + a + " Ada Lovelace <ada@example.com> 1760000120 -0500\treset: moving to HEAD~1\n" +
+		a + " " + a + " Ada Lovelace <ada@example.com> 1760000180 +0000\tmanual update\n"
+
+	entries, err := ParseReflog("HEAD", []byte(data))
+	if err != nil {
+		t.Fatalf("ParseReflog: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	reset := entries[1]
+	if reset.Ref != "HEAD" || reset.Old != b || reset.New != a || reset.Action != "reset" || reset.Message != "moving to HEAD~1" {
+		t.Fatalf("unexpected reset entry %+v", reset)
+	}
+	if reset.Who != "Ada Lovelace <ada@example.com>" || reset.Time.Unix() != 1760000120 {
+		t.Fatalf("unexpected identity %q at %v", reset.Who, reset.Time)
+	}
+	if _, offset := reset.Time.Zone(); offset != -5*3600 {
+		t.Fatalf("expected -0500, got offset %d", offset)
+	}
+	if e := entries[2]; e.Action != "commit" || e.Message != "B: add notes" {
+		t.Fatalf("message should keep later colons: %+v", e)
+	}
+	if e := entries[3]; !e.Created() || e.Action != "commit (initial)" {
+		t.Fatalf("oldest entry should create the ref: %+v", e)
+	}
+	if e := entries[0]; e.Action != "" || e.Message != "manual update" {
+		t.Fatalf("entry without an action: %+v", e)
+	}
+
+	if _, err := ParseReflog("HEAD", []byte(a+" "+b+" no identity\tcommit: x\n")); err == nil {
+		t.Fatalf("expected a malformed line to fail")
+	}
+	if got := string(FormatReflog(entries)); got != data {
+		t.Fatalf("FormatReflog should restore the file:\n%s\nwant\n%s", got, data)
+	}
+}
+
+func TestReflogThroughGit(t *testing.T) {
+	ctx := context.Background()
+	if _, err := GitVersion(ctx); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	repo, git := newTestRepo(t)
+	if log, err := repo.Reflog(ctx, "HEAD"); err != nil || len(log) != 0 {
+		t.Fatalf("unborn HEAD: expected an empty reflog, got %v, %v", log, err)
+	}
+	git("commit", "--quiet", "--allow-empty", "-m", "A")
+	a := git("rev-parse", "HEAD")
+	git("commit", "--quiet", "--allow-empty", "-m", "B: second")
+	b := git("rev-parse", "HEAD")
+	git("reset", "--quiet", "--hard", a)
+	branch := git("symbolic-ref", "--short", "HEAD")
+
+	log, err := repo.Reflog(ctx, branch)
+	if err != nil {
+		t.Fatalf("Reflog: %v", err)
+	}
+	if len(log) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", log)
+	}
+	if e := log[0]; e.Ref != "refs/heads/"+branch || e.Old != b || e.New != a || e.Action != "reset" || e.Who != "S <s@x>" || e.Time.IsZero() {
+		t.Fatalf("unexpected reset entry %+v", e)
+	}
+	if e := log[1]; e.Action != "commit" || e.Message != "B: second" || e.Old != a {
+		t.Fatalf("unexpected commit entry %+v", e)
+	}
+	if e := log[2]; !e.Created() || e.Action != "commit (initial)" {
+		t.Fatalf("oldest entry should create the ref: %+v", e)
+	}
+
+	logs, err := repo.Reflogs(ctx)
+	if err != nil {
+		t.Fatalf("Reflogs: %v", err)
+	}
+	if len(logs["HEAD"]) != 3 || len(logs["refs/heads/"+branch]) != 3 {
+		t.Fatalf("unexpected reflogs %v", logs)
+	}
+
+	// Once the entry that created the branch has expired, the oldest one
+	// left neither created it nor says where it moved from.
+	git("reflog", "delete", branch+"@{2}")
+	log, err = repo.Reflog(ctx, branch)
+	if err != nil {
+		t.Fatalf("Reflog: %v", err)
+	}
+	if len(log) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", log)
+	}
+	if e := log[1]; e.Created() || e.Old != "" || e.New != b || e.Action != "commit" {
+		t.Fatalf("expired reflog: unexpected oldest entry %+v", e)
+	}
+	if line, _, _ := strings.Cut(string(FormatReflog(log)), "\n"); !strings.HasPrefix(line, b+" "+b+" ") {
+		t.Fatalf("an unknown old hash should be written as the new one: %q", line)
+	}
+}
+
+func TestPatchIDsIgnoreHashes(t *testing.T) {
+	ctx := context.Background()
+	if _, err := GitVersion(ctx); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	repo, git := newTestRepo(t)
+	dir := repo.Root
+	git("commit", "--quiet", "--allow-empty", "-m", "Start")
+	base := git("rev-parse", "HEAD")
+	git("switch", "--quiet", "--create", "side")
+	for _, name := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "--quiet", "-m", "Add "+name)
+	}
+	git("commit", "--quiet", "--allow-empty", "-m", "Nothing")
+
+	patches, err := repo.PatchIDs(ctx, base, "side")
+	if err != nil {
+		t.Fatalf("PatchIDs: %v", err)
+	}
+	if len(patches) != 3 || patches[0].Subject != "Add a" || patches[0].ID == "" || patches[2].ID != "" {
+		t.Fatalf("unexpected patches %+v", patches)
+	}
+
+	// A copy of "Add b" on another branch has a new hash but the same patch.
+	git("switch", "--quiet", "--detach", base)
+	git("cherry-pick", patches[1].Commit)
+	copied, err := repo.PatchIDs(ctx, base, "HEAD")
+	if err != nil {
+		t.Fatalf("PatchIDs: %v", err)
+	}
+	if len(copied) != 1 || copied[0].Commit == patches[1].Commit || copied[0].ID != patches[1].ID {
+		t.Fatalf("cherry-pick should keep the patch ID: %+v vs %+v", copied, patches[1])
+	}
+
+	combined, err := repo.DiffPatchID(ctx, base, "side")
+	if err != nil || combined == "" || combined == patches[0].ID || combined == patches[1].ID {
+		t.Fatalf("DiffPatchID = %q, %v", combined, err)
+	}
+	if same, err := repo.DiffPatchID(ctx, "side~1", "side"); err != nil || same != "" {
+		t.Fatalf("an empty diff should have no patch ID, got %q, %v", same, err)
+	}
+}
+
+// newTestRepo initialises a repository in a temporary directory and returns
+// it with a helper that runs git there as a fixed identity and returns the
+// trimmed output.
+func newTestRepo(t *testing.T) (*Repository, func(args ...string) string) {
+	t.Helper()
+	ctx := context.Background()
+	repo := &Repository{Root: t.TempDir()}
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := repo.git(ctx, append([]string{"-c", "user.name=S", "-c", "user.email=s@x"}, args...)...)
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(out)
+	}
+	git("init", "--quiet")
+	return repo, git
+}
+
+func TestStashes(t *testing.T) {
+	ctx := context.Background()
+	if _, err := GitVersion(ctx); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	cases := []struct {
+		name string
+		// change edits the work tree before git stash is run with args.
+		change   func(write func(name, content string), git func(...string) string)
+		args     []string
+		detached bool
+		// files is nil when git stash has nothing to save.
+		files []string
+	}{
+		{
+			name:   "tracked change",
+			change: func(write func(string, string), _ func(...string) string) { write("notes.txt", "edited\n") },
+			files:  []string{"notes.txt"},
+		},
+		{
+			name: "staged new file",
+			change: func(write func(string, string), git func(...string) string) {
+				write("todo.txt", "milk\n")
+				git("add", "todo.txt")
+			},
+			files: []string{"todo.txt"},
+		},
+		{
+			name:   "untracked file left out",
+			change: func(write func(string, string), _ func(...string) string) { write("scratch.txt", "tmp\n") },
+		},
+		{
+			name: "untracked file with -u",
+			change: func(write func(string, string), _ func(...string) string) {
+				write("notes.txt", "edited\n")
+				write("scratch/a.txt", "tmp\n")
+			},
+			args:  []string{"--include-untracked"},
+			files: []string{"notes.txt", "scratch/a.txt"},
+		},
+		{
+			name:     "detached HEAD",
+			change:   func(write func(string, string), _ func(...string) string) { write("notes.txt", "edited\n") },
+			detached: true,
+			files:    []string{"notes.txt"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, git := newTestRepo(t)
+			write := func(name, content string) {
+				t.Helper()
+				path := filepath.Join(repo.Root, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("notes.txt", "draft\n")
+			git("add", "notes.txt")
+			git("commit", "--quiet", "-m", "Start")
+			base := git("rev-parse", "HEAD")
+			branch := git("symbolic-ref", "--short", "HEAD")
+			if tc.detached {
+				git("switch", "--quiet", "--detach")
+				branch = ""
+			}
+			tc.change(write, git)
+			git(append([]string{"stash", "push", "--quiet"}, tc.args...)...)
+
+			stashes, err := repo.Stashes(ctx)
+			if err != nil {
+				t.Fatalf("Stashes: %v", err)
+			}
+			if tc.files == nil {
+				if len(stashes) != 0 {
+					t.Fatalf("expected no stash, got %+v", stashes)
+				}
+				return
+			}
+			if len(stashes) != 1 {
+				t.Fatalf("expected one stash, got %+v", stashes)
+			}
+			s := stashes[0]
+			if s.Index != 0 || s.Base != base || s.Branch != branch || s.Time.IsZero() || strings.Join(s.Files, ",") != strings.Join(tc.files, ",") {
+				t.Fatalf("unexpected stash %+v", s)
+			}
+
+			git("stash", "drop", "--quiet")
+			dropped, err := repo.DroppedStashes(ctx)
+			if err != nil {
+				t.Fatalf("DroppedStashes: %v", err)
+			}
+			if len(dropped) != 1 || dropped[0].Index != -1 || dropped[0].Commit != s.Commit || strings.Join(dropped[0].Files, ",") != strings.Join(tc.files, ",") {
+				t.Fatalf("expected the dropped stash to be found, got %+v", dropped)
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	if _, err := GitVersion(ctx); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	repo, git := newTestRepo(t)
+	git("commit", "--quiet", "--allow-empty", "-m", "Start")
+	head := git("rev-parse", "HEAD")
+	git("tag", "light")
+	git("tag", "-a", "v1.0.0", "-m", "First release\n\nNotes.")
+	git("tag", "-a", "tree-tag", "-m", "A tree", "HEAD^{tree}")
+	git("tag", "release/v2", head)
+
+	cases := []struct {
+		name      string
+		annotated bool
+		commit    string
+		message   string
+	}{
+		{name: "light", commit: head},
+		{name: "release/v2", commit: head},
+		{name: "tree-tag", annotated: true, message: "A tree"},
+		{name: "v1.0.0", annotated: true, commit: head, message: "First release\n\nNotes."},
+	}
+	tags, err := repo.Tags(ctx)
+	if err != nil {
+		t.Fatalf("Tags: %v", err)
+	}
+	if len(tags) != len(cases) {
+		t.Fatalf("expected %d tags, got %+v", len(cases), tags)
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tags[i]
+			if got.Name != tc.name || got.Annotated != tc.annotated || got.Commit != tc.commit || got.Message != tc.message {
+				t.Fatalf("unexpected tag %+v", got)
+			}
+			// An annotated tag's ref names the tag object, with its own
+			// tagger; a lightweight one names the commit.
+			if tc.annotated != (got.Hash != tc.commit && got.Tagger == "S <s@x>" && !got.Time.IsZero()) {
+				t.Fatalf("unexpected tag object for %+v", got)
+			}
+			one, ok, err := repo.Tag(ctx, tc.name)
+			if err != nil || !ok || one != got {
+				t.Fatalf("Tag(%q) = %+v, %v, %v", tc.name, one, ok, err)
+			}
+		})
+	}
+	if _, ok, err := repo.Tag(ctx, "release"); ok || err != nil {
+		t.Fatalf("Tag(release) should not match release/v2: %v, %v", ok, err)
+	}
+}
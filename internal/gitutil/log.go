@@ -0,0 +1,149 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a single commit as listed by Log.
+type Commit struct {
+	Hash       string
+	Parents    []string
+	Tree       string
+	AuthorTime time.Time
+	Subject    string
+}
+
+// RefKind distinguishes local branches, remote-tracking branches and tags.
+type RefKind string
+
+const (
+	RefBranch RefKind = "branch"
+	RefRemote RefKind = "remote"
+	RefTag    RefKind = "tag"
+)
+
+// Ref is a named pointer to a commit. Annotated tags are peeled so Hash is
+// always a commit.
+type Ref struct {
+	Name string
+	Kind RefKind
+	Hash string
+}
+
+// Head describes what HEAD points at. Branch is empty when HEAD is detached
+// and Hash is empty when the current branch has no commits yet.
+type Head struct {
+	Hash   string
+	Branch string
+}
+
+// Log returns up to limit commits reachable from HEAD, branches, tags and
+// remote-tracking branches, children before parents. A limit of zero or less
+// lists every commit. A repository without commits yields an empty slice.
+func (r *Repository) Log(ctx context.Context, limit int) ([]Commit, error) {
+	args := []string{"log", "--topo-order", "--format=%H%x00%P%x00%T%x00%at%x00%s", "--branches", "--tags", "--remotes"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	head, err := r.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.Hash != "" {
+		args = append(args, "HEAD")
+	}
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		if IsNoCommits(err) {
+			return []Commit{}, nil
+		}
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 5)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("gitutil: parse log line %q", line)
+		}
+		unix, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("gitutil: parse log line %q: %w", line, err)
+		}
+		commits = append(commits, Commit{
+			Hash:       fields[0],
+			Parents:    strings.Fields(fields[1]),
+			Tree:       fields[2],
+			AuthorTime: time.Unix(unix, 0).UTC(),
+			Subject:    fields[4],
+		})
+	}
+	return commits, nil
+}
+
+// Refs lists local branches, remote-tracking branches and tags.
+func (r *Repository) Refs(ctx context.Context) ([]Ref, error) {
+	out, err := r.git(ctx, "for-each-ref", "--format=%(refname)%00%(objectname)%00%(*objectname)", "refs/heads", "refs/remotes", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("gitutil: parse ref line %q", line)
+		}
+		ref := Ref{Hash: fields[1]}
+		if fields[2] != "" {
+			ref.Hash = fields[2]
+		}
+		switch name := fields[0]; {
+		case strings.HasPrefix(name, "refs/heads/"):
+			ref.Kind, ref.Name = RefBranch, strings.TrimPrefix(name, "refs/heads/")
+		case strings.HasPrefix(name, "refs/remotes/"):
+			ref.Kind, ref.Name = RefRemote, strings.TrimPrefix(name, "refs/remotes/")
+			if strings.HasSuffix(ref.Name, "/HEAD") {
+				continue
+			}
+		case strings.HasPrefix(name, "refs/tags/"):
+			ref.Kind, ref.Name = RefTag, strings.TrimPrefix(name, "refs/tags/")
+		default:
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// Head reports the commit and branch HEAD currently points at.
+func (r *Repository) Head(ctx context.Context) (Head, error) {
+	var head Head
+	out, err := r.git(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err == nil {
+		head.Branch = strings.TrimSpace(out)
+	} else if ctx.Err() != nil {
+		return head, err
+	}
+
+	out, err = r.git(ctx, "rev-parse", "--verify", "--quiet", "HEAD^{commit}")
+	if err != nil {
+		if ctx.Err() != nil {
+			return head, err
+		}
+		// An unborn branch has no commit to resolve.
+		return head, nil
+	}
+	head.Hash = strings.TrimSpace(out)
+	return head, nil
+}
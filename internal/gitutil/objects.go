@@ -0,0 +1,218 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ObjectType is the kind of a git object.
+type ObjectType string
+
+const (
+	ObjectCommit ObjectType = "commit"
+	ObjectTree   ObjectType = "tree"
+	ObjectBlob   ObjectType = "blob"
+	ObjectTag    ObjectType = "tag"
+)
+
+// Object identifies a git object in the object database.
+type Object struct {
+	Hash string
+	Type ObjectType
+	Size int64
+}
+
+// CommitObject is a decoded commit. Raw holds the header lines exactly as
+// stored, without the message.
+type CommitObject struct {
+	Object
+	Tree      string
+	Parents   []string
+	Author    string
+	Committer string
+	Message   string
+	Raw       string
+}
+
+// TagObject is a decoded annotated tag.
+type TagObject struct {
+	Object
+	Target     string
+	TargetType ObjectType
+	Name       string
+	Tagger     string
+	Message    string
+	Raw        string
+}
+
+// TreeEntry is one row of a tree object.
+type TreeEntry struct {
+	Mode string
+	Type ObjectType
+	Hash string
+	Name string
+}
+
+// ResolveObject looks up rev, which may be a hash, ref or an expression such
+// as HEAD:contents.md, and reports the object it names.
+func (r *Repository) ResolveObject(ctx context.Context, rev string) (Object, error) {
+	out, err := r.git(ctx, "rev-parse", "--verify", rev)
+	if err != nil {
+		return Object{}, err
+	}
+	hash := strings.TrimSpace(out)
+	typ, err := r.git(ctx, "cat-file", "-t", hash)
+	if err != nil {
+		return Object{}, err
+	}
+	size, err := r.git(ctx, "cat-file", "-s", hash)
+	if err != nil {
+		return Object{}, err
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+	if err != nil {
+		return Object{}, fmt.Errorf("gitutil: parse object size: %w", err)
+	}
+	return Object{Hash: hash, Type: ObjectType(strings.TrimSpace(typ)), Size: n}, nil
+}
+
+// ReadCommit decodes the commit named by rev.
+func (r *Repository) ReadCommit(ctx context.Context, rev string) (CommitObject, error) {
+	obj, raw, err := r.readObject(ctx, rev, ObjectCommit)
+	if err != nil {
+		return CommitObject{}, err
+	}
+	c := CommitObject{Object: obj}
+	headers, message := splitHeaders(raw)
+	c.Raw, c.Message = headers, message
+	for _, line := range strings.Split(headers, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			c.Tree = value
+		case "parent":
+			c.Parents = append(c.Parents, value)
+		case "author":
+			c.Author = value
+		case "committer":
+			c.Committer = value
+		}
+	}
+	return c, nil
+}
+
+// ReadTag decodes the annotated tag named by rev.
+func (r *Repository) ReadTag(ctx context.Context, rev string) (TagObject, error) {
+	obj, raw, err := r.readObject(ctx, rev, ObjectTag)
+	if err != nil {
+		return TagObject{}, err
+	}
+	t := TagObject{Object: obj}
+	headers, message := splitHeaders(raw)
+	t.Raw, t.Message = headers, message
+	for _, line := range strings.Split(headers, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "object":
+			t.Target = value
+		case "type":
+			t.TargetType = ObjectType(value)
+		case "tag":
+			t.Name = value
+		case "tagger":
+			t.Tagger = value
+		}
+	}
+	return t, nil
+}
+
+// ReadTree lists the entries of the tree named by rev. A commit resolves to
+// its root tree.
+func (r *Repository) ReadTree(ctx context.Context, rev string) ([]TreeEntry, error) {
+	out, err := r.git(ctx, "ls-tree", "-z", rev)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TreeEntry
+	for _, rec := range strings.Split(out, "\x00") {
+		if rec == "" {
+			continue
+		}
+		meta, name, ok := strings.Cut(rec, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("gitutil: parse tree entry %q", rec)
+		}
+		entries = append(entries, TreeEntry{Mode: fields[0], Type: ObjectType(fields[1]), Hash: fields[2], Name: name})
+	}
+	return entries, nil
+}
+
+// FileEntry is a file in a commit's tree, listed with its full path.
+type FileEntry struct {
+	Path string
+	Hash string
+	Size int64
+}
+
+// Files lists every file in the tree of rev, recursing into directories.
+// Submodules are skipped.
+func (r *Repository) Files(ctx context.Context, rev string) ([]FileEntry, error) {
+	out, err := r.git(ctx, "ls-tree", "-r", "-l", "-z", rev)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileEntry
+	for _, rec := range strings.Split(out, "\x00") {
+		if rec == "" {
+			continue
+		}
+		meta, name, ok := strings.Cut(rec, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return nil, fmt.Errorf("gitutil: parse tree entry %q", rec)
+		}
+		if ObjectType(fields[1]) != ObjectBlob {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("gitutil: parse tree entry %q: %w", rec, err)
+		}
+		files = append(files, FileEntry{Path: name, Hash: fields[2], Size: size})
+	}
+	return files, nil
+}
+
+// ReadBlob returns the contents of the blob named by rev.
+func (r *Repository) ReadBlob(ctx context.Context, rev string) ([]byte, error) {
+	_, raw, err := r.readObject(ctx, rev, ObjectBlob)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
+
+func (r *Repository) readObject(ctx context.Context, rev string, want ObjectType) (Object, string, error) {
+	obj, err := r.ResolveObject(ctx, rev)
+	if err != nil {
+		return Object{}, "", err
+	}
+	if obj.Type != want {
+		return Object{}, "", fmt.Errorf("gitutil: %s is a %s, not a %s", rev, obj.Type, want)
+	}
+	raw, err := r.git(ctx, "cat-file", string(want), obj.Hash)
+	if err != nil {
+		return Object{}, "", err
+	}
+	return obj, raw, nil
+}
+
+// splitHeaders separates the header block of a commit or tag from its
+// message.
+func splitHeaders(raw string) (headers, message string) {
+	headers, message, _ = strings.Cut(raw, "\n\n")
+	return headers, message
+}
@@ -0,0 +1,105 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Patch is a commit together with the patch ID of the change it makes. Two
+// commits with the same patch ID make the same change, whatever their
+// hashes, parents or messages.
+type Patch struct {
+	Commit  string
+	Subject string
+	// ID is empty for commits that change nothing, such as merges and
+	// empty commits.
+	ID string
+}
+
+// diffArgs keep patch IDs independent of diff drivers and rename
+// detection, which could otherwise differ between the two sides compared.
+var diffArgs = []string{"--no-color", "--no-ext-diff", "--no-textconv", "--no-renames"}
+
+// PatchIDs lists the commits in base..tip, oldest first, with their patch
+// IDs as computed by git patch-id --stable.
+func (r *Repository) PatchIDs(ctx context.Context, base, tip string) ([]Patch, error) {
+	spec := base + ".." + tip
+	out, err := r.git(ctx, "log", "--reverse", "--format=%H%x00%s", spec)
+	if err != nil {
+		return nil, err
+	}
+	patches := []Patch{}
+	index := map[string]int{}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			return nil, fmt.Errorf("gitutil: parse log line %q", line)
+		}
+		index[hash] = len(patches)
+		patches = append(patches, Patch{Commit: hash, Subject: subject})
+	}
+	if len(patches) == 0 {
+		return patches, nil
+	}
+
+	diff, err := r.git(ctx, append([]string{"log", "-p", "--format=commit %H"}, append(diffArgs, spec)...)...)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := r.patchID(ctx, diff)
+	if err != nil {
+		return nil, err
+	}
+	for commit, id := range ids {
+		if i, ok := index[commit]; ok {
+			patches[i].ID = id
+		}
+	}
+	return patches, nil
+}
+
+// DiffPatchID returns the patch ID of the combined change from one commit
+// to another, or "" when they have the same tree. A run of commits squashed
+// into one keeps the combined patch ID of the run.
+func (r *Repository) DiffPatchID(ctx context.Context, from, to string) (string, error) {
+	diff, err := r.git(ctx, append(append([]string{"diff"}, diffArgs...), from, to)...)
+	if err != nil {
+		return "", err
+	}
+	ids, err := r.patchID(ctx, diff)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range ids {
+		return id, nil
+	}
+	return "", nil
+}
+
+// patchID feeds diff to git patch-id and maps each commit it names to its
+// patch ID. A diff without commit headers is reported under the zero hash.
+func (r *Repository) patchID(ctx context.Context, diff string) (map[string]string, error) {
+	ids := map[string]string{}
+	if strings.TrimSpace(diff) == "" {
+		return ids, nil
+	}
+	out, err := gitCommandInput(ctx, r.Root, diff, "patch-id", "--stable")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		id, commit, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("gitutil: parse patch-id line %q", line)
+		}
+		ids[commit] = id
+	}
+	return ids, nil
+}
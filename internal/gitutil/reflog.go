@@ -0,0 +1,216 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReflogEntry is one update of a ref as recorded in its reflog. Old is all
+// zeros when the update created the ref, and empty when it is not known.
+type ReflogEntry struct {
+	Ref string
+	Old string
+	New string
+	// Who is the identity that made the update, as "Name <email>".
+	Who  string
+	Time time.Time
+	// Action is the command that moved the ref, such as "commit",
+	// "commit (amend)", "reset" or "branch". It is empty for updates
+	// recorded without one.
+	Action  string
+	Message string
+}
+
+// Created reports whether the entry created its ref.
+func (e ReflogEntry) Created() bool {
+	return e.Old != "" && strings.Trim(e.Old, "0") == ""
+}
+
+// reflogFormat prints one reflog entry per line for git log --walk-reflogs
+// --date=raw: the commit the ref moved to, the selector, which carries the
+// time as ref@{<unix time> <zone>}, the identity and "action: message".
+const reflogFormat = "--format=%H%x00%gD%x00%gn <%ge>%x00%gs"
+
+// Reflog returns the reflog of ref, newest first, so entry i is ref@{i}.
+// ref is HEAD, a full ref name or a branch name. It is read through git, so
+// it works whichever ref storage the repository uses. A ref without a
+// reflog, or one that does not resolve, such as HEAD on an unborn branch,
+// yields an empty slice.
+func (r *Repository) Reflog(ctx context.Context, ref string) ([]ReflogEntry, error) {
+	if ref != "HEAD" && !strings.HasPrefix(ref, "refs/") {
+		ref = "refs/heads/" + ref
+	}
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", ref); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return []ReflogEntry{}, nil
+	}
+	out, err := r.git(ctx, "log", "--walk-reflogs", "--date=raw", reflogFormat, ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseReflogLog(ref, out)
+}
+
+// Reflogs returns every non-empty reflog in the repository, keyed by ref
+// name: HEAD's and those of existing refs.
+func (r *Repository) Reflogs(ctx context.Context) (map[string][]ReflogEntry, error) {
+	out, err := r.git(ctx, "for-each-ref", "--format=%(refname)")
+	if err != nil {
+		return nil, err
+	}
+	logs := map[string][]ReflogEntry{}
+	for _, ref := range append([]string{"HEAD"}, strings.Fields(out)...) {
+		log, err := r.Reflog(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if len(log) > 0 {
+			logs[ref] = log
+		}
+	}
+	return logs, nil
+}
+
+// parseReflogLog decodes the output of git log --walk-reflogs with
+// reflogFormat. git does not print the value a ref had before each update,
+// so Old is taken from the next older entry. The oldest entry has none: its
+// Old is all zeros if its message says it created the ref, and is otherwise
+// left empty, since older entries may have expired.
+func parseReflogLog(ref, out string) ([]ReflogEntry, error) {
+	entries := []ReflogEntry{}
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("gitutil: parse reflog %s: malformed line %q", ref, line)
+		}
+		e := ReflogEntry{Ref: ref, New: fields[0], Who: fields[2]}
+		start, end := strings.LastIndex(fields[1], "@{"), strings.LastIndex(fields[1], "}")
+		stamp := []string{}
+		if start >= 0 && end > start {
+			stamp = strings.Fields(fields[1][start+2 : end])
+		}
+		if len(stamp) != 2 {
+			return nil, fmt.Errorf("gitutil: parse reflog %s: malformed time in %q", ref, line)
+		}
+		unix, err := strconv.ParseInt(stamp[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("gitutil: parse reflog %s: malformed time in %q: %w", ref, line, err)
+		}
+		e.Time = time.Unix(unix, 0).In(reflogZone(stamp[1]))
+		e.Action, e.Message = splitReflogMessage(fields[3])
+		entries = append(entries, e)
+	}
+	for i := range entries {
+		if i+1 < len(entries) {
+			entries[i].Old = entries[i+1].New
+		} else if e := entries[i]; createdBy(e.Action, e.Message) {
+			entries[i].Old = strings.Repeat("0", len(e.New))
+		}
+	}
+	return entries, nil
+}
+
+// createdBy reports whether a reflog message is one git writes when it
+// creates a ref.
+func createdBy(action, message string) bool {
+	switch action {
+	case "commit (initial)", "clone":
+		return true
+	case "branch":
+		return strings.HasPrefix(message, "Created from ")
+	}
+	return false
+}
+
+// FormatReflog encodes entries, newest first as Reflog returns them, in the
+// format of a reflog file, which ParseReflog reads back. A file needs a hash
+// for every Old, so an unknown one is written as New: the line then records
+// no movement, rather than the creation that zeros would claim.
+func FormatReflog(entries []ReflogEntry) []byte {
+	var b strings.Builder
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Old == "" {
+			e.Old = e.New
+		}
+		msg := e.Message
+		if e.Action != "" {
+			msg = e.Action + ": " + msg
+		}
+		fmt.Fprintf(&b, "%s %s %s %d %s\t%s\n", e.Old, e.New, e.Who, e.Time.Unix(), e.Time.Format("-0700"), msg)
+	}
+	return []byte(b.String())
+}
+
+// ParseReflog decodes the contents of a reflog file for ref, such as the
+// snapshots in a submission, returning the entries newest first. Each line
+// has the form
+//
+//	<old> <new> <name> <<email>> <unix time> <zone>\t<action>: <message>
+func ParseReflog(ref string, data []byte) ([]ReflogEntry, error) {
+	entries := []ReflogEntry{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		e, err := parseReflogLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("gitutil: parse reflog %s: %w", ref, err)
+		}
+		e.Ref = ref
+		entries = append(entries, e)
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func parseReflogLine(line string) (ReflogEntry, error) {
+	head, msg, _ := strings.Cut(line, "\t")
+	old, rest, ok1 := strings.Cut(head, " ")
+	next, rest, ok2 := strings.Cut(rest, " ")
+	end := strings.LastIndex(rest, "> ")
+	if !ok1 || !ok2 || end < 0 {
+		return ReflogEntry{}, fmt.Errorf("malformed line %q", line)
+	}
+	e := ReflogEntry{Old: old, New: next, Who: rest[:end+1]}
+	stamp := strings.Fields(rest[end+2:])
+	if len(stamp) != 2 {
+		return ReflogEntry{}, fmt.Errorf("malformed time in %q", line)
+	}
+	unix, err := strconv.ParseInt(stamp[0], 10, 64)
+	if err != nil {
+		return ReflogEntry{}, fmt.Errorf("malformed time in %q: %w", line, err)
+	}
+	e.Time = time.Unix(unix, 0).In(reflogZone(stamp[1]))
+	e.Action, e.Message = splitReflogMessage(msg)
+	return e, nil
+}
+
+// splitReflogMessage splits "action: message"; messages without an action
+// come back whole.
+func splitReflogMessage(msg string) (action, message string) {
+	if action, message, ok := strings.Cut(msg, ": "); ok {
+		return action, message
+	}
+	return "", msg
+}
+
+// reflogZone turns a "+0130" style offset into a fixed zone, falling back
+// to UTC for anything else.
+func reflogZone(offset string) *time.Location {
+	t, err := time.Parse("-0700", offset)
+	if err != nil {
+		return time.UTC
+	}
+	return t.Location()
+}
@@ -2,12 +2,10 @@ package gitutil
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -15,6 +13,9 @@ import (
 // Repository encapsulates helpers for inspecting a local Git repository.
 type Repository struct {
 	Root string
+	// drivers pins the filter, diff and merge drivers the repository's
+	// configuration defines to empty commands; see driverOverrides.
+	drivers []string
 }
 
 // Open discovers the git repository that contains path. If path is empty, the
@@ -38,7 +39,11 @@ func Open(ctx context.Context, path string) (*Repository, error) {
 		return nil, errors.New("gitutil: repository root is empty")
 	}
 
-	return &Repository{Root: root}, nil
+	drivers, err := driverOverrides(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{Root: root, drivers: drivers}, nil
 }
 
 // CurrentBranch returns the currently checked out branch.
@@ -77,6 +82,11 @@ func (r *Repository) FileExists(relPath string) (bool, error) {
 	return false, err
 }
 
+// ReadFile returns the working tree contents of relPath.
+func (r *Repository) ReadFile(relPath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(r.Root, relPath))
+}
+
 // CommitCount returns the number of commits reachable from HEAD.
 func (r *Repository) CommitCount(ctx context.Context) (int, error) {
 	out, err := r.git(ctx, "rev-list", "--count", "HEAD")
@@ -112,6 +122,15 @@ func (r *Repository) HasRemote(ctx context.Context, name string) (bool, error) {
 	return false, nil
 }
 
+// Remotes lists the names of the configured remotes.
+func (r *Repository) Remotes(ctx context.Context) ([]string, error) {
+	out, err := r.git(ctx, "remote")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
 // LastCommitMessage returns the subject line of the most recent commit.
 func (r *Repository) LastCommitMessage(ctx context.Context) (string, error) {
 	out, err := r.git(ctx, "log", "-1", "--pretty=%s")
@@ -139,29 +158,12 @@ func (r *Repository) CommitsAhead(ctx context.Context, base, compare string) (in
 }
 
 func (r *Repository) git(ctx context.Context, args ...string) (string, error) {
-	return gitCommand(ctx, r.Root, args...)
-}
-
-func gitCommand(ctx context.Context, dir string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", args...)
-	if dir != "" {
-		cmd.Dir = dir
-	}
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("git command failed: git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	if len(r.drivers) == 0 {
+		return gitCommand(ctx, r.Root, args...)
 	}
-	return stdout.String(), nil
-}
-
-// IsNoCommits reports whether the error corresponds to a repository without commits.
-func IsNoCommits(err error) bool {
-	if err == nil {
-		return false
+	full := make([]string, 0, 2*len(r.drivers)+len(args))
+	for _, kv := range r.drivers {
+		full = append(full, "-c", kv)
 	}
-	msg := err.Error()
-	return strings.Contains(msg, "does not have any commits yet") || strings.Contains(msg, "unknown revision or path not in the working tree")
+	return gitCommand(ctx, r.Root, append(full, args...)...)
 }
@@ -0,0 +1,159 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StashEntry is a stash commit. Index is its position in git stash list,
+// or -1 for a stash that was popped or dropped but is still in the object
+// database.
+type StashEntry struct {
+	Index  int
+	Commit string
+	// Base is the commit HEAD was at when the work was stashed.
+	Base string
+	// Branch is the branch that was checked out, or empty when HEAD was
+	// detached.
+	Branch  string
+	Message string
+	Time    time.Time
+	// Files lists the paths the stash saved, staged or not, including
+	// untracked files stashed with --include-untracked.
+	Files []string
+}
+
+// stashSubject matches the messages git stash writes: "WIP on main: ..." by
+// default and "On main: ..." with -m.
+var stashSubject = regexp.MustCompile(`^(?:WIP on|On) ([^:]+): `)
+
+// Stashes lists the stash entries, newest first, as git stash list does.
+func (r *Repository) Stashes(ctx context.Context) ([]StashEntry, error) {
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "refs/stash"); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return []StashEntry{}, nil
+	}
+	out, err := r.git(ctx, "log", "--walk-reflogs", "--format=%H", "refs/stash")
+	if err != nil {
+		return nil, err
+	}
+	hashes := strings.Fields(out)
+	stashes, err := r.stashEntries(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stashes {
+		stashes[i].Index = i
+	}
+	return stashes, nil
+}
+
+// DroppedStashes finds stash commits that are no longer listed, because
+// they were popped or dropped, but have not been garbage collected yet,
+// newest first. It runs git fsck, so it reads the whole object database.
+func (r *Repository) DroppedStashes(ctx context.Context) ([]StashEntry, error) {
+	out, err := r.git(ctx, "fsck", "--unreachable", "--no-progress")
+	if err != nil {
+		return nil, err
+	}
+	var hashes []string
+	for _, line := range strings.Split(out, "\n") {
+		if hash, ok := strings.CutPrefix(line, "unreachable commit "); ok {
+			hashes = append(hashes, strings.TrimSpace(hash))
+		}
+	}
+	if len(hashes) == 0 {
+		return []StashEntry{}, nil
+	}
+	stashes, err := r.stashEntries(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	stashes = slices.DeleteFunc(stashes, func(s StashEntry) bool { return s.Base == "" })
+	slices.SortStableFunc(stashes, func(a, b StashEntry) int { return b.Time.Compare(a.Time) })
+	for i := range stashes {
+		stashes[i].Index = -1
+	}
+	return stashes, nil
+}
+
+// stashEntries describes the given commits in order. Commits that do not
+// look like stashes come back with an empty Base.
+func (r *Repository) stashEntries(ctx context.Context, hashes []string) ([]StashEntry, error) {
+	if len(hashes) == 0 {
+		return []StashEntry{}, nil
+	}
+	args := append([]string{"log", "--no-walk=unsorted", "--format=%H%x00%P%x00%ct%x00%s"}, hashes...)
+	out, err := r.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	stashes := make([]StashEntry, 0, len(hashes))
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("gitutil: parse stash line %q", line)
+		}
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("gitutil: parse stash line %q: %w", line, err)
+		}
+		s := StashEntry{Commit: fields[0], Message: fields[3], Time: time.Unix(unix, 0).UTC()}
+		parents := strings.Fields(fields[1])
+		m := stashSubject.FindStringSubmatch(s.Message)
+		// A stash commit merges the index commit into the base.
+		if len(parents) < 2 || m == nil {
+			stashes = append(stashes, s)
+			continue
+		}
+		s.Base = parents[0]
+		if m[1] != "(no branch)" {
+			s.Branch = m[1]
+		}
+		if s.Files, err = r.stashFiles(ctx, s.Commit, parents); err != nil {
+			return nil, err
+		}
+		stashes = append(stashes, s)
+	}
+	return stashes, nil
+}
+
+// stashFiles lists the paths a stash changed in the index or working tree,
+// plus its untracked files, sorted.
+func (r *Repository) stashFiles(ctx context.Context, commit string, parents []string) ([]string, error) {
+	seen := map[string]bool{}
+	add := func(out string) {
+		for _, p := range strings.Split(out, "\x00") {
+			if p != "" {
+				seen[p] = true
+			}
+		}
+	}
+	for _, rev := range []string{parents[1], commit} {
+		out, err := r.git(ctx, "diff", "--name-only", "-z", "--no-renames", parents[0], rev)
+		if err != nil {
+			return nil, err
+		}
+		add(out)
+	}
+	if len(parents) > 2 {
+		out, err := r.git(ctx, "ls-tree", "-r", "-z", "--name-only", parents[2])
+		if err != nil {
+			return nil, err
+		}
+		add(out)
+	}
+	files := make([]string, 0, len(seen))
+	for p := range seen {
+		files = append(files, p)
+	}
+	slices.Sort(files)
+	return files, nil
+}
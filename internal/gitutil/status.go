@@ -0,0 +1,244 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusCode is one side of a porcelain v2 XY status pair.
+type StatusCode byte
+
+const (
+	StatusUnmodified StatusCode = '.'
+	StatusModified   StatusCode = 'M'
+	StatusTypeChange StatusCode = 'T'
+	StatusAdded      StatusCode = 'A'
+	StatusDeleted    StatusCode = 'D'
+	StatusRenamed    StatusCode = 'R'
+	StatusCopied     StatusCode = 'C'
+	StatusUnmerged   StatusCode = 'U'
+	StatusUntracked  StatusCode = '?'
+	StatusIgnored    StatusCode = '!'
+)
+
+// String returns the code as git prints it.
+func (c StatusCode) String() string {
+	return string(rune(c))
+}
+
+// EntryKind is the porcelain v2 record type of a StatusEntry.
+type EntryKind string
+
+const (
+	EntryChanged   EntryKind = "changed"
+	EntryRenamed   EntryKind = "renamed"
+	EntryUnmerged  EntryKind = "unmerged"
+	EntryUntracked EntryKind = "untracked"
+	EntryIgnored   EntryKind = "ignored"
+)
+
+// StatusEntry describes one path that differs between HEAD, the index and
+// the working tree. Index compares HEAD with the index (staged changes) and
+// Worktree compares the index with the working tree (unstaged changes).
+type StatusEntry struct {
+	Kind     EntryKind
+	Path     string
+	OrigPath string
+	Index    StatusCode
+	Worktree StatusCode
+	// Modes and object names for HEAD and the index, when git reports them.
+	HeadMode, IndexMode, WorktreeMode string
+	HeadHash, IndexHash               string
+	// Score is the rename or copy similarity, such as R100.
+	Score string
+}
+
+// Staged reports whether the index differs from HEAD for this path.
+func (e StatusEntry) Staged() bool {
+	return (e.Kind == EntryChanged || e.Kind == EntryRenamed) && e.Index != StatusUnmodified
+}
+
+// Unstaged reports whether the working tree differs from the index.
+func (e StatusEntry) Unstaged() bool {
+	return (e.Kind == EntryChanged || e.Kind == EntryRenamed) && e.Worktree != StatusUnmodified
+}
+
+// BranchStatus is the branch header of git status --porcelain=v2 --branch.
+type BranchStatus struct {
+	// Head is the branch name, or empty when HEAD is detached.
+	Head     string
+	Detached bool
+	// OID is the HEAD commit, or empty before the first commit.
+	OID      string
+	Upstream string
+	Ahead    int
+	Behind   int
+}
+
+// Status is a typed view of git status.
+type Status struct {
+	Branch  BranchStatus
+	Entries []StatusEntry
+}
+
+// Status reads the state of the index and working tree. Untracked files are
+// listed individually; ignored files are omitted.
+func (r *Repository) Status(ctx context.Context) (*Status, error) {
+	if err := requireVersion(ctx, "git status --porcelain=v2", 2, 11, 0); err != nil {
+		return nil, err
+	}
+	out, err := r.git(ctx, "status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(out)
+}
+
+// Entry returns the entry for path, if it has any changes.
+func (s *Status) Entry(path string) (StatusEntry, bool) {
+	for _, e := range s.Entries {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return StatusEntry{}, false
+}
+
+// Staged returns entries with changes in the index.
+func (s *Status) Staged() []StatusEntry {
+	return s.filter(StatusEntry.Staged)
+}
+
+// Modified returns tracked entries with unstaged working tree changes.
+func (s *Status) Modified() []StatusEntry {
+	return s.filter(StatusEntry.Unstaged)
+}
+
+// Untracked returns paths git is not tracking.
+func (s *Status) Untracked() []StatusEntry {
+	return s.filter(func(e StatusEntry) bool { return e.Kind == EntryUntracked })
+}
+
+// Renamed returns renamed or copied entries.
+func (s *Status) Renamed() []StatusEntry {
+	return s.filter(func(e StatusEntry) bool { return e.Kind == EntryRenamed })
+}
+
+// Unmerged returns paths with unresolved merge conflicts.
+func (s *Status) Unmerged() []StatusEntry {
+	return s.filter(func(e StatusEntry) bool { return e.Kind == EntryUnmerged })
+}
+
+// Clean reports whether nothing is staged, modified, unmerged or untracked.
+func (s *Status) Clean() bool {
+	return len(s.Entries) == 0
+}
+
+func (s *Status) filter(keep func(StatusEntry) bool) []StatusEntry {
+	var out []StatusEntry
+	for _, e := range s.Entries {
+		if keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// TrackedFiles lists every path in the index.
+func (r *Repository) TrackedFiles(ctx context.Context) ([]string, error) {
+	out, err := r.git(ctx, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// parseStatus decodes NUL-terminated git status --porcelain=v2 output.
+func parseStatus(out string) (*Status, error) {
+	s := &Status{}
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		if rec == "" {
+			continue
+		}
+		switch rec[0] {
+		case '#':
+			if err := parseBranchHeader(&s.Branch, rec); err != nil {
+				return nil, err
+			}
+		case '1':
+			f := strings.SplitN(rec, " ", 9)
+			if len(f) != 9 || len(f[1]) != 2 {
+				return nil, fmt.Errorf("gitutil: parse status entry %q", rec)
+			}
+			s.Entries = append(s.Entries, StatusEntry{
+				Kind: EntryChanged, Index: StatusCode(f[1][0]), Worktree: StatusCode(f[1][1]),
+				HeadMode: f[3], IndexMode: f[4], WorktreeMode: f[5],
+				HeadHash: f[6], IndexHash: f[7], Path: f[8],
+			})
+		case '2':
+			f := strings.SplitN(rec, " ", 10)
+			if len(f) != 10 || len(f[1]) != 2 || i+1 >= len(records) {
+				return nil, fmt.Errorf("gitutil: parse status entry %q", rec)
+			}
+			i++
+			s.Entries = append(s.Entries, StatusEntry{
+				Kind: EntryRenamed, Index: StatusCode(f[1][0]), Worktree: StatusCode(f[1][1]),
+				HeadMode: f[3], IndexMode: f[4], WorktreeMode: f[5],
+				HeadHash: f[6], IndexHash: f[7], Score: f[8], Path: f[9], OrigPath: records[i],
+			})
+		case 'u':
+			f := strings.SplitN(rec, " ", 11)
+			if len(f) != 11 || len(f[1]) != 2 {
+				return nil, fmt.Errorf("gitutil: parse status entry %q", rec)
+			}
+			s.Entries = append(s.Entries, StatusEntry{
+				Kind: EntryUnmerged, Index: StatusCode(f[1][0]), Worktree: StatusCode(f[1][1]),
+				WorktreeMode: f[6], Path: f[10],
+			})
+		case '?':
+			s.Entries = append(s.Entries, StatusEntry{Kind: EntryUntracked, Index: StatusUntracked, Worktree: StatusUntracked, Path: strings.TrimPrefix(rec, "? ")})
+		case '!':
+			s.Entries = append(s.Entries, StatusEntry{Kind: EntryIgnored, Index: StatusIgnored, Worktree: StatusIgnored, Path: strings.TrimPrefix(rec, "! ")})
+		default:
+			return nil, fmt.Errorf("gitutil: unknown status record %q", rec)
+		}
+	}
+	return s, nil
+}
+
+func parseBranchHeader(b *BranchStatus, rec string) error {
+	key, value, _ := strings.Cut(strings.TrimPrefix(rec, "# "), " ")
+	switch key {
+	case "branch.oid":
+		if value != "(initial)" {
+			b.OID = value
+		}
+	case "branch.head":
+		if value == "(detached)" {
+			b.Detached = true
+		} else {
+			b.Head = value
+		}
+	case "branch.upstream":
+		b.Upstream = value
+	case "branch.ab":
+		ahead, behind, ok := strings.Cut(value, " ")
+		a, errA := strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+		c, errB := strconv.Atoi(strings.TrimPrefix(behind, "-"))
+		if !ok || errA != nil || errB != nil {
+			return fmt.Errorf("gitutil: parse status header %q", rec)
+		}
+		b.Ahead, b.Behind = a, c
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is a tag ref. Hash is the object the ref names: the tag object for an
+// annotated tag, the tagged object itself for a lightweight one. Tagger,
+// Time and Message are only set for annotated tags.
+type Tag struct {
+	Name      string
+	Hash      string
+	Annotated bool
+	// Commit is the commit the tag resolves to, or empty when it tags a
+	// tree or blob.
+	Commit  string
+	Tagger  string
+	Time    time.Time
+	Message string
+}
+
+// tagFormat separates fields with NUL and records with RS, since tag
+// messages span lines.
+const tagFormat = "%(refname:strip=2)%00%(objecttype)%00%(objectname)%00%(*objecttype)%00%(*objectname)%00%(taggername) %(taggeremail)%00%(taggerdate:raw)%00%(contents)%1e"
+
+// Tags lists the repository's tags in name order.
+func (r *Repository) Tags(ctx context.Context) ([]Tag, error) {
+	out, err := r.git(ctx, "for-each-ref", "--format="+tagFormat, "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	return parseTags(out)
+}
+
+// Tag looks up the tag called name. ok is false when there is no such tag.
+func (r *Repository) Tag(ctx context.Context, name string) (tag Tag, ok bool, err error) {
+	out, err := r.git(ctx, "for-each-ref", "--format="+tagFormat, "refs/tags/"+name)
+	if err != nil {
+		return Tag{}, false, err
+	}
+	tags, err := parseTags(out)
+	if err != nil {
+		return Tag{}, false, err
+	}
+	for _, t := range tags {
+		// for-each-ref also matches whole directories, such as
+		// refs/tags/release for release/v1.
+		if t.Name == name {
+			return t, true, nil
+		}
+	}
+	return Tag{}, false, nil
+}
+
+// RemoteTags lists the tags of remote, which may be a remote name or URL,
+// with annotated tags peeled so Hash is the tagged object. It contacts the
+// remote, so callers should bound ctx. The stock git-upload-pack serves the
+// listing whatever remote.<name>.uploadpack says, since that setting comes
+// from the repository being checked.
+func (r *Repository) RemoteTags(ctx context.Context, remote string) ([]Ref, error) {
+	out, err := r.git(ctx, "ls-remote", "--upload-pack=git-upload-pack", "--tags", remote)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	index := map[string]int{}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		hash, name, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(name, "refs/tags/") {
+			return nil, fmt.Errorf("gitutil: parse ls-remote line %q", line)
+		}
+		name = strings.TrimPrefix(name, "refs/tags/")
+		name, peeled := strings.CutSuffix(name, "^{}")
+		if i, seen := index[name]; seen {
+			if peeled {
+				refs[i].Hash = hash
+			}
+			continue
+		}
+		index[name] = len(refs)
+		refs = append(refs, Ref{Name: name, Kind: RefTag, Hash: hash})
+	}
+	return refs, nil
+}
+
+func parseTags(out string) ([]Tag, error) {
+	tags := []Tag{}
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimPrefix(rec, "\n")
+		if rec == "" {
+			continue
+		}
+		fields := strings.SplitN(rec, "\x00", 8)
+		if len(fields) != 8 {
+			return nil, fmt.Errorf("gitutil: parse tag record %q", rec)
+		}
+		t := Tag{Name: fields[0], Hash: fields[2]}
+		switch {
+		case fields[1] == string(ObjectTag):
+			t.Annotated = true
+			t.Tagger = strings.TrimSpace(fields[5])
+			t.Message = strings.TrimRight(fields[7], "\n")
+			if stamp := strings.Fields(fields[6]); len(stamp) > 0 {
+				unix, err := strconv.ParseInt(stamp[0], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("gitutil: parse tag %s date: %w", t.Name, err)
+				}
+				t.Time = time.Unix(unix, 0).UTC()
+			}
+			if fields[3] == string(ObjectCommit) {
+				t.Commit = fields[4]
+			}
+		case fields[1] == string(ObjectCommit):
+			t.Commit = fields[2]
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
@@ -0,0 +1,93 @@
+package gitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is a parsed git version such as 2.43.0.
+type Version struct {
+	Major, Minor, Patch int
+	// Raw is the full output of git version, e.g. "git version 2.39.3 (Apple Git-146)".
+	Raw string
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the given version or newer.
+func (v Version) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// ParseVersion parses the output of git version. Vendor suffixes such as
+// ".windows.1" or " (Apple Git-146)" are ignored.
+func ParseVersion(out string) (Version, error) {
+	raw := strings.TrimSpace(out)
+	fields := strings.Fields(raw)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return Version{}, fmt.Errorf("gitutil: unexpected git version output %q", raw)
+	}
+	v := Version{Raw: raw}
+	parts := strings.Split(fields[2], ".")
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := 0; i < len(nums) && i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			if i < 2 {
+				return Version{}, fmt.Errorf("gitutil: parse git version %q: %w", raw, err)
+			}
+			break
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+var (
+	versionMu     sync.Mutex
+	cachedVersion *Version
+)
+
+// GitVersion runs git version once per process and caches the result.
+// Failures are not cached so a later call can succeed.
+func GitVersion(ctx context.Context) (Version, error) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+	if cachedVersion != nil {
+		return *cachedVersion, nil
+	}
+	out, err := gitCommand(ctx, "", "version")
+	if err != nil {
+		return Version{}, fmt.Errorf("gitutil: %w", err)
+	}
+	v, err := ParseVersion(out)
+	if err != nil {
+		return Version{}, err
+	}
+	cachedVersion = &v
+	return v, nil
+}
+
+// requireVersion returns an error naming feature when git is older than the
+// given version.
+func requireVersion(ctx context.Context, feature string, major, minor, patch int) error {
+	v, err := GitVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !v.AtLeast(major, minor, patch) {
+		return fmt.Errorf("gitutil: %s needs git %d.%d.%d or newer, found %s", feature, major, minor, patch, v)
+	}
+	return nil
+}
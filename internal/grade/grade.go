@@ -0,0 +1,334 @@
+// Package grade runs verification lessons against a whole class of student
+// repositories and collects the results into a gradebook.
+package grade
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/similarity"
+	"github.com/rohit746/tscgit/internal/submit"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// Submission is one student's repository, or the submission file made by
+// tscgit submit when Bundle is set.
+type Submission struct {
+	Student string `json:"student"`
+	Path    string `json:"path"`
+	Bundle  bool   `json:"bundle,omitempty"`
+}
+
+// Discover treats every non-hidden subdirectory of dir as a submission
+// named after the directory. Plain files are ignored.
+func Discover(dir string) ([]Submission, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("grade: read submissions: %w", err)
+	}
+	var subs []Submission
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		// Follow symlinks so a directory of links to clones works too.
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		subs = append(subs, Submission{Student: e.Name(), Path: path})
+	}
+	return subs, nil
+}
+
+// DiscoverBundles treats every submission file (*.tscgit) in dir as a
+// submission named after the file.
+func DiscoverBundles(dir string) ([]Submission, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("grade: read submissions: %w", err)
+	}
+	var subs []Submission
+	for _, e := range entries {
+		student, ok := strings.CutSuffix(e.Name(), submit.Ext)
+		if !ok || student == "" || strings.HasPrefix(e.Name(), ".") || e.IsDir() {
+			continue
+		}
+		subs = append(subs, Submission{Student: student, Path: filepath.Join(dir, e.Name()), Bundle: true})
+	}
+	return subs, nil
+}
+
+// rosterStudent and rosterRepo list the accepted header names of the roster
+// columns, compared case-insensitively.
+var (
+	rosterStudent = []string{"student", "name", "login", "github_username"}
+	rosterRepo    = []string{"repo", "path", "repository"}
+)
+
+// ReadRoster reads a CSV roster with a header row naming a student column
+// and a repository column; other columns are ignored. Relative repository
+// paths are resolved against dir, normally the roster's own directory.
+func ReadRoster(r io.Reader, dir string) ([]Submission, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("grade: read roster: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("grade: roster is empty")
+	}
+	studentCol, repoCol := -1, -1
+	for i, name := range rows[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case studentCol < 0 && slices.Contains(rosterStudent, name):
+			studentCol = i
+		case repoCol < 0 && slices.Contains(rosterRepo, name):
+			repoCol = i
+		}
+	}
+	if studentCol < 0 || repoCol < 0 {
+		return nil, fmt.Errorf("grade: roster header needs a student column (%s) and a repository column (%s)",
+			strings.Join(rosterStudent, ", "), strings.Join(rosterRepo, ", "))
+	}
+
+	seen := make(map[string]int)
+	var subs []Submission
+	for n, row := range rows[1:] {
+		line := n + 2
+		if len(row) <= max(studentCol, repoCol) {
+			return nil, fmt.Errorf("grade: roster line %d: missing columns", line)
+		}
+		student, path := strings.TrimSpace(row[studentCol]), strings.TrimSpace(row[repoCol])
+		if student == "" && path == "" {
+			continue
+		}
+		if student == "" || path == "" {
+			return nil, fmt.Errorf("grade: roster line %d: student and repository are both required", line)
+		}
+		if prev, ok := seen[student]; ok {
+			return nil, fmt.Errorf("grade: roster line %d: student %q already listed on line %d", line, student, prev)
+		}
+		seen[student] = line
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		subs = append(subs, Submission{Student: student, Path: path})
+	}
+	return subs, nil
+}
+
+// LoadRoster reads the roster file at path. See ReadRoster.
+func LoadRoster(path string) ([]Submission, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("grade: open roster: %w", err)
+	}
+	defer f.Close()
+	return ReadRoster(f, filepath.Dir(path))
+}
+
+// Options controls a grading run.
+type Options struct {
+	Lessons []*lessons.Lesson
+	// Workers bounds how many repositories are graded at once. Values below
+	// one grade them one at a time.
+	Workers int
+	// Verify is passed to verify.RunWithOptions for every lesson.
+	Verify verify.Options
+	// Progress, when set, is called as each submission finishes. Calls may
+	// come from several goroutines but never overlap.
+	Progress func(Result)
+	// Fingerprint collects each repository's similarity fingerprint while
+	// it is open, for Gradebook.Similarity.
+	Fingerprint bool
+}
+
+// LessonResult is the report for one lesson, plus the error that stopped it
+// early, such as a failed setup hook.
+type LessonResult struct {
+	verify.Report
+	Error string `json:"error,omitempty"`
+}
+
+// Result is one student's row of the gradebook. Error is set when the
+// repository could not be graded at all, in which case Lessons is empty.
+// Submitted describes the submission file of a bundled submission; Root is
+// only set for repositories graded in place.
+type Result struct {
+	Student   string           `json:"student"`
+	Path      string           `json:"path"`
+	Root      string           `json:"root,omitempty"`
+	Submitted *submit.Manifest `json:"submitted,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	ErrorKind string           `json:"errorKind,omitempty"`
+	Score     verify.Score     `json:"score"`
+	Lessons   []LessonResult   `json:"lessons"`
+	// Fingerprint is set when Options.Fingerprint was and the repository
+	// could be read.
+	Fingerprint similarity.Fingerprint `json:"-"`
+}
+
+// Gradebook is the outcome of grading every submission, in input order.
+type Gradebook struct {
+	Lessons  []*lessons.Lesson `json:"-"`
+	Students []Result          `json:"students"`
+}
+
+// Run grades every submission against every lesson. Repositories are graded
+// concurrently; a broken or missing repository only affects its own row.
+func Run(ctx context.Context, subs []Submission, opts Options) *Gradebook {
+	book := &Gradebook{Lessons: opts.Lessons, Students: make([]Result, len(subs))}
+	workers := max(opts.Workers, 1)
+
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, max(len(subs), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := gradeOne(ctx, subs[i], opts)
+				book.Students[i] = res
+				if opts.Progress != nil {
+					mu.Lock()
+					opts.Progress(res)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range subs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return book
+}
+
+func gradeOne(ctx context.Context, sub Submission, opts Options) Result {
+	res := Result{Student: sub.Student, Path: sub.Path, Lessons: []LessonResult{}}
+	for _, lesson := range opts.Lessons {
+		for _, check := range lesson.Checks {
+			if check.Required() {
+				res.Score.Max += check.Points()
+			}
+		}
+	}
+
+	var repo *gitutil.Repository
+	var err error
+	if sub.Bundle {
+		var cleanup func()
+		repo, res.Submitted, cleanup, err = extractSubmission(ctx, sub.Path)
+		defer cleanup()
+	} else {
+		repo, err = openSubmission(ctx, sub.Path)
+	}
+	if err != nil {
+		res.Error = describe(err, sub.Bundle)
+		res.ErrorKind = string(verify.Classify(err))
+		return res
+	}
+	if !sub.Bundle {
+		res.Root = repo.Root
+	}
+	if opts.Fingerprint {
+		// A repository that cannot be fingerprinted is still graded; it is
+		// only left out of the comparison.
+		res.Fingerprint, _ = similarity.Collect(ctx, repo)
+	}
+
+	for _, lesson := range opts.Lessons {
+		results, err := verify.RunWithOptions(ctx, lesson, repo, nil, opts.Verify)
+		lr := LessonResult{Report: verify.NewReport(lesson, results)}
+		if err != nil {
+			lr.Error = err.Error()
+		}
+		res.Score.Points += lr.Score.Points
+		res.Lessons = append(res.Lessons, lr)
+	}
+	if res.Score.Max > 0 {
+		res.Score.Percent = min(100, float64(res.Score.Points)*100/float64(res.Score.Max))
+	}
+	return res
+}
+
+// openSubmission opens the repository at path, refusing directories that are
+// merely inside some other repository, such as the folder holding the class.
+func openSubmission(ctx context.Context, path string) (*gitutil.Repository, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("grade: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("grade: %s is not a directory", path)
+	}
+	repo, err := gitutil.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	want, err1 := canonical(path)
+	got, err2 := canonical(repo.Root)
+	if err1 == nil && err2 == nil && want != got {
+		return nil, fmt.Errorf("grade: %s has no repository of its own (it is inside %s): %w", path, repo.Root, gitutil.ErrNotRepository)
+	}
+	return repo, nil
+}
+
+// extractSubmission restores a submission file into a temporary directory
+// of its own. cleanup removes the directory and must always be called.
+func extractSubmission(ctx context.Context, path string) (*gitutil.Repository, *submit.Manifest, func(), error) {
+	cleanup := func() {}
+	s, err := submit.Open(path)
+	if err != nil {
+		return nil, nil, cleanup, err
+	}
+	dir, err := os.MkdirTemp("", "tscgit-grade-")
+	if err != nil {
+		return nil, &s.Manifest, cleanup, fmt.Errorf("grade: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+	repo, err := s.Extract(ctx, dir)
+	return repo, &s.Manifest, cleanup, err
+}
+
+// describe reports git's own message for git failures, which is more use to
+// an instructor than the student-facing explanation.
+func describe(err error, bundle bool) string {
+	if errors.Is(err, fs.ErrNotExist) {
+		if bundle {
+			return "submission file not found"
+		}
+		return "directory not found"
+	}
+	var gitErr *gitutil.Error
+	if errors.As(err, &gitErr) && gitErr.Stderr != "" {
+		first, _, _ := strings.Cut(gitErr.Stderr, "\n")
+		return first
+	}
+	return err.Error()
+}
+
+func canonical(path string) (string, error) {
+	abs, err := filepath.Abs(filepath.FromSlash(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
@@ -0,0 +1,280 @@
+package grade
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/submit"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+func gitInit(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Skipf("git init: %v: %s", err, out)
+	}
+}
+
+var readmeLesson = &lessons.Lesson{
+	ID:    "readme",
+	Title: "Readme",
+	Checks: []lessons.Check{
+		{
+			ID:    "has-readme",
+			Title: "Has README.md",
+			Verify: func(_ context.Context, repo *gitutil.Repository) lessons.CheckResult {
+				ok, err := repo.FileExists("README.md")
+				return lessons.CheckResult{Passed: ok, Err: err}
+			},
+		},
+		{ID: "bonus", Title: "Bonus", Bonus: true, Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+			return lessons.CheckResult{Passed: true}
+		}},
+	},
+}
+
+func TestRunGradesEachSubmission(t *testing.T) {
+	class := t.TempDir()
+	gitInit(t, filepath.Join(class, "alice"))
+	if err := os.WriteFile(filepath.Join(class, "alice", "README.md"), []byte("hi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitInit(t, filepath.Join(class, "bob"))
+	// carol's folder sits inside the class repository but has no repository
+	// of its own, so it must not be graded as the class repository.
+	gitInit(t, class)
+	if err := os.MkdirAll(filepath.Join(class, "carol"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(class, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := Discover(class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subs = append(subs, Submission{Student: "dave", Path: filepath.Join(class, "missing")})
+	if len(subs) != 4 {
+		t.Fatalf("want alice, bob, carol and dave, got %+v", subs)
+	}
+
+	calls := 0
+	book := Run(context.Background(), subs, Options{
+		Lessons:  []*lessons.Lesson{readmeLesson},
+		Workers:  3,
+		Verify:   verify.Options{Workers: 1},
+		Progress: func(Result) { calls++ },
+	})
+	if calls != 4 || book.Errors() != 2 {
+		t.Fatalf("progress calls %d, errors %d", calls, book.Errors())
+	}
+	alice, bob, carol, dave := book.Students[0], book.Students[1], book.Students[2], book.Students[3]
+	if alice.Score.Points != 2 || alice.Score.Max != 1 || alice.Score.Percent != 100 {
+		t.Fatalf("alice score = %+v", alice.Score)
+	}
+	if bob.Error != "" || bob.Score.Points != 1 {
+		t.Fatalf("bob = %+v", bob)
+	}
+	if carol.ErrorKind != string(verify.KindNotRepo) || len(carol.Lessons) != 0 {
+		t.Fatalf("carol = %+v", carol)
+	}
+	if dave.Error != "directory not found" || dave.Score.Max != 1 {
+		t.Fatalf("dave = %+v", dave)
+	}
+
+	var buf bytes.Buffer
+	if err := book.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rows[0], ","); got != "student,path,points,max,percent,error,readme:points,readme:max,readme/has-readme,readme/bonus" {
+		t.Fatalf("header = %s", got)
+	}
+	if got := strings.Join(rows[2], ","); !strings.HasPrefix(got, "bob,") || !strings.HasSuffix(got, ",1,1,failed,passed") {
+		t.Fatalf("bob row = %s", got)
+	}
+	if got := rows[3]; got[5] == "" || got[8] != "" {
+		t.Fatalf("carol row should have an error and empty checks: %q", got)
+	}
+
+	buf.Reset()
+	if err := book.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Students) != 4 || read.Lessons[0].Title != "Readme" || len(read.Lessons[0].Checks) != 2 {
+		t.Fatalf("gradebook did not round-trip: %+v", read)
+	}
+	if got := read.Students[1].Lessons[0]; got.Lesson != "readme" || got.Checks[0].Status != verify.StatusFailed {
+		t.Fatalf("bob's report did not round-trip: %+v", got)
+	}
+}
+
+func TestRunGradesBundles(t *testing.T) {
+	ctx := context.Background()
+	src := filepath.Join(t.TempDir(), "alice")
+	gitInit(t, src)
+	if err := os.WriteFile(filepath.Join(src, "README.md"), []byte("hi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "README.md"}, {"-c", "user.name=Alice", "-c", "user.email=a@x", "commit", "--quiet", "-m", "readme"}} {
+		if out, err := exec.Command("git", append([]string{"-C", src}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	repo, err := gitutil.Open(ctx, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	class := t.TempDir()
+	f, err := os.Create(filepath.Join(class, "alice"+submit.Ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := submit.Create(ctx, repo, submit.Info{}, f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.WriteFile(filepath.Join(class, "eve"+submit.Ext), []byte("not a submission"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(class, "roster.csv"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := DiscoverBundles(class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].Student != "alice" || !subs[0].Bundle {
+		t.Fatalf("unexpected submissions %+v", subs)
+	}
+	book := Run(ctx, subs, Options{Lessons: []*lessons.Lesson{readmeLesson}, Verify: verify.Options{Workers: 1}})
+	alice, eve := book.Students[0], book.Students[1]
+	if alice.Error != "" || alice.Score.Points != 2 || alice.Root != "" || alice.Submitted == nil || alice.Submitted.Branch == "" {
+		t.Fatalf("alice = %+v", alice)
+	}
+	if !strings.Contains(eve.Error, "not a submission file") {
+		t.Fatalf("eve = %+v", eve)
+	}
+}
+
+func TestReadRoster(t *testing.T) {
+	roster := "Name, Email, Repo\nalice,a@x,alice\n,,\nbob,b@x,/abs/bob\n"
+	subs, err := ReadRoster(strings.NewReader(roster), "/class")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].Path != filepath.Join("/class", "alice") || subs[1].Path != "/abs/bob" {
+		t.Fatalf("unexpected submissions %+v", subs)
+	}
+
+	for _, bad := range []string{
+		"",
+		"student,email\nalice,a@x\n",
+		"student,repo\nalice,a\nalice,b\n",
+		"student,repo\nalice,\n",
+	} {
+		if _, err := ReadRoster(strings.NewReader(bad), "/class"); err == nil {
+			t.Errorf("roster %q should be rejected", bad)
+		}
+	}
+}
+
+func TestRunIgnoresHostileRepositoryConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hostile program is a shell script")
+	}
+	cases := []struct {
+		name string
+		key  string
+	}{
+		{name: "fsmonitor", key: "core.fsmonitor"},
+		{name: "clean filter", key: "filter.x.clean"},
+		{name: "smudge filter", key: "filter.x.smudge"},
+		{name: "filter process", key: "filter.x.process"},
+		{name: "external diff", key: "diff.external"},
+		{name: "diff driver", key: "diff.x.command"},
+		{name: "textconv", key: "diff.x.textconv"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "mallory")
+			gitInit(t, dir)
+			git := func(args ...string) {
+				t.Helper()
+				args = append([]string{"-C", dir, "-c", "user.name=M", "-c", "user.email=m@example.com"}, args...)
+				if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %v: %s", args, err, out)
+				}
+			}
+			write := func(name, data string) {
+				t.Helper()
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("README.md", "one\n")
+			git("add", "README.md")
+			git("commit", "--quiet", "-m", "one")
+			write("README.md", "two\n")
+			git("commit", "--quiet", "-am", "two")
+			write("README.md", "three\n")
+			git("stash", "--quiet")
+
+			marker := filepath.Join(t.TempDir(), "pwned")
+			program := filepath.Join(t.TempDir(), "hostile.sh")
+			if err := os.WriteFile(program, []byte("#!/bin/sh\ntouch '"+marker+"'\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			write(".gitattributes", "* filter=x diff=x\n")
+			git("config", tc.key, program)
+			write("README.md", "owt\n")
+
+			lesson := &lessons.Lesson{ID: "inspect", Title: "Inspect", Checks: []lessons.Check{{
+				ID:    "read",
+				Title: "Read the repository",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) lessons.CheckResult {
+					if _, err := repo.Status(ctx); err != nil {
+						return lessons.CheckResult{Err: err}
+					}
+					if _, err := repo.PatchIDs(ctx, "HEAD~1", "HEAD"); err != nil {
+						return lessons.CheckResult{Err: err}
+					}
+					if _, err := repo.DiffPatchID(ctx, "HEAD~1", "HEAD"); err != nil {
+						return lessons.CheckResult{Err: err}
+					}
+					_, err := repo.Stashes(ctx)
+					return lessons.CheckResult{Passed: err == nil, Err: err}
+				},
+			}}}
+			book := Run(context.Background(), []Submission{{Student: "mallory", Path: dir}}, Options{Lessons: []*lessons.Lesson{lesson}})
+			if res := book.Students[0]; res.Error != "" || res.Score.Points != 1 {
+				t.Fatalf("expected the repository to be graded, got %+v", res)
+			}
+			if _, err := os.Stat(marker); err == nil {
+				t.Fatalf("grading ran the repository's %s program", tc.key)
+			}
+		})
+	}
+}
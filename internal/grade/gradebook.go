@@ -0,0 +1,149 @@
+package grade
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/similarity"
+)
+
+// Errors reports how many submissions could not be graded at all.
+func (b *Gradebook) Errors() int {
+	n := 0
+	for _, s := range b.Students {
+		if s.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Similarity compares the fingerprints collected while grading; students
+// without one are left out.
+func (b *Gradebook) Similarity() similarity.Report {
+	var repos []similarity.Repo
+	for _, s := range b.Students {
+		if s.Fingerprint != nil {
+			repos = append(repos, similarity.Repo{Name: s.Student, Fingerprint: s.Fingerprint})
+		}
+	}
+	return similarity.Compare(repos)
+}
+
+// WriteCSV writes one row per student: the total score, then the points of
+// each lesson and the status of each of its checks. Cells for checks that
+// never ran are empty, and errors from every lesson are joined into the
+// error column.
+func (b *Gradebook) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"student", "path", "points", "max", "percent", "error"}
+	for _, lesson := range b.Lessons {
+		header = append(header, lesson.ID+":points", lesson.ID+":max")
+		for _, check := range lesson.Checks {
+			header = append(header, lesson.ID+"/"+check.ID)
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("grade: write csv: %w", err)
+	}
+
+	for _, s := range b.Students {
+		errs := []string{}
+		if s.Error != "" {
+			errs = append(errs, s.Error)
+		}
+		row := []string{
+			s.Student, s.Path,
+			strconv.Itoa(s.Score.Points), strconv.Itoa(s.Score.Max),
+			strconv.FormatFloat(s.Score.Percent, 'f', 1, 64),
+			"",
+		}
+		for i, lesson := range b.Lessons {
+			var lr *LessonResult
+			if i < len(s.Lessons) {
+				lr = &s.Lessons[i]
+			}
+			statuses := make(map[string]string)
+			points, max := "", ""
+			if lr != nil {
+				points, max = strconv.Itoa(lr.Score.Points), strconv.Itoa(lr.Score.Max)
+				for _, c := range lr.Checks {
+					statuses[c.ID] = c.Status
+				}
+				if lr.Error != "" {
+					errs = append(errs, lesson.ID+": "+lr.Error)
+				}
+			}
+			row = append(row, points, max)
+			for _, check := range lesson.Checks {
+				row = append(row, statuses[check.ID])
+			}
+		}
+		row[5] = strings.Join(errs, "; ")
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("grade: write csv: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("grade: write csv: %w", err)
+	}
+	return nil
+}
+
+// lessonInfo names a graded lesson in the JSON gradebook.
+type lessonInfo struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Checks []string `json:"checks"`
+}
+
+// WriteJSON writes the gradebook with the full report of every lesson.
+func (b *Gradebook) WriteJSON(w io.Writer) error {
+	doc := struct {
+		Lessons  []lessonInfo `json:"lessons"`
+		Students []Result     `json:"students"`
+	}{Lessons: []lessonInfo{}, Students: b.Students}
+	for _, lesson := range b.Lessons {
+		info := lessonInfo{ID: lesson.ID, Title: lesson.Title, Checks: []string{}}
+		for _, check := range lesson.Checks {
+			info.Checks = append(info.Checks, check.ID)
+		}
+		doc.Lessons = append(doc.Lessons, info)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("grade: write json: %w", err)
+	}
+	return nil
+}
+
+// ReadJSON reads a gradebook written by WriteJSON. Its lessons carry only
+// the IDs and titles recorded in the file, not runnable checks.
+func ReadJSON(r io.Reader) (*Gradebook, error) {
+	var doc struct {
+		Lessons  []lessonInfo `json:"lessons"`
+		Students []Result     `json:"students"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("grade: read json: %w", err)
+	}
+	if doc.Students == nil {
+		return nil, fmt.Errorf("grade: read json: not a gradebook")
+	}
+	book := &Gradebook{Students: doc.Students}
+	for _, info := range doc.Lessons {
+		lesson := &lessons.Lesson{ID: info.ID, Title: info.Title}
+		for _, id := range info.Checks {
+			lesson.Checks = append(lesson.Checks, lessons.Check{ID: id})
+		}
+		book.Lessons = append(book.Lessons, lesson)
+	}
+	return book, nil
+}
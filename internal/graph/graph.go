@@ -0,0 +1,292 @@
+// Package graph lays out commit history as a text graph with lanes, branch
+// labels, tags and HEAD, similar to git log --graph.
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+// Node is a commit in the graph. Nodes are expected children first, as
+// listed by git log --topo-order.
+type Node struct {
+	ID      string
+	Parents []string
+	Subject string
+	// Labels decorate the node, for example "HEAD -> main" or "tag: v1.0".
+	Labels []string
+	Head   bool
+}
+
+// Style customises how parts of a rendered line look. Nil functions leave
+// the text unchanged.
+type Style struct {
+	Node  func(string) string
+	Head  func(string) string
+	Edge  func(string) string
+	Hash  func(string) string
+	Label func(string) string
+}
+
+// Load reads up to limit commits from repo and decorates them with branch,
+// remote, tag and HEAD labels.
+func Load(ctx context.Context, repo *gitutil.Repository, limit int) ([]Node, error) {
+	head, err := repo.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repo.Log(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.Refs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string][]string{}
+	if head.Hash != "" {
+		if head.Branch != "" {
+			labels[head.Hash] = append(labels[head.Hash], "HEAD -> "+head.Branch)
+		} else {
+			labels[head.Hash] = append(labels[head.Hash], "HEAD")
+		}
+	}
+	for _, ref := range refs {
+		switch {
+		case ref.Kind == gitutil.RefBranch && ref.Name == head.Branch && ref.Hash == head.Hash:
+			continue
+		case ref.Kind == gitutil.RefTag:
+			labels[ref.Hash] = append(labels[ref.Hash], "tag: "+ref.Name)
+		default:
+			labels[ref.Hash] = append(labels[ref.Hash], ref.Name)
+		}
+	}
+
+	nodes := make([]Node, 0, len(commits))
+	for _, c := range commits {
+		nodes = append(nodes, Node{
+			ID:      c.Hash,
+			Parents: c.Parents,
+			Subject: c.Subject,
+			Labels:  labels[c.Hash],
+			Head:    c.Hash == head.Hash,
+		})
+	}
+	return nodes, nil
+}
+
+const (
+	up = 1 << iota
+	down
+	left
+	right
+)
+
+// Render draws nodes one per line, with connector lines wherever branches
+// fork or merge. Each lane is a column; a lane is freed when its branch
+// ends and reused by the next branch that needs one.
+func Render(nodes []Node, style Style) []string {
+	var (
+		lanes []string
+		lines []string
+	)
+	for _, n := range nodes {
+		col := -1
+		var joins []int
+		for i, id := range lanes {
+			if id != n.ID {
+				continue
+			}
+			if col < 0 {
+				col = i
+			} else {
+				joins = append(joins, i)
+			}
+		}
+		if col < 0 {
+			col = freeLane(&lanes, 0)
+			lanes[col] = n.ID
+		}
+
+		// Other children converge on this node above it.
+		if len(joins) > 0 {
+			cells := activeCells(lanes, nil)
+			for _, j := range joins {
+				connect(cells, j, col, up)
+				lanes[j] = ""
+			}
+			lines = append(lines, drawCells(cells, style))
+			lanes = trim(lanes)
+		}
+
+		lines = append(lines, drawNode(n, col, lanes, style))
+
+		if len(n.Parents) == 0 {
+			lanes[col] = ""
+		} else {
+			lanes[col] = n.Parents[0]
+			type fork struct {
+				lane  int
+				fresh bool
+			}
+			var forks []fork
+			for _, p := range n.Parents[1:] {
+				k := indexOf(lanes, p)
+				fresh := k < 0
+				if fresh {
+					k = freeLane(&lanes, col+1)
+					lanes[k] = p
+				}
+				forks = append(forks, fork{lane: k, fresh: fresh})
+			}
+			if len(forks) > 0 {
+				fresh := map[int]bool{}
+				for _, f := range forks {
+					if f.fresh {
+						fresh[f.lane] = true
+					}
+				}
+				cells := activeCells(lanes, fresh)
+				for _, f := range forks {
+					if f.fresh {
+						connect(cells, f.lane, col, down)
+					} else {
+						connect(cells, f.lane, col, up|down)
+					}
+				}
+				lines = append(lines, drawCells(cells, style))
+			}
+		}
+		lanes = trim(lanes)
+	}
+	return lines
+}
+
+func trim(lanes []string) []string {
+	for len(lanes) > 0 && lanes[len(lanes)-1] == "" {
+		lanes = lanes[:len(lanes)-1]
+	}
+	return lanes
+}
+
+// ShortID abbreviates commit hashes; short symbolic IDs are kept as is.
+func ShortID(id string) string {
+	if len(id) > 7 {
+		return id[:7]
+	}
+	return id
+}
+
+func freeLane(lanes *[]string, from int) int {
+	for i := from; i < len(*lanes); i++ {
+		if (*lanes)[i] == "" {
+			return i
+		}
+	}
+	*lanes = append(*lanes, "")
+	return len(*lanes) - 1
+}
+
+func indexOf(lanes []string, id string) int {
+	for i, l := range lanes {
+		if l == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func activeCells(lanes []string, skip map[int]bool) []int {
+	cells := make([]int, len(lanes))
+	for i, id := range lanes {
+		if id != "" && !skip[i] {
+			cells[i] = up | down
+		}
+	}
+	return cells
+}
+
+// connect draws a horizontal edge from lane from to lane to. The from cell
+// keeps only the vertical directions in vert.
+func connect(cells []int, from, to, vert int) {
+	step, fromSide, toSide := 1, right, left
+	if to < from {
+		step, fromSide, toSide = -1, left, right
+	}
+	cells[from] = vert | fromSide
+	for i := from + step; i != to; i += step {
+		cells[i] |= left | right
+	}
+	cells[to] |= toSide
+}
+
+var boxes = map[int]string{
+	0:                        " ",
+	up:                       "│",
+	down:                     "│",
+	up | down:                "│",
+	left | right:             "─",
+	left:                     "─",
+	right:                    "─",
+	up | down | left | right: "┼",
+	up | down | right:        "├",
+	up | down | left:         "┤",
+	down | right:             "╭",
+	down | left:              "╮",
+	up | right:               "╰",
+	up | left:                "╯",
+	up | left | right:        "┴",
+	down | left | right:      "┬",
+}
+
+func drawCells(cells []int, style Style) string {
+	var b strings.Builder
+	for i, c := range cells {
+		b.WriteString(boxes[c])
+		if i < len(cells)-1 {
+			if c&right != 0 {
+				b.WriteString("─")
+			} else {
+				b.WriteString(" ")
+			}
+		}
+	}
+	return apply(style.Edge, strings.TrimRight(b.String(), " "))
+}
+
+func drawNode(n Node, col int, lanes []string, style Style) string {
+	var b strings.Builder
+	for i, id := range lanes {
+		switch {
+		case i == col && n.Head:
+			b.WriteString(apply(style.Head, "◉"))
+		case i == col:
+			b.WriteString(apply(style.Node, "●"))
+		case id != "":
+			b.WriteString(apply(style.Edge, "│"))
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString(apply(style.Hash, ShortID(n.ID)))
+	if len(n.Labels) > 0 {
+		b.WriteString(" ")
+		b.WriteString(apply(style.Label, "("+strings.Join(n.Labels, ", ")+")"))
+	}
+	if n.Subject != "" {
+		b.WriteString(" ")
+		b.WriteString(n.Subject)
+	}
+	return b.String()
+}
+
+func apply(fn func(string) string, s string) string {
+	if fn == nil {
+		return s
+	}
+	return fn(s)
+}
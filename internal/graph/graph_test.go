@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderForkAndMerge(t *testing.T) {
+	nodes := []Node{
+		{ID: "M", Parents: []string{"C", "F2"}, Subject: "Merge feat", Labels: []string{"HEAD -> main", "tag: v1"}, Head: true},
+		{ID: "F2", Parents: []string{"F1"}, Labels: []string{"feat"}},
+		{ID: "F1", Parents: []string{"B"}},
+		{ID: "O1", Parents: []string{"C"}, Labels: []string{"other"}},
+		{ID: "C", Parents: []string{"B"}},
+		{ID: "B", Parents: []string{"A"}},
+		{ID: "A"},
+	}
+	want := []string{
+		"◉ M (HEAD -> main, tag: v1) Merge feat",
+		"├─╮",
+		"│ ● F2 (feat)",
+		"│ ● F1",
+		"│ │ ● O1 (other)",
+		"├─┼─╯",
+		"● │ C",
+		"├─╯",
+		"● B",
+		"● A",
+	}
+	got := Render(nodes, Style{})
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected graph:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderDisjointRoots(t *testing.T) {
+	nodes := []Node{
+		{ID: "X", Parents: []string{"A"}},
+		{ID: "Y"},
+		{ID: "A"},
+	}
+	got := Render(nodes, Style{})
+	want := []string{"● X", "│ ● Y", "● A"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected graph:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -7,11 +7,21 @@ import (
 	"time"
 
 	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/graph"
 )
 
 func init() {
 	Must(Register(lessonInitBasics()))
 	Must(Register(lessonBranchBasics()))
+	Must(Register(lessonObjectBasics()))
+	Must(Register(lessonStagingBasics()))
+	Must(Register(lessonRecoverCommit()))
+	Must(Register(lessonTagRelease()))
+	Must(Register(lessonRebaseSquash()))
+	Must(Register(lessonRebaseReword()))
+	Must(Register(lessonRebaseReorder()))
+	Must(Register(lessonRebaseDrop()))
+	Must(Register(lessonStashHotfix()))
 }
 
 func lessonInitBasics() *Lesson {
@@ -54,6 +64,7 @@ func lessonInitBasics() *Lesson {
 				ID:          "commit-message",
 				Title:       "Write a descriptive commit message",
 				Description: "Make sure your latest commit message is at least 5 characters long.",
+				DependsOn:   []string{"first-commit"},
 				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
 					msg, err := repo.LastCommitMessage(ctx)
 					if err != nil {
@@ -74,7 +85,11 @@ func lessonBranchBasics() *Lesson {
 	return &Lesson{
 		ID:          "branch-basics",
 		Title:       "Practice branching",
-		Description: "Create a feature branch, commit work on it, and keep main clean.",
+		Description: "Create a feature branch, commit work on it, and keep the default branch clean.",
+		Target: []graph.Node{
+			{ID: "B", Parents: []string{"A"}, Subject: "Feature work [branch]", Labels: []string{"HEAD -> feature/lesson-branch"}, Head: true},
+			{ID: "A", Subject: "Earlier work", Labels: []string{DefaultBranch}},
+		},
 		Checks: []Check{
 			{
 				ID:          "branch-exists",
@@ -95,6 +110,7 @@ func lessonBranchBasics() *Lesson {
 				ID:          "branch-current",
 				Title:       "Check out the feature branch",
 				Description: "Switch to feature/lesson-branch before committing work.",
+				DependsOn:   []string{"branch-exists"},
 				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
 					branch, err := repo.CurrentBranch(ctx)
 					if err != nil {
@@ -109,16 +125,17 @@ func lessonBranchBasics() *Lesson {
 			{
 				ID:          "branch-commit",
 				Title:       "Commit work on the feature branch",
-				Description: "Create at least one commit that is ahead of main.",
+				Description: "Create at least one commit that is ahead of the default branch.",
+				DependsOn:   []string{"branch-exists"},
 				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
-					ahead, err := repo.CommitsAhead(ctx, "main", "feature/lesson-branch")
+					ahead, err := repo.CommitsAhead(ctx, DefaultBranch, "feature/lesson-branch")
 					if err != nil {
 						return CheckResult{Err: err}
 					}
 					if ahead == 0 {
-						return CheckResult{Passed: false, Message: "No commits found on feature/lesson-branch that aren't on main."}
+						return CheckResult{Passed: false, Message: fmt.Sprintf("No commits found on feature/lesson-branch that aren't on %s.", DefaultBranch)}
 					}
-					return CheckResult{Passed: true, Message: fmt.Sprintf("feature/lesson-branch is ahead of main by %d commit(s).", ahead)}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("feature/lesson-branch is ahead of %s by %d commit(s).", DefaultBranch, ahead)}
 				},
 			},
 			{
@@ -140,7 +157,33 @@ func lessonBranchBasics() *Lesson {
 	}
 }
 
+// CheckTimeout is the default time budget for a single check's git calls. It
+// is set from the timeouts.check configuration key.
+var CheckTimeout = 5 * time.Second
+
+// DefaultBranch is the trunk branch lessons compare feature work against. It
+// is set from the branches.default configuration key.
+var DefaultBranch = "main"
+
+// SetDefaultBranch changes DefaultBranch and relabels the target graphs of
+// registered lessons to match.
+func SetDefaultBranch(name string) {
+	if name == "" || name == DefaultBranch {
+		return
+	}
+	for _, lesson := range catalog {
+		for i := range lesson.Target {
+			for j, label := range lesson.Target[i].Labels {
+				if label == DefaultBranch {
+					lesson.Target[i].Labels[j] = name
+				}
+			}
+		}
+	}
+	DefaultBranch = name
+}
+
 // TimeoutContext wraps context.Background with a reasonable timeout for git calls used during verification.
 func TimeoutContext() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), 5*time.Second)
+	return context.WithTimeout(context.Background(), CheckTimeout)
 }
@@ -7,6 +7,7 @@ import (
 	"sort"
 
 	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/graph"
 )
 
 // CheckFunc executes a single verification step against the given repository.
@@ -25,6 +26,40 @@ type Check struct {
 	Title       string
 	Description string
 	Verify      CheckFunc
+	// DependsOn lists the IDs of checks in the same lesson that must pass
+	// before this one runs. Checks without dependencies may run concurrently.
+	DependsOn []string
+	// Weight is the number of points the check is worth. Zero counts as one.
+	Weight int
+	// Optional checks are informational: they earn no points and never fail
+	// the lesson.
+	Optional bool
+	// Bonus checks earn points when they pass but are left out of the
+	// maximum score, so they never fail the lesson either.
+	Bonus bool
+}
+
+// Points returns the check's effective weight.
+func (c Check) Points() int {
+	if c.Weight <= 0 {
+		return 1
+	}
+	return c.Weight
+}
+
+// Required reports whether the lesson fails when this check does.
+func (c Check) Required() bool {
+	return !c.Optional && !c.Bonus
+}
+
+// HookFunc prepares or restores repository state around a lesson's checks.
+type HookFunc func(ctx context.Context, repo *gitutil.Repository) error
+
+// Hook is an ungraded setup or teardown step.
+type Hook struct {
+	ID    string
+	Title string
+	Run   HookFunc
 }
 
 // Lesson bundles together a series of Checks.
@@ -33,13 +68,33 @@ type Lesson struct {
 	Title       string
 	Description string
 	Checks      []Check
+	// Setup hooks run before the checks. Teardown hooks always run
+	// afterwards, even when setup fails or verification is cancelled.
+	Setup    []Hook
+	Teardown []Hook
+	// Target optionally sketches the history a finished lesson should have,
+	// children first. The verify UI shows it beside the student's graph.
+	Target []graph.Node
+	// Panel selects the side pane the verify UI opens with. The zero value
+	// shows the history graph.
+	Panel Panel
 }
 
+// Panel names a side pane of the verify UI.
+type Panel string
+
+const (
+	PanelGraph Panel = "graph"
+	PanelTrees Panel = "trees"
+)
+
 var (
 	catalog       = map[string]*Lesson{}
 	lessonOrder   []string
 	errLessonDNE  = errors.New("lesson not found")
 	errCatalogSet = errors.New("lesson already registered")
+
+	errInvalidChecks = errors.New("invalid lesson checks")
 )
 
 // Register adds a new lesson to the catalog. It should typically be invoked in
@@ -51,6 +106,9 @@ func Register(lesson *Lesson) error {
 	if lesson.ID == "" {
 		return errors.New("lesson ID is required")
 	}
+	if err := lesson.Validate(); err != nil {
+		return err
+	}
 	if _, exists := catalog[lesson.ID]; exists {
 		return fmt.Errorf("%w: %s", errCatalogSet, lesson.ID)
 	}
@@ -60,6 +118,55 @@ func Register(lesson *Lesson) error {
 	return nil
 }
 
+// Validate reports duplicate check IDs and DependsOn references that are
+// unknown or form a cycle.
+func (l *Lesson) Validate() error {
+	index := make(map[string]int, len(l.Checks))
+	for i, check := range l.Checks {
+		if _, dup := index[check.ID]; dup {
+			return fmt.Errorf("%w: lesson %s: duplicate check %s", errInvalidChecks, l.ID, check.ID)
+		}
+		index[check.ID] = i
+	}
+	for _, check := range l.Checks {
+		for _, dep := range check.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("%w: lesson %s: check %s depends on unknown check %s", errInvalidChecks, l.ID, check.ID, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(l.Checks))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("%w: lesson %s: dependency cycle through check %s", errInvalidChecks, l.ID, l.Checks[i].ID)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range l.Checks[i].DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range l.Checks {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // List returns the catalog of lessons in a deterministic order.
 func List() []*Lesson {
 	out := make([]*Lesson, 0, len(lessonOrder))
@@ -1,6 +1,17 @@
 package lessons
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
 
 func TestCatalogRegistration(t *testing.T) {
 	lessons := List()
@@ -22,3 +33,398 @@ func TestCatalogRegistration(t *testing.T) {
 		t.Fatalf("expected duplicate registration to fail")
 	}
 }
+
+func TestValidateRejectsBadDependencies(t *testing.T) {
+	lesson := &Lesson{
+		ID: "deps",
+		Checks: []Check{
+			{ID: "a", DependsOn: []string{"b"}},
+			{ID: "b", DependsOn: []string{"a"}},
+		},
+	}
+	if err := lesson.Validate(); err == nil {
+		t.Fatalf("expected dependency cycle to be rejected")
+	}
+
+	lesson.Checks = []Check{{ID: "a", DependsOn: []string{"missing"}}}
+	if err := lesson.Validate(); err == nil {
+		t.Fatalf("expected unknown dependency to be rejected")
+	}
+
+	lesson.Checks = []Check{{ID: "a"}, {ID: "b", DependsOn: []string{"a"}}}
+	if err := lesson.Validate(); err != nil {
+		t.Fatalf("expected valid dependencies, got %v", err)
+	}
+}
+
+func TestCheckHashAnswer(t *testing.T) {
+	repo := &gitutil.Repository{Root: t.TempDir()}
+	want := "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"
+
+	if res := checkHashAnswer(repo, "blobhash.txt", want, "the blob"); res.Passed || res.Err != nil {
+		t.Fatalf("expected missing answer to fail without error, got %+v", res)
+	}
+
+	cases := map[string]struct {
+		ok      bool
+		message string
+	}{
+		want + "\n":             {true, "Correct"},
+		"3B18E51\n":             {true, "Correct"},
+		"3b18e5":                {false, "at least the first 7"},
+		"deadbeefcafe":          {false, "doesn't match"},
+		strings.Repeat("0", 40): {false, "doesn't match"},
+	}
+	for answer, tc := range cases {
+		if err := os.WriteFile(filepath.Join(repo.Root, "blobhash.txt"), []byte(answer), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		res := checkHashAnswer(repo, "blobhash.txt", want, "the blob")
+		if res.Passed != tc.ok || !strings.Contains(res.Message, tc.message) {
+			t.Fatalf("answer %q: expected passed=%v with %q, got %+v", answer, tc.ok, tc.message, res)
+		}
+	}
+}
+
+// sandbox is a throwaway repository on the default branch whose commits get
+// distinct, fixed dates.
+type sandbox struct {
+	t      *testing.T
+	dir    string
+	repo   *gitutil.Repository
+	minute int
+	// env is added to the environment of every git command.
+	env []string
+}
+
+func newSandbox(t *testing.T) *sandbox {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	s := &sandbox{t: t, dir: t.TempDir()}
+	s.git("init", "--quiet")
+	s.git("symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch)
+	repo, err := gitutil.Open(context.Background(), s.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.repo = repo
+	return s
+}
+
+func (s *sandbox) git(args ...string) string {
+	s.t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=S", "-c", "user.email=s@x"}, args...)...)
+	cmd.Dir = s.dir
+	date := fmt.Sprintf("2026-03-01T10:%02d:00Z", s.minute)
+	cmd.Env = append(append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date), s.env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		s.t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (s *sandbox) commit(name, content, message string) string {
+	s.t.Helper()
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644); err != nil {
+		s.t.Fatal(err)
+	}
+	s.git("add", name)
+	s.git("commit", "--quiet", "-m", message)
+	s.minute++
+	return s.git("rev-parse", "HEAD")
+}
+
+func (s *sandbox) verify(lessonID, checkID string) CheckResult {
+	s.t.Helper()
+	lesson, err := Get(lessonID)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	for _, c := range lesson.Checks {
+		if c.ID == checkID {
+			res := c.Verify(context.Background(), s.repo)
+			if res.Err != nil {
+				s.t.Fatalf("%s/%s: %v", lessonID, checkID, res.Err)
+			}
+			return res
+		}
+	}
+	s.t.Fatalf("%s has no check %s", lessonID, checkID)
+	return CheckResult{}
+}
+
+func TestRecoverCommitLesson(t *testing.T) {
+	s := newSandbox(t)
+	verify := func(id string) CheckResult {
+		t.Helper()
+		return s.verify("recover-commit", id)
+	}
+
+	s.commit("notes.md", "first\n", "Start notes")
+	if res := verify("commit-dropped"); res.Passed {
+		t.Fatalf("nothing was dropped yet: %+v", res)
+	}
+	lost := s.commit("notes.md", "first\nsecond\n", "Add second note")
+	s.git("reset", "--quiet", "--hard", "HEAD~1")
+	if res := verify("commit-dropped"); !res.Passed {
+		t.Fatalf("expected the reset to drop a commit: %+v", res)
+	}
+
+	// Typing the change in again makes a different commit.
+	s.commit("notes.md", "first\nsecond\n", "Add second note")
+	if res := verify("original-recovered"); res.Passed || !strings.Contains(res.Message, "typing it again") {
+		t.Fatalf("a retyped commit should not count: %+v", res)
+	}
+	s.git("reset", "--quiet", "--hard", "HEAD~1")
+
+	// Moving an existing branch onto the commit recovers it but does not
+	// re-create a branch there.
+	s.git("branch", "moved")
+	s.git("branch", "--force", "moved", lost)
+	if res := verify("original-recovered"); !res.Passed {
+		t.Fatalf("moved points at the original: %+v", res)
+	}
+	if res := verify("branch-recreated"); res.Passed {
+		t.Fatalf("moved was created elsewhere: %+v", res)
+	}
+	s.git("branch", "--delete", "--force", "moved")
+
+	s.git("branch", "recovered", lost)
+	if res := verify("original-recovered"); !res.Passed || !strings.Contains(res.Message, "recovered") {
+		t.Fatalf("expected recovered to hold the original: %+v", res)
+	}
+	if res := verify("branch-recreated"); !res.Passed {
+		t.Fatalf("recovered was created at the commit: %+v", res)
+	}
+}
+
+func TestTagReleaseLesson(t *testing.T) {
+	s := newSandbox(t)
+	verify := func(id string) CheckResult {
+		t.Helper()
+		return s.verify("tag-release", id)
+	}
+
+	s.commit("notes.md", "draft\n", "Start notes")
+	s.git("tag", "draft")
+	s.git("tag", "v0.9.0")
+	if res := verify("semver-tag"); !res.Passed {
+		t.Fatalf("v0.9.0 tags the tip: %+v", res)
+	}
+	if res := verify("annotated-tag"); res.Passed || !strings.Contains(res.Message, "lightweight") {
+		t.Fatalf("v0.9.0 is lightweight: %+v", res)
+	}
+
+	tip := s.commit("notes.md", "done\n", "Finish notes")
+	if res := verify("semver-tag"); res.Passed || !strings.Contains(res.Message, "v0.9.0 is on") {
+		t.Fatalf("v0.9.0 is behind the tip: %+v", res)
+	}
+	s.git("tag", "v1.0.0-rc.1")
+	s.git("tag", "-a", "v1.0.0", "-m", "First release\n\nEverything in one place.", "HEAD~1")
+	if res := verify("semver-tag"); res.Passed || !strings.Contains(res.Message, "v1.0.0 is on") {
+		t.Fatalf("v1.0.0 outranks its release candidate but is on the wrong commit: %+v", res)
+	}
+	s.git("tag", "-a", "--force", "v1.0.0", "-m", "First release")
+	if res := verify("semver-tag"); !res.Passed {
+		t.Fatalf("v1.0.0 tags the tip: %+v", res)
+	}
+	if res := verify("annotated-tag"); !res.Passed || !strings.Contains(res.Message, `"First release"`) {
+		t.Fatalf("v1.0.0 is annotated: %+v", res)
+	}
+
+	tag, ok, err := s.repo.Tag(context.Background(), "v1.0.0")
+	if err != nil || !ok {
+		t.Fatalf("Tag(v1.0.0) = %v, %v", ok, err)
+	}
+	if !tag.Annotated || tag.Commit != tip || tag.Hash == tip || tag.Tagger != "S <s@x>" || tag.Time.IsZero() {
+		t.Fatalf("unexpected tag %+v", tag)
+	}
+
+	if res := verify("tag-pushed"); res.Passed {
+		t.Fatalf("there is no remote yet: %+v", res)
+	}
+	s.git("remote", "add", "web", "https://example.invalid/notes.git")
+	remote := filepath.Join(t.TempDir(), "origin.git")
+	s.git("init", "--quiet", "--bare", remote)
+	s.git("remote", "add", "origin", remote)
+	if res := verify("tag-pushed"); res.Passed || !strings.Contains(res.Message, "git push origin v1.0.0") {
+		t.Fatalf("v1.0.0 is not pushed yet: %+v", res)
+	}
+	s.git("push", "--quiet", "origin", "v1.0.0")
+	if res := verify("tag-pushed"); !res.Passed {
+		t.Fatalf("v1.0.0 was pushed: %+v", res)
+	}
+
+	// The remote's upload-pack setting is the student's to choose, so it
+	// must not be run when the check lists the remote's tags.
+	if runtime.GOOS != "windows" {
+		marker := filepath.Join(t.TempDir(), "pwned")
+		hook := filepath.Join(t.TempDir(), "upload-pack.sh")
+		if err := os.WriteFile(hook, []byte("#!/bin/sh\ntouch '"+marker+"'\nexec git-upload-pack \"$@\"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		s.git("config", "remote.origin.uploadpack", hook)
+		if res := verify("tag-pushed"); !res.Passed {
+			t.Fatalf("v1.0.0 is still pushed: %+v", res)
+		}
+		if _, err := os.Stat(marker); err == nil {
+			t.Fatal("tag-pushed ran remote.origin.uploadpack")
+		}
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	ordered := []string{"0.9.0", "v1.0.0-alpha", "v1.0.0-rc.1", "1.0.0", "v1.2.0", "v1.10.0", "v2.0.0+build.5"}
+	for i := 1; i < len(ordered); i++ {
+		if compareSemver(ordered[i-1], ordered[i]) >= 0 || compareSemver(ordered[i], ordered[i-1]) <= 0 {
+			t.Fatalf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+	if compareSemver("v1.0.0", "1.0.0") != 0 {
+		t.Fatalf("the v prefix should not matter")
+	}
+}
+
+func TestRebaseLessons(t *testing.T) {
+	// feature makes three commits on top of main, each adding its own file.
+	setup := func(t *testing.T) (*sandbox, []string) {
+		s := newSandbox(t)
+		s.commit("README.md", "# Notes\n", "Start")
+		s.git("switch", "--quiet", "--create", "feature")
+		var commits []string
+		for _, name := range []string{"a", "b", "c"} {
+			commits = append(commits, s.commit(name+".txt", name+"\n", "Add "+name))
+		}
+		return s, commits
+	}
+	// rebase runs git rebase -i main with todo as the edited todo list.
+	rebase := func(s *sandbox, todo, message string) {
+		s.t.Helper()
+		dir := s.t.TempDir()
+		todoFile, messageFile := filepath.Join(dir, "todo"), filepath.Join(dir, "message")
+		if err := os.WriteFile(todoFile, []byte(todo), 0o644); err != nil {
+			s.t.Fatal(err)
+		}
+		if err := os.WriteFile(messageFile, []byte(message+"\n"), 0o644); err != nil {
+			s.t.Fatal(err)
+		}
+		s.env = []string{"GIT_SEQUENCE_EDITOR=cp " + todoFile, "GIT_EDITOR=true"}
+		if message != "" {
+			s.env[1] = "GIT_EDITOR=cp " + messageFile
+		}
+		s.git("rebase", "--quiet", "--interactive", "main")
+		s.env = nil
+	}
+	expect := func(t *testing.T, s *sandbox, lesson string, want map[string]bool) {
+		t.Helper()
+		for check, passed := range want {
+			if res := s.verify(lesson, check); res.Passed != passed {
+				t.Fatalf("%s/%s: expected passed=%v, got %+v", lesson, check, passed, res)
+			}
+		}
+	}
+
+	t.Run("squash", func(t *testing.T) {
+		s, c := setup(t)
+		expect(t, s, "rebase-squash", map[string]bool{"rebased": false, "squashed": false})
+		rebase(s, fmt.Sprintf("pick %s\nsquash %s\nfixup %s\n", c[0], c[1], c[2]), "")
+		expect(t, s, "rebase-squash", map[string]bool{"rebased": true, "squashed": true, "change-kept": true})
+		expect(t, s, "rebase-drop", map[string]bool{"dropped": false})
+		if res := s.verify("rebase-drop", "dropped"); !strings.Contains(res.Message, "more than drop") {
+			t.Fatalf("a squash is not a drop: %+v", res)
+		}
+		if res := s.verify("rebase-squash", "squashed"); !strings.Contains(res.Message, "3 commits") {
+			t.Fatalf("expected all three commits squashed: %+v", res)
+		}
+	})
+	t.Run("reword", func(t *testing.T) {
+		s, c := setup(t)
+		rebase(s, fmt.Sprintf("pick %s\nreword %s\npick %s\n", c[0], c[1], c[2]), "Add b, the second file")
+		expect(t, s, "rebase-reword", map[string]bool{"reworded": true, "changes-kept": true})
+		expect(t, s, "rebase-squash", map[string]bool{"squashed": false})
+		expect(t, s, "rebase-reorder", map[string]bool{"reordered": false})
+	})
+	t.Run("reorder", func(t *testing.T) {
+		s, c := setup(t)
+		rebase(s, fmt.Sprintf("pick %s\npick %s\npick %s\n", c[2], c[0], c[1]), "")
+		expect(t, s, "rebase-reorder", map[string]bool{"reordered": true, "change-kept": true})
+		expect(t, s, "rebase-drop", map[string]bool{"dropped": false})
+		expect(t, s, "rebase-reword", map[string]bool{"reworded": false})
+	})
+	t.Run("drop", func(t *testing.T) {
+		s, c := setup(t)
+		rebase(s, fmt.Sprintf("pick %s\ndrop %s\npick %s\n", c[0], c[1], c[2]), "")
+		expect(t, s, "rebase-drop", map[string]bool{"dropped": true, "others-kept": true})
+		expect(t, s, "rebase-squash", map[string]bool{"squashed": false})
+		// A later, unrelated rebase does not hide the one that dropped b.
+		rebase(s, fmt.Sprintf("pick %s\npick %s\n", c[0], c[2]), "")
+		expect(t, s, "rebase-drop", map[string]bool{"dropped": true, "others-kept": true})
+	})
+}
+
+func TestStashHotfixLesson(t *testing.T) {
+	s := newSandbox(t)
+	verify := func(id string) CheckResult {
+		t.Helper()
+		return s.verify("stash-hotfix", id)
+	}
+
+	s.commit("README.md", "# Notes\n", "Start")
+	s.git("switch", "--quiet", "--create", "feature")
+	base := s.commit("notes.md", "draft\n", "Draft notes")
+	for name, content := range map[string]string{"notes.md": "draft\nmore\n", "todo.txt": "finish\n"} {
+		if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if res := verify("work-stashed"); res.Passed {
+		t.Fatalf("nothing is stashed yet: %+v", res)
+	}
+
+	s.git("stash", "push", "--quiet", "--include-untracked", "--message", "half-done notes")
+	stashes, err := s.repo.Stashes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stashes) != 1 {
+		t.Fatalf("expected one stash, got %+v", stashes)
+	}
+	if st := stashes[0]; st.Index != 0 || st.Base != base || st.Branch != "feature" || st.Message != "On feature: half-done notes" || strings.Join(st.Files, ",") != "notes.md,todo.txt" {
+		t.Fatalf("unexpected stash %+v", st)
+	}
+	if res := verify("work-stashed"); !res.Passed || !strings.Contains(res.Message, "notes.md, todo.txt on feature") {
+		t.Fatalf("expected the stash to count: %+v", res)
+	}
+	if res := verify("hotfix-committed"); res.Passed {
+		t.Fatalf("no hotfix yet: %+v", res)
+	}
+
+	s.git("switch", "--quiet", DefaultBranch)
+	s.commit("README.md", "# Notes\n\nFixed.\n", "Fix the README")
+	if res := verify("hotfix-committed"); !res.Passed {
+		t.Fatalf("expected the hotfix on %s to count: %+v", DefaultBranch, res)
+	}
+	if res := verify("back-on-branch"); res.Passed {
+		t.Fatalf("still on %s: %+v", DefaultBranch, res)
+	}
+
+	s.git("switch", "--quiet", "feature")
+	if res := verify("back-on-branch"); !res.Passed {
+		t.Fatalf("back on feature: %+v", res)
+	}
+	if res := verify("stash-popped"); res.Passed || !strings.Contains(res.Message, "stash@{0}") {
+		t.Fatalf("the stash is still listed: %+v", res)
+	}
+
+	s.git("stash", "pop", "--quiet")
+	if res := verify("stash-popped"); !res.Passed {
+		t.Fatalf("expected the popped stash to count: %+v", res)
+	}
+	dropped, err := s.repo.DroppedStashes(context.Background())
+	if err != nil || len(dropped) != 1 || dropped[0].Index != -1 || dropped[0].Commit != stashes[0].Commit {
+		t.Fatalf("DroppedStashes = %+v, %v", dropped, err)
+	}
+}
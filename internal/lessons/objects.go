@@ -0,0 +1,103 @@
+package lessons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func lessonObjectBasics() *Lesson {
+	return &Lesson{
+		ID:          "object-basics",
+		Title:       "Explore the object database",
+		Description: "Use tscgit explore (or git cat-file) to walk from HEAD to the blob that stores contents.md.",
+		Checks: []Check{
+			{
+				ID:          "contents-committed",
+				Title:       "Commit contents.md",
+				Description: "Add contents.md and commit it so it is stored as a blob.",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					_, found, err := headEntry(ctx, repo, "contents.md")
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if !found {
+						return CheckResult{Passed: false, Message: "contents.md isn't in your latest commit yet."}
+					}
+					return CheckResult{Passed: true, Message: "contents.md is stored in HEAD's tree."}
+				},
+			},
+			{
+				ID:          "blob-hash",
+				Title:       "Find the blob hash for contents.md",
+				Description: "Walk commit → tree → blob and write the blob's hash into blobhash.txt.",
+				DependsOn:   []string{"contents-committed"},
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					entry, _, err := headEntry(ctx, repo, "contents.md")
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					return checkHashAnswer(repo, "blobhash.txt", entry.Hash, "contents.md's blob")
+				},
+			},
+			{
+				ID:          "tree-hash",
+				Title:       "Find HEAD's tree hash",
+				Description: "Write the hash of the root tree of your latest commit into treehash.txt.",
+				Bonus:       true,
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					commit, err := repo.ReadCommit(ctx, "HEAD")
+					if err != nil {
+						if gitutil.IsNoCommits(err) {
+							return CheckResult{Passed: false, Message: "Make a commit first so HEAD has a tree."}
+						}
+						return CheckResult{Err: err}
+					}
+					return checkHashAnswer(repo, "treehash.txt", commit.Tree, "HEAD's tree")
+				},
+			},
+		},
+	}
+}
+
+// headEntry looks up name in the root tree of HEAD.
+func headEntry(ctx context.Context, repo *gitutil.Repository, name string) (gitutil.TreeEntry, bool, error) {
+	count, err := repo.CommitCount(ctx)
+	if err != nil || count == 0 {
+		return gitutil.TreeEntry{}, false, err
+	}
+	entries, err := repo.ReadTree(ctx, "HEAD")
+	if err != nil {
+		return gitutil.TreeEntry{}, false, err
+	}
+	for _, e := range entries {
+		if e.Name == name && e.Type == gitutil.ObjectBlob {
+			return e, true, nil
+		}
+	}
+	return gitutil.TreeEntry{}, false, nil
+}
+
+// checkHashAnswer compares the hash written in file with want. Abbreviated
+// hashes of at least seven characters are accepted.
+func checkHashAnswer(repo *gitutil.Repository, file, want, what string) CheckResult {
+	data, err := repo.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return CheckResult{Passed: false, Message: fmt.Sprintf("Write %s hash into %s.", what, file)}
+	}
+	if err != nil {
+		return CheckResult{Err: err}
+	}
+	answer := strings.ToLower(strings.TrimSpace(string(data)))
+	switch {
+	case len(answer) < 7:
+		return CheckResult{Passed: false, Message: fmt.Sprintf("%s holds %d characters; write at least the first 7 of %s hash.", file, len(answer), what)}
+	case !strings.HasPrefix(want, answer):
+		return CheckResult{Passed: false, Message: fmt.Sprintf("%s doesn't match %s hash.", file, what)}
+	}
+	return CheckResult{Passed: true, Message: fmt.Sprintf("Correct: %s is %s.", what, want[:7])}
+}
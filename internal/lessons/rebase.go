@@ -0,0 +1,335 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func lessonRebaseSquash() *Lesson {
+	return rebaseLesson("rebase-squash", "Squash commits",
+		"On a branch with a few small commits, run git rebase -i and squash them into one commit that makes the same change.",
+		rewriteCheck(Check{
+			ID:          "squashed",
+			Title:       "Squash commits into one",
+			Description: "Mark commits squash or fixup in the todo list so they fold into the commit above them.",
+			Weight:      2,
+		}, squashed, nil),
+		rewriteCheck(Check{
+			ID:          "change-kept",
+			Title:       "Keep the branch's overall change",
+			Description: "Squashing only regroups commits; the branch as a whole should change the same lines as before.",
+			DependsOn:   []string{"squashed"},
+		}, sameCombinedDiff, squashed),
+	)
+}
+
+func lessonRebaseReword() *Lesson {
+	return rebaseLesson("rebase-reword", "Reword a commit message",
+		"Fix the message of an older commit with git rebase -i, without changing what any commit does.",
+		rewriteCheck(Check{
+			ID:          "reworded",
+			Title:       "Reword a commit",
+			Description: "Mark a commit reword in the todo list and write a better message.",
+			Weight:      2,
+		}, reworded, nil),
+		rewriteCheck(Check{
+			ID:          "changes-kept",
+			Title:       "Change only the messages",
+			Description: "Every commit should still make the same change, in the same order.",
+			DependsOn:   []string{"reworded"},
+		}, samePatches, reworded),
+	)
+}
+
+func lessonRebaseReorder() *Lesson {
+	return rebaseLesson("rebase-reorder", "Reorder commits",
+		"Use git rebase -i to change the order of commits that touch different files.",
+		rewriteCheck(Check{
+			ID:          "reordered",
+			Title:       "Reorder commits",
+			Description: "Move lines in the todo list; keep every commit.",
+			Weight:      2,
+		}, reordered, nil),
+		rewriteCheck(Check{
+			ID:          "change-kept",
+			Title:       "Keep the branch's overall change",
+			Description: "The reordered commits should add up to the same change as before.",
+			DependsOn:   []string{"reordered"},
+		}, sameCombinedDiff, reordered),
+	)
+}
+
+func lessonRebaseDrop() *Lesson {
+	return rebaseLesson("rebase-drop", "Drop a commit",
+		"Remove a commit you no longer want from a branch with git rebase -i.",
+		rewriteCheck(Check{
+			ID:          "dropped",
+			Title:       "Drop a commit",
+			Description: "Mark a commit drop in the todo list, or delete its line.",
+			Weight:      2,
+		}, dropped, nil),
+		rewriteCheck(Check{
+			ID:          "others-kept",
+			Title:       "Keep the other commits as they were",
+			Description: "Every commit you did not drop should make the same change, in the same order.",
+			DependsOn:   []string{"dropped"},
+		}, othersKept, dropped),
+	)
+}
+
+// rebaseLesson builds a history rewriting lesson: a check that the current
+// branch was rebased, followed by checks on what the rebase did.
+func rebaseLesson(id, title, description string, checks ...Check) *Lesson {
+	rebased := Check{
+		ID:          "rebased",
+		Title:       "Rewrite the branch with git rebase -i",
+		Description: "Run git rebase -i on a branch with a few commits of its own, for example git rebase -i HEAD~3.",
+		Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+			branch, rws, err := rewrites(ctx, repo)
+			if err != nil {
+				return CheckResult{Err: err}
+			}
+			switch {
+			case branch == "":
+				return CheckResult{Passed: false, Message: "HEAD is detached. Switch to the branch you want to rewrite."}
+			case len(rws) == 0:
+				return CheckResult{Passed: false, Message: fmt.Sprintf("%s's reflog shows no finished rebase. Run git rebase -i HEAD~3 on it.", branch)}
+			}
+			rw := rws[0]
+			return CheckResult{Passed: true, Message: fmt.Sprintf("%s was rebased onto %.7s: %d commit(s) became %d.", branch, rw.Onto, len(rw.Before), len(rw.After))}
+		},
+	}
+	for i := range checks {
+		checks[i].DependsOn = append(checks[i].DependsOn, rebased.ID)
+	}
+	return &Lesson{
+		ID:          id,
+		Title:       title,
+		Description: description,
+		Checks:      append([]Check{rebased}, checks...),
+	}
+}
+
+// rewrite is one rebase of a branch, as recorded in the branch's reflog.
+// Before and After are the branch's own commits on either side of it,
+// oldest first.
+type rewrite struct {
+	Branch string
+	Onto   string
+	Before []gitutil.Patch
+	After  []gitutil.Patch
+}
+
+// rewrites lists the finished rebases of the current branch, newest first.
+// branch is empty when HEAD is detached.
+func rewrites(ctx context.Context, repo *gitutil.Repository) (branch string, rws []rewrite, err error) {
+	head, err := repo.Head(ctx)
+	if err != nil || head.Branch == "" {
+		return "", nil, err
+	}
+	log, err := repo.Reflog(ctx, head.Branch)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, e := range log {
+		// "rebase (finish): refs/heads/NAME onto HASH"; older versions of
+		// git write "rebase -i (finish)".
+		if !strings.HasPrefix(e.Action, "rebase") || !strings.HasSuffix(e.Action, "(finish)") {
+			continue
+		}
+		_, onto, ok := strings.Cut(e.Message, " onto ")
+		if !ok || e.Old == "" {
+			continue
+		}
+		rw := rewrite{Branch: head.Branch, Onto: onto}
+		if rw.Before, err = repo.PatchIDs(ctx, onto, e.Old); err != nil {
+			return "", nil, err
+		}
+		if rw.After, err = repo.PatchIDs(ctx, onto, e.New); err != nil {
+			return "", nil, err
+		}
+		rws = append(rws, rw)
+	}
+	return head.Branch, rws, nil
+}
+
+// rewriteTest decides whether a rewrite did what a check asks for and
+// explains why.
+type rewriteTest func(ctx context.Context, repo *gitutil.Repository, rw rewrite) (ok bool, message string, err error)
+
+// rewriteCheck completes c with a Verify that passes when some rebase of the
+// current branch passes test. When requires is set, only rebases that pass
+// it are considered, so follow-up checks look at the same rebase.
+func rewriteCheck(c Check, test, requires rewriteTest) Check {
+	c.Verify = func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+		_, rws, err := rewrites(ctx, repo)
+		if err != nil {
+			return CheckResult{Err: err}
+		}
+		failure := "No finished rebase of this branch to check. Run git rebase -i first."
+		considered := false
+		for _, rw := range rws {
+			if requires != nil {
+				ok, _, err := requires(ctx, repo, rw)
+				if err != nil {
+					return CheckResult{Err: err}
+				}
+				if !ok {
+					continue
+				}
+			}
+			ok, msg, err := test(ctx, repo, rw)
+			if err != nil {
+				return CheckResult{Err: err}
+			}
+			if ok {
+				return CheckResult{Passed: true, Message: msg}
+			}
+			if !considered {
+				failure, considered = msg, true
+			}
+		}
+		return CheckResult{Passed: false, Message: failure}
+	}
+	return c
+}
+
+// maxSquash bounds the runs of commits squashed tries, since each run costs
+// a diff.
+const maxSquash = 10
+
+func squashed(ctx context.Context, repo *gitutil.Repository, rw rewrite) (bool, string, error) {
+	old := patchSet(rw.Before)
+	for _, p := range rw.After {
+		if p.ID == "" || old[p.ID] {
+			continue
+		}
+		for i := range rw.Before {
+			for j := i + 1; j < len(rw.Before) && j-i < maxSquash; j++ {
+				id, err := repo.DiffPatchID(ctx, rw.Before[i].Commit+"^", rw.Before[j].Commit)
+				if err != nil {
+					return false, "", err
+				}
+				if id == p.ID {
+					return true, fmt.Sprintf("%.7s %q makes the same change as the %d commits from %q to %q together.", p.Commit, p.Subject, j-i+1, rw.Before[i].Subject, rw.Before[j].Subject), nil
+				}
+			}
+		}
+	}
+	return false, fmt.Sprintf("No commit on %s combines several of its old commits. In the todo list, change pick to squash or fixup on the commits to fold into the one above.", rw.Branch), nil
+}
+
+func reworded(_ context.Context, _ *gitutil.Repository, rw rewrite) (bool, string, error) {
+	for _, p := range rw.After {
+		for _, q := range rw.Before {
+			if p.ID != "" && p.ID == q.ID && p.Subject != q.Subject {
+				return true, fmt.Sprintf("%.7s keeps the change of %.7s with a new message: %q (was %q).", p.Commit, q.Commit, p.Subject, q.Subject), nil
+			}
+		}
+	}
+	return false, "Every commit kept its message. Mark a commit reword in the todo list and edit its message.", nil
+}
+
+func reordered(_ context.Context, _ *gitutil.Repository, rw rewrite) (bool, string, error) {
+	before, after := patchList(rw.Before), patchList(rw.After)
+	switch {
+	case slices.Equal(before, after):
+		return false, "The commits kept their order. Move lines around in the todo list to reorder them.", nil
+	case !slices.Equal(slices.Sorted(slices.Values(before)), slices.Sorted(slices.Values(after))):
+		return false, "The rebase changed commits, not only their order. Reorder commits that touch different files so each keeps its change.", nil
+	}
+	return true, fmt.Sprintf("The same %d changes now come in a new order, starting with %q.", len(after), rw.After[0].Subject), nil
+}
+
+// dropped passes when the rebase only removed commits: every commit after
+// it makes a change one made before, and there are fewer of them. A squash
+// also loses changes, but the combined commit is new.
+func dropped(_ context.Context, _ *gitutil.Repository, rw rewrite) (bool, string, error) {
+	old := patchSet(rw.Before)
+	for _, p := range rw.After {
+		if p.ID != "" && !old[p.ID] {
+			return false, fmt.Sprintf("%.7s %q makes a change no commit made before, so the rebase did more than drop commits. Only mark commits drop.", p.Commit, p.Subject), nil
+		}
+	}
+	if len(patchList(rw.After)) >= len(patchList(rw.Before)) {
+		return false, "Every change is still on the branch. Mark a commit drop in the todo list.", nil
+	}
+	kept := patchSet(rw.After)
+	for _, p := range rw.Before {
+		if p.ID != "" && !kept[p.ID] {
+			return true, fmt.Sprintf("%.7s %q is gone from %s.", p.Commit, p.Subject, rw.Branch), nil
+		}
+	}
+	// Before held the same change twice and one copy was dropped.
+	return true, fmt.Sprintf("%s has %d fewer commit(s).", rw.Branch, len(patchList(rw.Before))-len(patchList(rw.After))), nil
+}
+
+func othersKept(_ context.Context, _ *gitutil.Repository, rw rewrite) (bool, string, error) {
+	kept := patchSet(rw.After)
+	var rest []string
+	for _, id := range patchList(rw.Before) {
+		if kept[id] {
+			rest = append(rest, id)
+		}
+	}
+	if after := patchList(rw.After); !slices.Equal(rest, after) {
+		return false, "The rebase changed or moved other commits too. Drop only the commit you don't want.", nil
+	}
+	return true, fmt.Sprintf("The other %d commit(s) make the same changes as before.", len(rest)), nil
+}
+
+func samePatches(_ context.Context, _ *gitutil.Repository, rw rewrite) (bool, string, error) {
+	if !slices.Equal(patchList(rw.Before), patchList(rw.After)) {
+		return false, "The rebase changed what the commits do, not just their messages. Reword commits without editing them.", nil
+	}
+	return true, fmt.Sprintf("All %d commit(s) make the same changes as before.", len(rw.After)), nil
+}
+
+// sameCombinedDiff compares everything the branch changes before and after
+// the rebase, so it holds however the commits were regrouped or reordered.
+func sameCombinedDiff(ctx context.Context, repo *gitutil.Repository, rw rewrite) (bool, string, error) {
+	before, err := combinedPatchID(ctx, repo, rw.Before)
+	if err != nil {
+		return false, "", err
+	}
+	after, err := combinedPatchID(ctx, repo, rw.After)
+	if err != nil {
+		return false, "", err
+	}
+	if before != after {
+		return false, fmt.Sprintf("%s no longer makes the same overall change. Use git reset --hard %s@{1} to start over.", rw.Branch, rw.Branch), nil
+	}
+	return true, fmt.Sprintf("%s still makes the same overall change.", rw.Branch), nil
+}
+
+func combinedPatchID(ctx context.Context, repo *gitutil.Repository, patches []gitutil.Patch) (string, error) {
+	if len(patches) == 0 {
+		return "", nil
+	}
+	return repo.DiffPatchID(ctx, patches[0].Commit+"^", patches[len(patches)-1].Commit)
+}
+
+// patchList returns the non-empty patch IDs in order.
+func patchList(patches []gitutil.Patch) []string {
+	var ids []string
+	for _, p := range patches {
+		if p.ID != "" {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func patchSet(patches []gitutil.Patch) map[string]bool {
+	set := map[string]bool{}
+	for _, p := range patches {
+		if p.ID != "" {
+			set[p.ID] = true
+		}
+	}
+	return set
+}
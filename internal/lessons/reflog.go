@@ -0,0 +1,157 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/graph"
+)
+
+func lessonRecoverCommit() *Lesson {
+	return &Lesson{
+		ID:          "recover-commit",
+		Title:       "Recover a lost commit",
+		Description: "Drop a commit with git reset --hard, then find it again with git reflog (or git fsck) and put a new branch on it.",
+		Target: []graph.Node{
+			{ID: "C", Parents: []string{"B"}, Subject: "Work you dropped", Labels: []string{"recovered"}},
+			{ID: "B", Subject: "Earlier work", Labels: []string{DefaultBranch}, Head: true},
+		},
+		Checks: []Check{
+			{
+				ID:          "commit-dropped",
+				Title:       "Drop a commit with git reset",
+				Description: "Commit something, then run git reset --hard HEAD~1 so the commit disappears from git log.",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					dropped, err := droppedCommits(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if len(dropped) == 0 {
+						return CheckResult{Passed: false, Message: "HEAD's reflog shows no reset that left a commit behind. Commit a change, then git reset --hard HEAD~1."}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("git reset left %.7s behind; git log no longer shows it.", dropped[0].Old)}
+				},
+			},
+			{
+				ID:          "original-recovered",
+				Title:       "Recover the original commit",
+				Description: "Look the dropped commit up in git reflog or git fsck --lost-found and point a branch at that exact hash.",
+				DependsOn:   []string{"commit-dropped"},
+				Weight:      2,
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					branch, commit, err := recoveredBranch(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if branch != "" {
+						return CheckResult{Passed: true, Message: fmt.Sprintf("%s points at %.7s, the commit you dropped.", branch, commit)}
+					}
+					dropped, err := droppedCommits(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if retyped, original, err := retypedCommit(ctx, repo, dropped); err != nil {
+						return CheckResult{Err: err}
+					} else if retyped != "" {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("%.7s repeats the message of the dropped %.7s but is a new commit. Recover the original from git reflog instead of typing it again.", retyped, original)}
+					}
+					return CheckResult{Passed: false, Message: fmt.Sprintf("No branch points at a dropped commit. git reflog lists it; try git branch recovered %.7s.", dropped[0].Old)}
+				},
+			},
+			{
+				ID:          "branch-recreated",
+				Title:       "Create a new branch at it",
+				Description: "Use git branch NAME HASH (or git switch -c) so the branch starts at the recovered commit.",
+				DependsOn:   []string{"original-recovered"},
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					branch, commit, err := recoveredBranch(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					log, err := repo.Reflog(ctx, branch)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if len(log) == 0 {
+						return CheckResult{Passed: true, Message: fmt.Sprintf("%s has no reflog to check, but it points at the recovered commit.", branch)}
+					}
+					first := log[len(log)-1]
+					if !first.Created() || first.New != commit {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("%s was moved onto %.7s after it was created. Create a fresh branch there with git branch recovered %.7s.", branch, commit, commit)}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("%s was created at %.7s (%s: %s).", branch, commit, first.Action, first.Message)}
+				},
+			},
+		},
+	}
+}
+
+// droppedCommits lists the resets in HEAD's reflog that moved away from a
+// commit the new position does not contain, newest first.
+func droppedCommits(ctx context.Context, repo *gitutil.Repository) ([]gitutil.ReflogEntry, error) {
+	log, err := repo.Reflog(ctx, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var dropped []gitutil.ReflogEntry
+	for _, e := range log {
+		// The oldest entry of an expired reflog does not say where the
+		// reset started from.
+		if e.Action != "reset" || e.Old == "" || e.Created() || e.Old == e.New {
+			continue
+		}
+		ahead, err := repo.CommitsAhead(ctx, e.New, e.Old)
+		if err != nil {
+			return nil, err
+		}
+		if ahead > 0 {
+			dropped = append(dropped, e)
+		}
+	}
+	return dropped, nil
+}
+
+// recoveredBranch finds a local branch whose tip is a dropped commit.
+func recoveredBranch(ctx context.Context, repo *gitutil.Repository) (branch, commit string, err error) {
+	dropped, err := droppedCommits(ctx, repo)
+	if err != nil {
+		return "", "", err
+	}
+	refs, err := repo.Refs(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	for _, e := range dropped {
+		for _, ref := range refs {
+			if ref.Kind == gitutil.RefBranch && ref.Hash == e.Old {
+				return ref.Name, e.Old, nil
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// retypedCommit finds a reachable commit with the subject of a dropped one
+// but a different hash: the change was typed in again rather than
+// recovered.
+func retypedCommit(ctx context.Context, repo *gitutil.Repository, dropped []gitutil.ReflogEntry) (retyped, original string, err error) {
+	commits, err := repo.Log(ctx, 0)
+	if err != nil {
+		return "", "", err
+	}
+	for _, e := range dropped {
+		lost, err := repo.ReadCommit(ctx, e.Old)
+		if err != nil {
+			return "", "", err
+		}
+		subject, _, _ := strings.Cut(lost.Message, "\n")
+		for _, c := range commits {
+			if c.Subject == subject && c.Hash != e.Old {
+				return c.Hash, e.Old, nil
+			}
+		}
+	}
+	return "", "", nil
+}
@@ -0,0 +1,88 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func lessonStagingBasics() *Lesson {
+	return &Lesson{
+		ID:          "staging-basics",
+		Title:       "Stage changes deliberately",
+		Description: "See how HEAD, the index and your working tree differ, and choose exactly what goes into the next commit.",
+		Panel:       PanelTrees,
+		Checks: []Check{
+			{
+				ID:          "contents-staged",
+				Title:       "Stage contents.md",
+				Description: "Use git add contents.md so the index holds your latest version.",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					status, err := repo.Status(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					entry, ok := status.Entry("contents.md")
+					switch {
+					case !ok:
+						return CheckResult{Passed: false, Message: "contents.md has no changes staged. Edit it or create it, then git add it."}
+					case entry.Kind == gitutil.EntryUntracked:
+						return CheckResult{Passed: false, Message: "contents.md is untracked. Run git add contents.md to stage it."}
+					case !entry.Staged():
+						return CheckResult{Passed: false, Message: "contents.md is modified but not staged yet."}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("contents.md is staged (%s in the index).", codeName(entry.Index))}
+				},
+			},
+			{
+				ID:          "partial-stage",
+				Title:       "Keep a change out of the index",
+				Description: "Edit a staged file again so it has both staged and unstaged changes.",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					status, err := repo.Status(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					for _, e := range status.Entries {
+						if e.Staged() && e.Unstaged() {
+							return CheckResult{Passed: true, Message: fmt.Sprintf("%s differs in all three trees: staged and unstaged changes.", e.Path)}
+						}
+					}
+					return CheckResult{Passed: false, Message: "No file has both staged and unstaged changes. Stage a file, then edit it again."}
+				},
+			},
+			{
+				ID:          "no-conflicts",
+				Title:       "No unresolved conflicts",
+				Description: "Make sure nothing is left unmerged in the index.",
+				Optional:    true,
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					status, err := repo.Status(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if unmerged := status.Unmerged(); len(unmerged) > 0 {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("%d path(s) still have conflicts, starting with %s.", len(unmerged), unmerged[0].Path)}
+					}
+					return CheckResult{Passed: true, Message: "The index has no conflicts."}
+				},
+			},
+		},
+	}
+}
+
+func codeName(c gitutil.StatusCode) string {
+	switch c {
+	case gitutil.StatusAdded:
+		return "added"
+	case gitutil.StatusModified:
+		return "modified"
+	case gitutil.StatusDeleted:
+		return "deleted"
+	case gitutil.StatusRenamed:
+		return "renamed"
+	default:
+		return c.String()
+	}
+}
@@ -0,0 +1,147 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func lessonStashHotfix() *Lesson {
+	return &Lesson{
+		ID:          "stash-hotfix",
+		Title:       "Stash work for a hotfix",
+		Description: "Put unfinished work aside with git stash, commit an urgent fix on another branch, then come back and pop your work.",
+		Checks: []Check{
+			{
+				ID:          "work-stashed",
+				Title:       "Stash your uncommitted work",
+				Description: "With changes you are not ready to commit, run git stash (add -u to include new files).",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					stash, err := latestStash(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					switch {
+					case stash == nil:
+						return CheckResult{Passed: false, Message: "No stash found. Edit a tracked file, then run git stash."}
+					case stash.Branch == "":
+						return CheckResult{Passed: false, Message: "Your latest stash was made on a detached HEAD. Check out a branch and stash from there."}
+					}
+					where := "git stash list shows it"
+					if stash.Index < 0 {
+						where = "already popped"
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("You stashed %s on %s (%s).", fileList(stash.Files), stash.Branch, where)}
+				},
+			},
+			{
+				ID:          "hotfix-committed",
+				Title:       "Commit a hotfix on another branch",
+				Description: "While your work is stashed, switch to another branch, such as main or a new hotfix branch, and commit a fix there.",
+				DependsOn:   []string{"work-stashed"},
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					stash, err := latestStash(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					refs, err := repo.Refs(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					commits, err := repo.Log(ctx, 0)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					byHash := make(map[string]gitutil.Commit, len(commits))
+					for _, c := range commits {
+						byHash[c.Hash] = c
+					}
+					for _, ref := range refs {
+						if ref.Kind != gitutil.RefBranch || ref.Name == stash.Branch {
+							continue
+						}
+						if tip, ok := byHash[ref.Hash]; ok && !tip.AuthorTime.Before(stash.Time) {
+							return CheckResult{Passed: true, Message: fmt.Sprintf("%s has %q, committed after you stashed.", ref.Name, tip.Subject)}
+						}
+					}
+					return CheckResult{Passed: false, Message: fmt.Sprintf("No branch besides %s has a commit made after you stashed. Switch to %s, fix something and commit it.", stash.Branch, DefaultBranch)}
+				},
+			},
+			{
+				ID:          "back-on-branch",
+				Title:       "Switch back to your branch",
+				Description: "Return to the branch you stashed from.",
+				DependsOn:   []string{"hotfix-committed"},
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					stash, err := latestStash(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					branch, err := repo.CurrentBranch(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if branch != stash.Branch {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("You're on %s. Run git switch %s to get back to your work.", branch, stash.Branch)}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("Back on %s.", branch)}
+				},
+			},
+			{
+				ID:          "stash-popped",
+				Title:       "Pop the stash",
+				Description: "Run git stash pop to bring your work back and remove it from the stash list.",
+				DependsOn:   []string{"back-on-branch"},
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					stash, err := latestStash(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if stash.Index >= 0 {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("stash@{%d} is still in git stash list. Run git stash pop.", stash.Index)}
+					}
+					status, err := repo.Status(ctx)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					for _, path := range stash.Files {
+						if _, ok := status.Entry(path); !ok {
+							return CheckResult{Passed: false, Message: fmt.Sprintf("%s doesn't have your stashed changes. If you dropped the stash, git stash apply %.7s brings them back.", path, stash.Commit)}
+						}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("Your changes to %s are back on %s.", fileList(stash.Files), stash.Branch)}
+				},
+			},
+		},
+	}
+}
+
+// latestStash returns the newest stash, whether it is still listed or was
+// already popped, or nil when there is none.
+func latestStash(ctx context.Context, repo *gitutil.Repository) (*gitutil.StashEntry, error) {
+	listed, err := repo.Stashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := repo.DroppedStashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var latest *gitutil.StashEntry
+	for _, stashes := range [][]gitutil.StashEntry{listed, dropped} {
+		if len(stashes) > 0 && (latest == nil || stashes[0].Time.After(latest.Time)) {
+			latest = &stashes[0]
+		}
+	}
+	return latest, nil
+}
+
+// fileList names up to three files.
+func fileList(files []string) string {
+	if len(files) > 3 {
+		return fmt.Sprintf("%s and %d more files", strings.Join(files[:3], ", "), len(files)-3)
+	}
+	return strings.Join(files, ", ")
+}
@@ -0,0 +1,202 @@
+package lessons
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/graph"
+)
+
+func lessonTagRelease() *Lesson {
+	return &Lesson{
+		ID:          "tag-release",
+		Title:       "Tag a release",
+		Description: "Mark the tip of the default branch with a semantic version, annotate it, and push it to a remote.",
+		Target: []graph.Node{
+			{ID: "B", Parents: []string{"A"}, Subject: "Finish the release", Labels: []string{DefaultBranch, "tag: v1.0.0"}, Head: true},
+			{ID: "A", Subject: "Earlier work"},
+		},
+		Checks: []Check{
+			{
+				ID:          "semver-tag",
+				Title:       "Tag the release with a version",
+				Description: "Tag the latest commit on the default branch with a semantic version such as v1.0.0.",
+				Weight:      2,
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					release, tags, err := releaseTag(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if release == nil {
+						if len(tags) > 0 {
+							return CheckResult{Passed: false, Message: fmt.Sprintf("%s isn't a semantic version. Name release tags like v1.0.0.", tags[0].Name)}
+						}
+						return CheckResult{Passed: false, Message: fmt.Sprintf("No tags yet. Run git tag -a v1.0.0 -m \"First release\" on %s.", DefaultBranch)}
+					}
+					tip, err := branchTip(ctx, repo, DefaultBranch)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					switch {
+					case tip == "":
+						return CheckResult{Passed: false, Message: fmt.Sprintf("Branch %s not found. Releases are tagged on the default branch.", DefaultBranch)}
+					case release.Commit != tip:
+						return CheckResult{Passed: false, Message: fmt.Sprintf("%s is on %.7s, but %s is at %.7s. Tag the latest commit; git tag -f moves a tag.", release.Name, release.Commit, DefaultBranch, tip)}
+					}
+					return CheckResult{Passed: true, Message: fmt.Sprintf("%s marks %.7s, the tip of %s.", release.Name, tip, DefaultBranch)}
+				},
+			},
+			{
+				ID:          "annotated-tag",
+				Title:       "Annotate a tag with a message",
+				Description: "Create the tag with git tag -a and a message so it records who tagged it, when and why.",
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					release, tags, err := releaseTag(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if release != nil && release.Annotated && strings.TrimSpace(release.Message) != "" {
+						return CheckResult{Passed: true, Message: annotation(*release)}
+					}
+					for _, t := range tags {
+						if t.Annotated && strings.TrimSpace(t.Message) != "" {
+							return CheckResult{Passed: true, Message: annotation(t)}
+						}
+					}
+					if release != nil {
+						return CheckResult{Passed: false, Message: fmt.Sprintf("%s is a lightweight tag. Replace it with git tag -a -f %s -m \"...\".", release.Name, release.Name)}
+					}
+					return CheckResult{Passed: false, Message: "No annotated tags yet. Use git tag -a NAME -m \"message\"."}
+				},
+			},
+			{
+				ID:          "tag-pushed",
+				Title:       "Push the tag",
+				Description: "Push the release tag to a remote in a local folder, such as a bare repository made with git init --bare.",
+				DependsOn:   []string{"semver-tag"},
+				Optional:    true,
+				Verify: func(ctx context.Context, repo *gitutil.Repository) CheckResult {
+					release, _, err := releaseTag(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					remotes, err := localRemotes(ctx, repo)
+					if err != nil {
+						return CheckResult{Err: err}
+					}
+					if len(remotes) == 0 {
+						return CheckResult{Passed: false, Message: "No remote in a local folder. Try git init --bare ../release.git and git remote add origin ../release.git."}
+					}
+					for _, remote := range remotes {
+						tags, err := repo.RemoteTags(ctx, remote)
+						if err != nil {
+							return CheckResult{Err: err}
+						}
+						for _, t := range tags {
+							if t.Name == release.Name && t.Hash == release.Commit {
+								return CheckResult{Passed: true, Message: fmt.Sprintf("%s has %s.", remote, release.Name)}
+							}
+						}
+					}
+					return CheckResult{Passed: false, Message: fmt.Sprintf("%s isn't on %s yet. Run git push %s %s.", release.Name, remotes[0], remotes[0], release.Name)}
+				},
+			},
+		},
+	}
+}
+
+// semverPattern matches semantic versions, with an optional v prefix.
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// releaseTag returns the tag with the highest semantic version, or nil,
+// together with every tag in the repository.
+func releaseTag(ctx context.Context, repo *gitutil.Repository) (*gitutil.Tag, []gitutil.Tag, error) {
+	tags, err := repo.Tags(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var release *gitutil.Tag
+	for i, t := range tags {
+		if !semverPattern.MatchString(t.Name) {
+			continue
+		}
+		if release == nil || compareSemver(t.Name, release.Name) > 0 {
+			release = &tags[i]
+		}
+	}
+	return release, tags, nil
+}
+
+// compareSemver orders two semantic versions. A pre-release sorts before
+// its release; pre-releases are compared as plain strings.
+func compareSemver(a, b string) int {
+	ma, mb := semverPattern.FindStringSubmatch(a), semverPattern.FindStringSubmatch(b)
+	for i := 1; i <= 3; i++ {
+		x, _ := strconv.Atoi(ma[i])
+		y, _ := strconv.Atoi(mb[i])
+		if x != y {
+			return x - y
+		}
+	}
+	switch pa, pb := ma[4], mb[4]; {
+	case pa == pb:
+		return 0
+	case pa == "":
+		return 1
+	case pb == "":
+		return -1
+	default:
+		return strings.Compare(pa, pb)
+	}
+}
+
+func branchTip(ctx context.Context, repo *gitutil.Repository, name string) (string, error) {
+	refs, err := repo.Refs(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Kind == gitutil.RefBranch && ref.Name == name {
+			return ref.Hash, nil
+		}
+	}
+	return "", nil
+}
+
+func annotation(t gitutil.Tag) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(t.Message), "\n")
+	return fmt.Sprintf("%s was tagged by %s: %q", t.Name, t.Tagger, subject)
+}
+
+// localRemotes lists the remotes whose URL is a folder on this machine.
+// Only those are queried, so verification never waits on the network.
+func localRemotes(ctx context.Context, repo *gitutil.Repository) ([]string, error) {
+	names, err := repo.Remotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var local []string
+	for _, name := range names {
+		url, ok, err := repo.Config(ctx, "remote."+name+".url")
+		if err != nil {
+			return nil, err
+		}
+		path := strings.TrimPrefix(url, "file://")
+		if !ok || strings.Contains(path, "://") {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repo.Root, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			local = append(local, name)
+		}
+	}
+	return local, nil
+}
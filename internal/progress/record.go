@@ -0,0 +1,54 @@
+package progress
+
+import (
+	"strconv"
+
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+// FromVerify converts a verification report into a Record.
+func FromVerify(report verify.Report, repo string) Record {
+	rec := Record{
+		Kind:    KindLesson,
+		ID:      report.Lesson,
+		Passed:  report.Passed,
+		Points:  report.Score.Points,
+		Max:     report.Score.Max,
+		Percent: report.Score.Percent,
+		Repo:    repo,
+		Checks:  make(map[string]string, len(report.Checks)),
+	}
+	for _, check := range report.Checks {
+		rec.Checks[check.ID] = check.Status
+	}
+	return rec
+}
+
+// FromRun converts a run report into a Record. Graded steps are keyed by
+// their one-based position.
+func FromRun(report run.Report, repo string) Record {
+	rec := Record{
+		Kind:    KindScript,
+		ID:      report.Script,
+		Passed:  report.Passed,
+		Points:  report.Score.Points,
+		Max:     report.Score.Max,
+		Percent: report.Score.Percent,
+		Repo:    repo,
+		Checks:  map[string]string{},
+	}
+	step := 0
+	for _, s := range report.Steps {
+		if s.Phase != run.PhaseCheck {
+			continue
+		}
+		step++
+		status := verify.StatusFailed
+		if s.Passed {
+			status = verify.StatusPassed
+		}
+		rec.Checks[strconv.Itoa(step)] = status
+	}
+	return rec
+}
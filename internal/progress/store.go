@@ -0,0 +1,157 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Kind distinguishes verification lessons from run scripts, whose IDs may
+// overlap.
+type Kind string
+
+const (
+	KindLesson Kind = "lesson"
+	KindScript Kind = "script"
+)
+
+// Record is the latest outcome of a lesson or script.
+type Record struct {
+	Kind     Kind    `json:"kind"`
+	ID       string  `json:"id"`
+	Passed   bool    `json:"passed"`
+	Points   int     `json:"points"`
+	Max      int     `json:"max"`
+	Percent  float64 `json:"percent"`
+	Repo     string  `json:"repo,omitempty"`
+	Attempts int     `json:"attempts"`
+	// EverPassed stays set once any attempt has passed.
+	EverPassed bool `json:"everPassed"`
+	// Checks maps check IDs (or step numbers for scripts) to their last
+	// status: passed, failed, error or skipped.
+	Checks    map[string]string `json:"checks,omitempty"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// Store holds progress records backed by a JSON file.
+type Store struct {
+	path    string
+	records map[string]Record
+}
+
+type fileFormat struct {
+	Version int      `json:"version"`
+	Records []Record `json:"records"`
+}
+
+// DefaultPath returns the progress file inside the user's config directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("progress: locate config dir: %w", err)
+	}
+	return filepath.Join(dir, "tscgit", "progress.json"), nil
+}
+
+// Load reads the store at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, records: map[string]Record{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("progress: read %s: %w", path, err)
+	}
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("progress: parse %s: %w", path, err)
+	}
+	for _, rec := range f.Records {
+		s.records[key(rec.Kind, rec.ID)] = rec
+	}
+	return s, nil
+}
+
+// LoadDefault loads the store at DefaultPath.
+func LoadDefault() (*Store, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns the record for a lesson or script.
+func (s *Store) Get(kind Kind, id string) (Record, bool) {
+	rec, ok := s.records[key(kind, id)]
+	return rec, ok
+}
+
+// List returns every record ordered by kind and ID.
+func (s *Store) List() []Record {
+	out := make([]Record, 0, len(s.records))
+	for _, rec := range s.records {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Put stores rec as the latest attempt, carrying over the attempt count and
+// EverPassed from any previous record.
+func (s *Store) Put(rec Record) {
+	k := key(rec.Kind, rec.ID)
+	prev := s.records[k]
+	rec.Attempts = prev.Attempts + 1
+	rec.EverPassed = prev.EverPassed || rec.Passed
+	if rec.UpdatedAt.IsZero() {
+		rec.UpdatedAt = time.Now().UTC()
+	}
+	s.records[k] = rec
+}
+
+// Save writes the store atomically, creating its directory if needed.
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("progress: create dir: %w", err)
+	}
+	data, err := json.MarshalIndent(fileFormat{Version: 1, Records: s.List()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("progress: encode: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".progress-*.json")
+	if err != nil {
+		return fmt.Errorf("progress: write: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("progress: write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("progress: write: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("progress: write: %w", err)
+	}
+	return nil
+}
+
+func key(kind Kind, id string) string {
+	return string(kind) + ":" + id
+}
@@ -0,0 +1,36 @@
+package progress
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "progress.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load missing file: %v", err)
+	}
+
+	store.Put(Record{Kind: KindLesson, ID: "init-basics", Passed: true, Points: 3, Max: 3})
+	store.Put(Record{Kind: KindLesson, ID: "init-basics", Passed: false, Points: 1, Max: 3})
+	store.Put(Record{Kind: KindScript, ID: "init-basics", Passed: false})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	rec, ok := reloaded.Get(KindLesson, "init-basics")
+	if !ok {
+		t.Fatalf("expected lesson record")
+	}
+	if rec.Passed || !rec.EverPassed || rec.Attempts != 2 || rec.Points != 1 {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if len(reloaded.List()) != 2 {
+		t.Fatalf("expected lesson and script records to be kept apart, got %d", len(reloaded.List()))
+	}
+}
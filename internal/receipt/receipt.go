@@ -0,0 +1,281 @@
+// Package receipt issues and checks signed completion receipts: a record of
+// a passing lesson or script that an instructor can verify, unlike a
+// screenshot of the results screen.
+//
+// Receipts are signed with an Ed25519 private key, verified with the
+// matching public key, or with a shared HMAC secret. A receipt only proves
+// as much as the key is protected: sign in an environment students cannot
+// read, such as a CI job with the key in a secret.
+package receipt
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/progress"
+)
+
+// Version is the receipt format version.
+const Version = 1
+
+// Signature algorithms.
+const (
+	AlgEd25519 = "ed25519"
+	AlgHMAC    = "hmac-sha256"
+)
+
+// signingContext is prepended to the payload before signing so a receipt
+// signature cannot be replayed as a signature over anything else.
+const signingContext = "tscgit-receipt-v1\n"
+
+// minSecret is the shortest HMAC secret accepted, in bytes.
+const minSecret = 16
+
+var (
+	// ErrBadSignature means the receipt was altered or signed with another
+	// key.
+	ErrBadSignature = errors.New("receipt: signature does not match")
+	// ErrNoPrivateKey means a key that can only verify was used to sign.
+	ErrNoPrivateKey = errors.New("receipt: key cannot sign; use the private key")
+)
+
+// Student identifies who earned the receipt, from git's user.name and
+// user.email.
+type Student struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Receipt is the signed record of one passing attempt.
+type Receipt struct {
+	Version int           `json:"version"`
+	Kind    progress.Kind `json:"kind"`
+	ID      string        `json:"id"`
+	Passed  bool          `json:"passed"`
+	Points  int           `json:"points"`
+	Max     int           `json:"max"`
+	Percent float64       `json:"percent"`
+	// Checks maps check IDs, or step numbers for scripts, to their status.
+	Checks map[string]string `json:"checks"`
+	// Head is the commit HEAD pointed at, empty outside a repository or
+	// before the first commit.
+	Head        string    `json:"head,omitempty"`
+	Student     Student   `json:"student"`
+	IssuedAt    time.Time `json:"issuedAt"`
+	ToolVersion string    `json:"toolVersion"`
+}
+
+// New builds a receipt from a progress record.
+func New(rec progress.Record, head string, student Student, toolVersion string, now time.Time) Receipt {
+	return Receipt{
+		Version:     Version,
+		Kind:        rec.Kind,
+		ID:          rec.ID,
+		Passed:      rec.Passed,
+		Points:      rec.Points,
+		Max:         rec.Max,
+		Percent:     rec.Percent,
+		Checks:      rec.Checks,
+		Head:        head,
+		Student:     student,
+		IssuedAt:    now.UTC().Truncate(time.Second),
+		ToolVersion: toolVersion,
+	}
+}
+
+// Signed is a receipt with its signature. The signature covers the compact
+// JSON encoding of the receipt exactly as it was signed, so verification
+// does not depend on how Receipt would be encoded today.
+type Signed struct {
+	Receipt   json.RawMessage `json:"receipt"`
+	Algorithm string          `json:"alg"`
+	KeyID     string          `json:"keyId"`
+	Signature string          `json:"sig"`
+}
+
+// Key signs or verifies receipts. An Ed25519 public key can only verify.
+type Key struct {
+	alg    string
+	pub    ed25519.PublicKey
+	priv   ed25519.PrivateKey
+	secret []byte
+}
+
+// ParseKey reads a PEM "PRIVATE KEY" or "PUBLIC KEY" block holding an
+// Ed25519 key, as written by GenerateKey or openssl genpkey -algorithm
+// ed25519. Anything else is taken as an HMAC secret, with surrounding
+// whitespace removed.
+func ParseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := bytes.TrimSpace(data)
+		if len(secret) < minSecret {
+			return nil, fmt.Errorf("receipt: HMAC secret must be at least %d bytes", minSecret)
+		}
+		return &Key{alg: AlgHMAC, secret: secret}, nil
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("receipt: parse private key: %w", err)
+		}
+		priv, ok := k.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("receipt: private key is %T, want Ed25519", k)
+		}
+		return &Key{alg: AlgEd25519, priv: priv, pub: priv.Public().(ed25519.PublicKey)}, nil
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("receipt: parse public key: %w", err)
+		}
+		pub, ok := k.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("receipt: public key is %T, want Ed25519", k)
+		}
+		return &Key{alg: AlgEd25519, pub: pub}, nil
+	default:
+		return nil, fmt.Errorf("receipt: unsupported PEM block %q", block.Type)
+	}
+}
+
+// LoadKey reads the key file at path. See ParseKey.
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: read key: %w", err)
+	}
+	return ParseKey(data)
+}
+
+// GenerateKey creates an Ed25519 key pair, PEM encoded.
+func GenerateKey() (privatePEM, publicPEM []byte, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("receipt: generate key: %w", err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("receipt: encode private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("receipt: encode public key: %w", err)
+	}
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	return privatePEM, publicPEM, nil
+}
+
+// Algorithm returns the signature algorithm the key uses.
+func (k *Key) Algorithm() string {
+	return k.alg
+}
+
+// ID is a short fingerprint that tells keys apart without revealing them:
+// the start of the SHA-256 of the public key or of the HMAC secret.
+func (k *Key) ID() string {
+	material := []byte(k.pub)
+	if k.alg == AlgHMAC {
+		material = k.secret
+	}
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (k *Key) sign(payload []byte) ([]byte, error) {
+	msg := append([]byte(signingContext), payload...)
+	switch {
+	case k.alg == AlgHMAC:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(msg)
+		return mac.Sum(nil), nil
+	case k.priv != nil:
+		return ed25519.Sign(k.priv, msg), nil
+	}
+	return nil, ErrNoPrivateKey
+}
+
+func (k *Key) verify(payload, sig []byte) bool {
+	msg := append([]byte(signingContext), payload...)
+	if k.alg == AlgHMAC {
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(msg)
+		return hmac.Equal(mac.Sum(nil), sig)
+	}
+	return ed25519.Verify(k.pub, msg, sig)
+}
+
+// Sign encodes r and signs it with k.
+func Sign(r Receipt, k *Key) (*Signed, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: encode: %w", err)
+	}
+	sig, err := k.sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Signed{
+		Receipt:   payload,
+		Algorithm: k.alg,
+		KeyID:     k.ID(),
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// Parse decodes a signed receipt file without checking its signature.
+func Parse(data []byte) (*Signed, error) {
+	var s Signed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("receipt: parse: %w", err)
+	}
+	if len(s.Receipt) == 0 || s.Signature == "" {
+		return nil, errors.New("receipt: not a signed receipt")
+	}
+	return &s, nil
+}
+
+// Verify checks the signature with k and returns the receipt it covers.
+func (s *Signed) Verify(k *Key) (Receipt, error) {
+	var r Receipt
+	if s.Algorithm != k.alg {
+		return r, fmt.Errorf("receipt: signed with %s, but the key is %s", s.Algorithm, k.alg)
+	}
+	if s.KeyID != k.ID() {
+		return r, fmt.Errorf("%w: signed by key %s, checked with key %s", ErrBadSignature, s.KeyID, k.ID())
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return r, fmt.Errorf("receipt: decode signature: %w", err)
+	}
+	// The payload was signed compact; undo any indentation added when the
+	// file was written or reformatted.
+	var payload bytes.Buffer
+	if err := json.Compact(&payload, s.Receipt); err != nil {
+		return r, fmt.Errorf("receipt: decode: %w", err)
+	}
+	if !k.verify(payload.Bytes(), sig) {
+		return r, ErrBadSignature
+	}
+	if err := json.Unmarshal(s.Receipt, &r); err != nil {
+		return r, fmt.Errorf("receipt: decode: %w", err)
+	}
+	if r.Version != Version {
+		return r, fmt.Errorf("receipt: unsupported version %d", r.Version)
+	}
+	return r, nil
+}
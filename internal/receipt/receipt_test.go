@@ -0,0 +1,112 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/progress"
+)
+
+func sampleReceipt() Receipt {
+	rec := progress.Record{
+		Kind:    progress.KindLesson,
+		ID:      "init-basics",
+		Passed:  true,
+		Points:  3,
+		Max:     3,
+		Percent: 100,
+		Checks:  map[string]string{"first-commit": "passed"},
+	}
+	return New(rec, "0123abcd", Student{Name: "Ada", Email: "ada@example.com"}, "v1.2.3", time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC))
+}
+
+func TestSignAndVerifyEd25519(t *testing.T) {
+	privPEM, pubPEM, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParseKey(privPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParseKey(pubPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.ID() != pub.ID() || pub.Algorithm() != AlgEd25519 {
+		t.Fatalf("key IDs differ: %s vs %s", priv.ID(), pub.ID())
+	}
+
+	signed, err := Sign(sampleReceipt(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsed.Verify(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "init-basics" || got.Head != "0123abcd" || got.Student.Email != "ada@example.com" || !got.IssuedAt.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected receipt %+v", got)
+	}
+
+	if _, err := Sign(sampleReceipt(), pub); !errors.Is(err, ErrNoPrivateKey) {
+		t.Fatalf("signing with a public key: %v", err)
+	}
+
+	// Changing any byte of the receipt breaks the signature.
+	tampered := *parsed
+	tampered.Receipt = bytes.Replace(parsed.Receipt, []byte(`"points": 3`), []byte(`"points": 4`), 1)
+	if _, err := tampered.Verify(pub); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered receipt: %v", err)
+	}
+
+	_, otherPub, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := ParseKey(otherPub)
+	if _, err := parsed.Verify(other); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong key: %v", err)
+	}
+}
+
+func TestSignAndVerifyHMAC(t *testing.T) {
+	key, err := ParseKey([]byte("  correct horse battery staple\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := Sign(sampleReceipt(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Algorithm != AlgHMAC {
+		t.Fatalf("algorithm = %s", signed.Algorithm)
+	}
+	if _, err := signed.Verify(key); err != nil {
+		t.Fatal(err)
+	}
+
+	wrong, _ := ParseKey([]byte("correct horse battery stapler"))
+	if _, err := signed.Verify(wrong); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("wrong secret: %v", err)
+	}
+	_, pubPEM, _ := GenerateKey()
+	pub, _ := ParseKey(pubPEM)
+	if _, err := signed.Verify(pub); err == nil {
+		t.Fatal("an Ed25519 key must not verify an HMAC receipt")
+	}
+	if _, err := ParseKey([]byte("short")); err == nil {
+		t.Fatal("short secrets should be rejected")
+	}
+}
@@ -5,16 +5,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/trace"
 )
 
+// Phase identifies which part of a script produced a StepResult.
+type Phase string
+
+const (
+	PhaseSetup    Phase = "setup"
+	PhaseCheck    Phase = "check"
+	PhaseTeardown Phase = "teardown"
+)
+
+// ErrSetupFailed is returned by Execute when a setup step fails and the graded
+// steps were skipped.
+var ErrSetupFailed = errors.New("run: setup failed")
+
+// TeardownTimeout bounds the teardown phase, which runs on a context detached
+// from the caller's so it still executes after cancellation. It is set from
+// the timeouts.teardown configuration key.
+var TeardownTimeout = 15 * time.Second
+
+// StepTimeout bounds each command RunStep executes. Zero leaves only the
+// caller's deadline. It is set from the timeouts.step configuration key.
+var StepTimeout = 15 * time.Second
+
 // StepResult captures the outcome of a single scripted command.
 type StepResult struct {
 	Step      Step
+	Phase     Phase
 	Stdout    string
 	Stderr    string
 	ExitCode  int
@@ -24,13 +51,48 @@ type StepResult struct {
 	ExecError error
 }
 
-// Execute runs every step within the script sequentially.
-func Execute(ctx context.Context, script *Script, emitter func(StepResult)) ([]StepResult, error) {
+// Execute runs the script's setup steps, then every graded step sequentially,
+// then its teardown steps. Teardown runs even if setup fails or ctx is
+// cancelled. Results from all three phases are returned and emitted, tagged
+// with their Phase.
+func Execute(ctx context.Context, script *Script, emitter func(StepResult)) (results []StepResult, err error) {
 	if script == nil {
 		return nil, errors.New("run: script is nil")
 	}
 
-	results := make([]StepResult, 0, len(script.Steps))
+	results = make([]StepResult, 0, len(script.Setup)+len(script.Steps)+len(script.Teardown))
+	policy := script.EffectivePolicy()
+	record := func(res StepResult) {
+		results = append(results, res)
+		if emitter != nil {
+			emitter(res)
+		}
+	}
+
+	defer func() {
+		if len(script.Teardown) == 0 {
+			return
+		}
+		tctx, cancel := TeardownContext(ctx)
+		defer cancel()
+		for _, step := range script.Teardown {
+			res := RunStep(tctx, policy, step)
+			res.Phase = PhaseTeardown
+			record(res)
+		}
+	}()
+
+	for _, step := range script.Setup {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		res := RunStep(ctx, policy, step)
+		res.Phase = PhaseSetup
+		record(res)
+		if !res.Passed {
+			return results, fmt.Errorf("%w: %s", ErrSetupFailed, step.Command)
+		}
+	}
 
 	for _, step := range script.Steps {
 		select {
@@ -39,35 +101,68 @@ func Execute(ctx context.Context, script *Script, emitter func(StepResult)) ([]S
 		default:
 		}
 
-		res := RunStep(ctx, step)
-
-		results = append(results, res)
-		if emitter != nil {
-			emitter(res)
-		}
+		res := RunStep(ctx, policy, step)
+		res.Phase = PhaseCheck
+		record(res)
 	}
 
 	return results, nil
 }
 
-func runCommand(ctx context.Context, command string) (string, string, int, error) {
-	shell, args := defaultShell()
+// TeardownContext derives a context for teardown steps that survives
+// cancellation of parent but is bounded by TeardownTimeout.
+func TeardownContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(parent), TeardownTimeout)
+}
+
+func runCommand(ctx context.Context, dir, command string, trusted bool) (string, string, int, error) {
+	shell, args := DefaultShell()
 	args = append(args, command)
 	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Dir = dir
+	if trusted {
+		cmd.Env = gitutil.Environ(os.Environ())
+	} else {
+		cmd.Env = gitutil.UntrustedEnviron(os.Environ())
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
+	start := time.Now()
 	err := cmd.Run()
 	exitCode := exitStatus(err)
+	trace.Command(trace.KindShell, cmd, start, exitCode, stdout.String(), stderr.String(), err)
 	return stdout.String(), stderr.String(), exitCode, err
 }
 
-// RunStep executes a single Step and returns the result.
-func RunStep(ctx context.Context, step Step) StepResult {
+// RunStep checks step against policy, executes it, and returns the result.
+// Commands refused by the policy are never started.
+func RunStep(ctx context.Context, policy Policy, step Step) StepResult {
+	return RunStepIn(ctx, "", policy, step)
+}
+
+// RunStepIn is RunStep with the command started in dir rather than the
+// current directory, unless dir is empty.
+func RunStepIn(ctx context.Context, dir string, policy Policy, step Step) StepResult {
+	if err := policy.Check(step.Command); err != nil {
+		return StepResult{
+			Step:      step,
+			ExitCode:  -1,
+			ExecError: err,
+			Failures:  []string{err.Error()},
+		}
+	}
+
+	if StepTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, StepTimeout)
+		defer cancel()
+	}
+
 	start := time.Now()
-	stdout, stderr, exitCode, execErr := runCommand(ctx, step.Command)
+	stdout, stderr, exitCode, execErr := runCommand(ctx, dir, step.Command, policy.Trusted)
 	duration := time.Since(start)
 
 	res := StepResult{
@@ -97,7 +192,9 @@ func RunStep(ctx context.Context, step Step) StepResult {
 	return res
 }
 
-func defaultShell() (string, []string) {
+// DefaultShell returns the shell and leading arguments used to run script
+// commands: PowerShell on Windows and sh elsewhere.
+func DefaultShell() (string, []string) {
 	if runtime.GOOS == "windows" {
 		if _, err := exec.LookPath("pwsh"); err == nil {
 			return "pwsh", []string{"-NoLogo", "-NoProfile", "-Command"}
@@ -2,6 +2,7 @@ package run
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -59,6 +60,70 @@ func TestExecuteFailureOnMissingStdout(t *testing.T) {
 	}
 }
 
+func TestExecuteRunsSetupAndTeardown(t *testing.T) {
+	script := &Script{
+		ID:       "phases",
+		Title:    "Phases",
+		Setup:    []Step{{Command: "echo setup", ExpectStdout: []string{"setup"}}},
+		Steps:    []Step{{Command: "echo check", ExpectStdout: []string{"check"}}},
+		Teardown: []Step{{Command: "echo teardown", ExpectStdout: []string{"teardown"}}},
+	}
+
+	var phases []Phase
+	results, err := Execute(context.Background(), script, func(res StepResult) {
+		phases = append(phases, res.Phase)
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	want := []Phase{PhaseSetup, PhaseCheck, PhaseTeardown}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %d", len(want), len(results))
+	}
+	for i, phase := range want {
+		if phases[i] != phase || results[i].Phase != phase {
+			t.Fatalf("result %d: expected phase %s, got %s", i, phase, results[i].Phase)
+		}
+	}
+}
+
+func TestExecuteRunsTeardownAfterSetupFailure(t *testing.T) {
+	script := &Script{
+		ID:       "broken-setup",
+		Title:    "Broken setup",
+		Setup:    []Step{{Command: "echo setup", ExpectStdout: []string{"missing"}}},
+		Steps:    []Step{{Command: "echo check"}},
+		Teardown: []Step{{Command: "echo teardown"}},
+	}
+
+	results, err := Execute(context.Background(), script, nil)
+	if !errors.Is(err, ErrSetupFailed) {
+		t.Fatalf("expected setup failure, got %v", err)
+	}
+	if len(results) != 2 || results[1].Phase != PhaseTeardown {
+		t.Fatalf("expected setup then teardown results, got %+v", results)
+	}
+}
+
+func TestExecuteRunsTeardownAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	script := &Script{
+		ID:       "cancelled",
+		Title:    "Cancelled",
+		Steps:    []Step{{Command: "echo check"}},
+		Teardown: []Step{{Command: "echo teardown", ExpectStdout: []string{"teardown"}}},
+	}
+
+	results, err := Execute(ctx, script, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if len(results) != 1 || results[0].Phase != PhaseTeardown || !results[0].Passed {
+		t.Fatalf("expected a passing teardown result, got %+v", results)
+	}
+}
+
 func contains(slice []string, target string) bool {
 	for _, item := range slice {
 		if strings.Contains(item, target) {
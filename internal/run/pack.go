@@ -0,0 +1,94 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Pack is a collection of run scripts distributed as a JSON file outside the
+// binary. Packs are untrusted unless the user vouches for them when loading.
+type Pack struct {
+	Name    string    `json:"name"`
+	Policy  *Policy   `json:"policy,omitempty"`
+	Scripts []*Script `json:"scripts"`
+	Trusted bool      `json:"-"`
+	Path    string    `json:"-"`
+}
+
+// LoadPack reads a pack from path. trusted lifts the command allowlist for
+// every script in the pack; otherwise any policy the pack declares may only
+// narrow DefaultPolicy.
+func LoadPack(path string, trusted bool) (*Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("run: read pack: %w", err)
+	}
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("run: parse pack %s: %w", path, err)
+	}
+	if pack.Name == "" {
+		return nil, fmt.Errorf("run: pack %s has no name", path)
+	}
+	pack.Path = path
+	pack.Trusted = trusted
+
+	policy := DefaultPolicy
+	if pack.Policy != nil {
+		policy = DefaultPolicy.restrict(*pack.Policy)
+	}
+	for _, script := range pack.Scripts {
+		if script == nil || script.ID == "" {
+			return nil, fmt.Errorf("run: pack %s contains a script without an ID", pack.Name)
+		}
+		p := policy
+		script.Policy = &p
+		script.Trusted = trusted
+	}
+	return &pack, nil
+}
+
+// RegisterPack adds every script in the pack to the registry. Scripts whose ID
+// is already taken are rejected so a pack cannot shadow a built-in lesson.
+func RegisterPack(pack *Pack) error {
+	if pack == nil {
+		return errors.New("run: pack is nil")
+	}
+	for _, script := range pack.Scripts {
+		if _, exists := registry[script.ID]; exists {
+			return fmt.Errorf("run: pack %s: script %s already registered", pack.Name, script.ID)
+		}
+	}
+	for _, script := range pack.Scripts {
+		Register(script)
+	}
+	return nil
+}
+
+// Lint checks every step of script against its effective policy without
+// executing anything.
+func Lint(script *Script) []Violation {
+	if script == nil {
+		return nil
+	}
+	policy := script.EffectivePolicy()
+	var out []Violation
+	check := func(steps []Step, phase Phase) {
+		for i, step := range steps {
+			err := policy.Check(step.Command)
+			var v *Violation
+			if errors.As(err, &v) {
+				v.ScriptID = script.ID
+				v.Phase = phase
+				v.Step = i + 1
+				out = append(out, *v)
+			}
+		}
+	}
+	check(script.Setup, PhaseSetup)
+	check(script.Steps, PhaseCheck)
+	check(script.Teardown, PhaseTeardown)
+	return out
+}
@@ -0,0 +1,529 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrCommandBlocked is matched by every policy violation returned from Policy.Check.
+var ErrCommandBlocked = errors.New("run: command blocked by policy")
+
+// Policy decides which step commands a script is allowed to execute.
+type Policy struct {
+	// AllowedCommands lists the programs an untrusted script may invoke.
+	AllowedCommands []string `json:"allowedCommands,omitempty"`
+	// AllowedGitSubcommands restricts git invocations. An empty list allows
+	// every subcommand.
+	AllowedGitSubcommands []string `json:"allowedGitSubcommands,omitempty"`
+	// Trusted lifts the allowlist. Destructive commands are blocked regardless.
+	Trusted bool `json:"-"`
+}
+
+// DefaultPolicy applies to every script that does not declare its own.
+var DefaultPolicy = Policy{
+	AllowedCommands: []string{"git", "cat", "ls", "pwd", "echo"},
+	AllowedGitSubcommands: []string{
+		"branch", "cat-file", "config", "describe", "diff", "for-each-ref",
+		"fsck", "log", "ls-files", "ls-tree", "merge-base", "patch-id",
+		"reflog", "remote", "rev-list", "rev-parse", "shortlog", "show",
+		"show-ref", "stash", "status", "tag", "version",
+	},
+}
+
+// Violation describes why a command was refused.
+type Violation struct {
+	ScriptID string
+	Phase    Phase
+	Step     int
+	Command  string
+	Reason   string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by policy: %s", v.Reason)
+}
+
+// Is lets errors.Is match violations against ErrCommandBlocked.
+func (v *Violation) Is(target error) bool {
+	return target == ErrCommandBlocked
+}
+
+// Check parses command and reports the first rule it breaks, if any.
+func (p Policy) Check(command string) error {
+	segments, err := parseCommand(command, p.Trusted)
+	if err != nil {
+		return &Violation{Command: command, Reason: err.Error()}
+	}
+	if strings.Contains(strings.ReplaceAll(command, " ", ""), ":(){") {
+		return &Violation{Command: command, Reason: "fork bombs are never allowed"}
+	}
+
+	for _, seg := range segments {
+		for _, target := range seg.redirects {
+			if outsideRepo(target) {
+				return &Violation{Command: command, Reason: fmt.Sprintf("writes outside the repository are not allowed (%s)", target)}
+			}
+			if inGitDir(target) {
+				return &Violation{Command: command, Reason: fmt.Sprintf("writes into .git are never allowed (%s)", target)}
+			}
+		}
+		if len(seg.words) == 0 {
+			continue
+		}
+		if reason := destructive(seg.words); reason != "" {
+			return &Violation{Command: command, Reason: reason}
+		}
+		if p.Trusted {
+			continue
+		}
+		if reason := p.allowed(seg.words); reason != "" {
+			return &Violation{Command: command, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// restrict narrows p to the programs and git subcommands that other also
+// allows. It lets an untrusted pack tighten the default policy but never
+// widen it.
+func (p Policy) restrict(other Policy) Policy {
+	out := Policy{Trusted: p.Trusted}
+	out.AllowedCommands = intersect(p.AllowedCommands, other.AllowedCommands)
+	switch {
+	case len(p.AllowedGitSubcommands) == 0:
+		out.AllowedGitSubcommands = other.AllowedGitSubcommands
+	case len(other.AllowedGitSubcommands) == 0:
+		out.AllowedGitSubcommands = p.AllowedGitSubcommands
+	default:
+		out.AllowedGitSubcommands = intersect(p.AllowedGitSubcommands, other.AllowedGitSubcommands)
+	}
+	return out
+}
+
+func (p Policy) allowed(words []string) string {
+	if strings.Contains(words[0], "=") {
+		return fmt.Sprintf("environment assignments are not allowed (%s)", words[0])
+	}
+	program := words[0]
+	if !hasString(p.AllowedCommands, program) {
+		return fmt.Sprintf("%s is not in the command allowlist", program)
+	}
+	if program != "git" {
+		return ""
+	}
+	sub, i := gitSubcommand(words[1:])
+	for _, arg := range words[1 : i+1] {
+		if unsafeGitOption(arg) {
+			return fmt.Sprintf("git %s is not allowed in untrusted scripts", arg)
+		}
+	}
+	var args []string
+	if i+1 < len(words) {
+		args = words[i+2:]
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--output") {
+			return fmt.Sprintf("git %s writes a file and is not allowed in untrusted scripts", arg)
+		}
+	}
+	if readOnly, ok := readOnlyForms[sub]; ok && !readOnly(args) {
+		return fmt.Sprintf("git %s may only read the repository in untrusted scripts", sub)
+	}
+	if sub == "" || len(p.AllowedGitSubcommands) == 0 || hasString(p.AllowedGitSubcommands, sub) {
+		return ""
+	}
+	return fmt.Sprintf("git %s is not in the command allowlist", sub)
+}
+
+// gitSubcommand skips git's global options and returns the subcommand name
+// and its index in args, or "" and len(args) when there is none.
+func gitSubcommand(args []string) (string, int) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-c" || arg == "-C" || arg == "--git-dir" || arg == "--work-tree" || arg == "--namespace" || arg == "--config-env" || arg == "--exec-path":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg, i
+		}
+	}
+	return "", len(args)
+}
+
+// unsafeGitOption reports whether a global git option could run another
+// program, through configuration such as core.fsmonitor, or point git at
+// files outside the repository.
+func unsafeGitOption(arg string) bool {
+	if strings.HasPrefix(arg, "-c") || strings.HasPrefix(arg, "-C") {
+		return true
+	}
+	for _, opt := range []string{"--config-env", "--exec-path", "--git-dir", "--work-tree"} {
+		if arg == opt || strings.HasPrefix(arg, opt+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnlyForms holds, for the allowlisted git subcommands that can also
+// change the repository, a test that the arguments after the subcommand only
+// read it.
+var readOnlyForms = map[string]func(args []string) bool{
+	"branch": readOnlyRefList("branch"),
+	"config": readOnlyConfig,
+	"reflog": readOnlyReflog,
+	"remote": readOnlyRemote,
+	"stash":  readOnlyStash,
+	"tag":    readOnlyRefList("tag"),
+}
+
+// refListOptions are the git branch and git tag options that only list refs.
+// listModes are the ones that also make the remaining arguments patterns
+// rather than names to create.
+var (
+	refListOptions = map[string][]string{
+		"branch": {"-a", "--all", "-r", "--remotes", "-v", "-vv", "--verbose", "--show-current", "-i", "--ignore-case", "--no-color", "--no-column"},
+		"tag":    {"-i", "--ignore-case", "--no-color", "--no-column"},
+	}
+	refListValueOptions = []string{"--sort", "--format", "--color", "--column", "--abbrev"}
+	listModes           = []string{"-l", "--list", "--contains", "--no-contains", "--merged", "--no-merged", "--points-at"}
+)
+
+// readOnlyRefList tests git branch or git tag arguments: only listing
+// options, and names only when they are patterns for a listing.
+func readOnlyRefList(sub string) func(args []string) bool {
+	return func(args []string) bool {
+		listing, names := false, false
+		for _, arg := range args {
+			name, _, _ := strings.Cut(arg, "=")
+			switch {
+			case hasString(listModes, name):
+				listing = true
+			case hasString(refListOptions[sub], arg) || hasString(refListValueOptions, name):
+			case sub == "tag" && strings.HasPrefix(arg, "-n"):
+			case strings.HasPrefix(arg, "-"):
+				return false
+			default:
+				names = true
+			}
+		}
+		return listing || !names
+	}
+}
+
+// readOnlyStash accepts git stash list and git stash show; a bare git stash
+// saves the work tree.
+func readOnlyStash(args []string) bool {
+	return len(args) > 0 && (args[0] == "list" || args[0] == "show")
+}
+
+// readOnlyReflog accepts git reflog show and exists; like git, it treats
+// anything else that is not expire, delete or drop as show.
+func readOnlyReflog(args []string) bool {
+	return len(args) == 0 || (args[0] != "expire" && args[0] != "delete" && args[0] != "drop")
+}
+
+// readOnlyRemote accepts listing remotes, git remote show -n and git remote
+// get-url.
+func readOnlyRemote(args []string) bool {
+	if len(args) == 0 || args[0] == "-v" || args[0] == "--verbose" {
+		return len(args) <= 1
+	}
+	return args[0] == "get-url" || (args[0] == "show" && hasString(args, "-n"))
+}
+
+// readOnlyConfig reports whether git config args only read settings: the
+// get and list subcommands or the equivalent --get and --list options.
+func readOnlyConfig(args []string) bool {
+	if len(args) > 0 && (args[0] == "get" || args[0] == "list") {
+		return true
+	}
+	reads := false
+	for _, arg := range args {
+		switch arg {
+		case "--get", "--get-all", "--get-regexp", "--get-urlmatch", "--list", "-l":
+			reads = true
+		case "-e", "--edit", "--add", "--unset", "--unset-all", "--replace-all", "--rename-section", "--remove-section":
+			return false
+		}
+	}
+	return reads
+}
+
+var (
+	blockedPrograms = []string{
+		"mkfs", "dd", "shutdown", "reboot", "halt", "poweroff", "format", "diskpart",
+		"sudo", "su", "doas", "Format-Volume", "Stop-Computer", "Restart-Computer",
+	}
+	// wrapperPrograms run another command the policy cannot see, so even
+	// trusted scripts may not use them.
+	wrapperPrograms = []string{
+		"sh", "bash", "zsh", "dash", "ksh", "fish", "csh", "tcsh", "busybox",
+		"cmd", "powershell", "pwsh", "Invoke-Expression", "iex", "Start-Process",
+		"env", "xargs", "eval", "exec", "source", ".", "command", "builtin",
+		"nohup", "nice", "time", "timeout", "watch", "script", "parallel",
+	}
+	findActions    = []string{"-exec", "-execdir", "-ok", "-okdir", "-delete"}
+	removePrograms = []string{"rm", "rmdir", "del", "rd", "Remove-Item", "ri"}
+	writePrograms  = []string{
+		"cp", "mv", "tee", "touch", "mkdir", "ln", "chmod", "chown", "install",
+		"Set-Content", "Add-Content", "Out-File", "New-Item", "Copy-Item", "Move-Item",
+	}
+)
+
+func destructive(words []string) string {
+	program := words[0]
+	if strings.HasPrefix(program, "(") || strings.HasPrefix(program, "{") {
+		return "subshells and brace groups are never allowed"
+	}
+	if i := strings.LastIndexAny(program, `/\`); i >= 0 {
+		program = program[i+1:]
+	}
+	if strings.HasPrefix(program, "mkfs") || hasStringFold(blockedPrograms, program) {
+		return fmt.Sprintf("%s is never allowed", program)
+	}
+	if hasStringFold(wrapperPrograms, program) {
+		return fmt.Sprintf("%s runs commands the policy cannot check and is never allowed", program)
+	}
+	switch {
+	case program == "find":
+		for _, arg := range words[1:] {
+			if hasString(findActions, arg) {
+				return fmt.Sprintf("find %s is never allowed", arg)
+			}
+		}
+	case hasStringFold(removePrograms, program):
+		for _, arg := range operands(words[1:]) {
+			clean := path.Clean(strings.ReplaceAll(arg, `\`, "/"))
+			if outsideRepo(arg) || clean == "." || clean == "*" || inGitDir(arg) {
+				return fmt.Sprintf("%s %s would delete files outside the working tree or the repository itself", program, arg)
+			}
+		}
+	case hasStringFold(writePrograms, program):
+		for _, arg := range operands(words[1:]) {
+			if outsideRepo(arg) {
+				return fmt.Sprintf("writes outside the repository are not allowed (%s)", arg)
+			}
+			if inGitDir(arg) {
+				return fmt.Sprintf("writes into .git are never allowed (%s)", arg)
+			}
+		}
+	}
+	return ""
+}
+
+func operands(args []string) []string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+// inGitDir reports whether p names the .git directory or something inside
+// it, where a written file can become configuration or a hook git runs.
+func inGitDir(p string) bool {
+	clean := path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	first, _, _ := strings.Cut(clean, "/")
+	return strings.EqualFold(first, ".git")
+}
+
+// outsideRepo reports whether p may point outside the working directory.
+// Paths relying on variable expansion are treated as outside because they
+// cannot be resolved statically.
+func outsideRepo(p string) bool {
+	switch strings.ToLower(p) {
+	case "/dev/null", "nul", "$null":
+		return false
+	}
+	if p == "" {
+		return false
+	}
+	if strings.ContainsAny(p, "$%~") || strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) {
+		return true
+	}
+	if len(p) >= 2 && p[1] == ':' {
+		return true
+	}
+	clean := path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+type segment struct {
+	words     []string
+	redirects []string
+}
+
+// parseCommand splits a shell command into pipeline segments, collecting the
+// words and output redirect targets of each. Command substitution is only
+// tolerated when trusted is set because its contents cannot be checked.
+func parseCommand(command string, trusted bool) ([]segment, error) {
+	var (
+		segments []segment
+		cur      segment
+		word     strings.Builder
+		inWord   bool
+		quote    rune
+		redirect bool
+	)
+
+	flushWord := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		word.Reset()
+		inWord = false
+		if redirect {
+			cur.redirects = append(cur.redirects, w)
+			redirect = false
+			return
+		}
+		cur.words = append(cur.words, w)
+	}
+	flushSegment := func() {
+		flushWord()
+		if len(cur.words) > 0 || len(cur.redirects) > 0 {
+			segments = append(segments, cur)
+		}
+		cur = segment{}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if quote == '\'' {
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+			continue
+		}
+
+		if (r == '$' && i+1 < len(runes) && runes[i+1] == '(') || r == '`' {
+			if !trusted {
+				return nil, errors.New("command substitution is not allowed")
+			}
+		}
+
+		if quote == '"' {
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+			continue
+		}
+
+		switch r {
+		case '\'', '"':
+			quote = r
+			inWord = true
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case ' ', '\t':
+			flushWord()
+		case ';', '\n', '|', '&':
+			if r == '&' && i+1 < len(runes) && runes[i+1] == '>' {
+				flushWord()
+				redirect = true
+				i++
+				if i+1 < len(runes) && runes[i+1] == '>' {
+					i++
+				}
+				continue
+			}
+			flushSegment()
+			if (r == '|' || r == '&') && i+1 < len(runes) && runes[i+1] == r {
+				i++
+			}
+		case '>':
+			if inWord && isDigits(word.String()) {
+				word.Reset()
+				inWord = false
+			}
+			flushWord()
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				i++
+			}
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				// Duplicating a descriptor (2>&1) does not touch the filesystem.
+				i++
+				for i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9' {
+					i++
+				}
+				continue
+			}
+			redirect = true
+		case '<':
+			flushWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if redirect && !inWord {
+		return nil, errors.New("redirect without a target")
+	}
+	flushSegment()
+	return segments, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func intersect(a, b []string) []string {
+	out := make([]string, 0, len(a))
+	for _, item := range a {
+		if hasString(b, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func hasString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStringFold(list []string, target string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	cases := []struct {
+		name    string
+		command string
+		trusted bool
+		blocked bool
+	}{
+		{name: "echo", command: `echo "naad karti kay?"`},
+		{name: "git log", command: "git --no-pager log -n 1 --pretty=%s"},
+		{name: "git config", command: "git config get --global user.name"},
+		{name: "redirect in repo", command: "git cat-file -p HEAD > catfileout.txt"},
+		{name: "stderr dup", command: "git status 2>&1"},
+		{name: "not allowlisted", command: "curl https://example.com", blocked: true},
+		{name: "not allowlisted in pipeline", command: "git log | sh", blocked: true},
+		{name: "git write subcommand", command: "git reset --hard HEAD~1", blocked: true},
+		{name: "substitution", command: "echo $(whoami)", blocked: true},
+		{name: "rm root", command: "rm -rf /", trusted: true, blocked: true},
+		{name: "rm home", command: "rm -rf ~", trusted: true, blocked: true},
+		{name: "rm parent", command: "rm -r ../other", trusted: true, blocked: true},
+		{name: "rm git dir", command: "rm -rf .git", trusted: true, blocked: true},
+		{name: "write outside", command: "echo hi > /etc/motd", trusted: true, blocked: true},
+		{name: "append outside", command: "echo hi >> ../notes.txt", trusted: true, blocked: true},
+		{name: "copy outside", command: "cp notes.txt /tmp/x", trusted: true, blocked: true},
+		{name: "sudo", command: "sudo ls", trusted: true, blocked: true},
+		{name: "trusted rm in repo", command: "rm -f catfileout.txt", trusted: true},
+		{name: "trusted curl", command: "curl https://example.com", trusted: true},
+		{name: "dev null", command: "git status > /dev/null", trusted: true},
+		{name: "git config legacy get", command: "git config --get user.name"},
+		{name: "git config list", command: "git config list --global"},
+		{name: "git config set", command: "git config core.fsmonitor 'touch pwned'", blocked: true},
+		{name: "git config set subcommand", command: "git config set core.fsmonitor x", blocked: true},
+		{name: "git config unset", command: "git config --get x --unset y", blocked: true},
+		{name: "git -c", command: "git -c core.fsmonitor='sh -c id' status", blocked: true},
+		{name: "git -c glued", command: "git -ccore.pager=id log", blocked: true},
+		{name: "git -C", command: "git -C / status", blocked: true},
+		{name: "git --git-dir", command: "git --git-dir=/tmp/other.git log", blocked: true},
+		{name: "git --exec-path", command: "git --exec-path=. status", blocked: true},
+		{name: "git --work-tree", command: "git --work-tree / status", blocked: true},
+		{name: "subshell", command: "(rm -rf /)", trusted: true, blocked: true},
+		{name: "brace group", command: "{ rm -rf /; }", trusted: true, blocked: true},
+		{name: "sh -c", command: "sh -c 'rm -rf /'", trusted: true, blocked: true},
+		{name: "bash by path", command: "/bin/bash -c 'rm -rf /'", trusted: true, blocked: true},
+		{name: "env wrapper", command: "env rm -rf /", trusted: true, blocked: true},
+		{name: "xargs", command: "ls | xargs rm", trusted: true, blocked: true},
+		{name: "find delete", command: "find / -delete", trusted: true, blocked: true},
+		{name: "find exec", command: "find . -exec rm {} +", trusted: true, blocked: true},
+		{name: "trusted find", command: "find . -name '*.md'", trusted: true},
+		{name: "append git config", command: "echo '[core] fsmonitor = id' >> .git/config", blocked: true},
+		{name: "write hook", command: "echo id > .git/hooks/pre-commit", blocked: true},
+		{name: "write hook via dot", command: "echo id > ./.git/../.git/hooks/pre-commit", blocked: true},
+		{name: "trusted copy into git dir", command: "cp hook .git/hooks/pre-commit", trusted: true, blocked: true},
+		{name: "git log output", command: "git log --output=/tmp/x", blocked: true},
+		{name: "git diff output", command: "git diff --output=../x", blocked: true},
+		{name: "git show output", command: "git show --output x", blocked: true},
+		{name: "git ls-files others", command: "git ls-files -o"},
+		{name: "git stash clear", command: "git stash clear", blocked: true},
+		{name: "git stash push", command: "git stash", blocked: true},
+		{name: "git stash list", command: "git stash list"},
+		{name: "git branch delete", command: "git branch -D main", blocked: true},
+		{name: "git branch create", command: "git branch feature", blocked: true},
+		{name: "git branch list", command: "git branch -a --sort=-committerdate"},
+		{name: "git branch pattern", command: "git branch --list 'feat*'"},
+		{name: "git tag delete", command: "git tag -d v1", blocked: true},
+		{name: "git tag create", command: "git tag v1", blocked: true},
+		{name: "git tag list", command: "git tag -l 'v*' -n"},
+		{name: "git reflog expire", command: "git reflog expire --expire=now --all", blocked: true},
+		{name: "git reflog delete", command: "git reflog delete HEAD@{1}", blocked: true},
+		{name: "git reflog", command: "git reflog -n 5"},
+		{name: "git reflog show", command: "git reflog show main"},
+		{name: "git remote add", command: "git remote add x ext::sh", blocked: true},
+		{name: "git remote set-url", command: "git remote set-url origin ext::sh", blocked: true},
+		{name: "git remote list", command: "git remote -v"},
+		{name: "git remote get-url", command: "git remote get-url origin"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := DefaultPolicy
+			policy.Trusted = tc.trusted
+			err := policy.Check(tc.command)
+			if tc.blocked && !errors.Is(err, ErrCommandBlocked) {
+				t.Fatalf("expected %q to be blocked, got %v", tc.command, err)
+			}
+			if !tc.blocked && err != nil {
+				t.Fatalf("expected %q to be allowed, got %v", tc.command, err)
+			}
+		})
+	}
+}
+
+func TestBuiltinScriptsPassLint(t *testing.T) {
+	for _, script := range List() {
+		if violations := Lint(script); len(violations) > 0 {
+			t.Errorf("script %s: %+v", script.ID, violations)
+		}
+	}
+}
+
+func TestRunStepRefusesBlockedCommand(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	res := RunStep(context.Background(), DefaultPolicy, Step{Command: "touch " + marker})
+	if res.Passed {
+		t.Fatalf("expected blocked step to fail")
+	}
+	if !errors.Is(res.ExecError, ErrCommandBlocked) {
+		t.Fatalf("expected policy error, got %v", res.ExecError)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("blocked command was executed")
+	}
+}
+
+func TestLoadPackCannotWidenPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pack.json")
+	data := `{
+  "name": "extra",
+  "policy": {"allowedCommands": ["git", "curl"]},
+  "scripts": [
+    {"id": "x1", "title": "Fetch", "steps": [{"command": "curl https://example.com"}, {"command": "git status"}]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pack, err := LoadPack(path, false)
+	if err != nil {
+		t.Fatalf("LoadPack: %v", err)
+	}
+	violations := Lint(pack.Scripts[0])
+	if len(violations) != 1 || violations[0].Step != 1 {
+		t.Fatalf("expected curl step to be flagged, got %+v", violations)
+	}
+
+	trusted, err := LoadPack(path, true)
+	if err != nil {
+		t.Fatalf("LoadPack trusted: %v", err)
+	}
+	if violations := Lint(trusted.Scripts[0]); len(violations) != 0 {
+		t.Fatalf("expected trusted pack to pass, got %+v", violations)
+	}
+}
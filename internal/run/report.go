@@ -0,0 +1,91 @@
+package run
+
+import (
+	"time"
+)
+
+// Score summarises the graded steps of a run. Every step is worth one point.
+type Score struct {
+	Points  int     `json:"points"`
+	Max     int     `json:"max"`
+	Percent float64 `json:"percent"`
+}
+
+// Grade scores the check-phase results of script. Setup and teardown steps
+// are ignored.
+func Grade(script *Script, results []StepResult) Score {
+	var score Score
+	if script == nil {
+		return score
+	}
+	score.Max = len(script.Steps)
+	for _, res := range results {
+		if res.Phase != PhaseSetup && res.Phase != PhaseTeardown && res.Passed {
+			score.Points++
+		}
+	}
+	if score.Max > 0 {
+		score.Percent = float64(score.Points) * 100 / float64(score.Max)
+	} else {
+		score.Percent = 100
+	}
+	return score
+}
+
+// AllPassed reports whether every graded step of script has a passing result.
+func AllPassed(script *Script, results []StepResult) bool {
+	score := Grade(script, results)
+	return score.Points == score.Max
+}
+
+// Report is the machine-readable summary of a run.
+type Report struct {
+	Script string       `json:"script"`
+	Title  string       `json:"title"`
+	Passed bool         `json:"passed"`
+	Score  Score        `json:"score"`
+	Steps  []StepReport `json:"steps"`
+}
+
+// StepReport describes a single executed step within a Report.
+type StepReport struct {
+	Command    string   `json:"command"`
+	Phase      Phase    `json:"phase"`
+	Passed     bool     `json:"passed"`
+	ExitCode   int      `json:"exitCode"`
+	Failures   []string `json:"failures,omitempty"`
+	Stdout     string   `json:"stdout,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+}
+
+// maxReportOutput caps how much stdout is copied into a StepReport.
+const maxReportOutput = 4096
+
+// NewReport builds a Report from the results of running script.
+func NewReport(script *Script, results []StepResult) Report {
+	report := Report{
+		Passed: AllPassed(script, results),
+		Score:  Grade(script, results),
+		Steps:  make([]StepReport, 0, len(results)),
+	}
+	if script != nil {
+		report.Script = script.ID
+		report.Title = script.Title
+	}
+	for _, res := range results {
+		stdout := res.Stdout
+		if len(stdout) > maxReportOutput {
+			stdout = stdout[:maxReportOutput]
+		}
+		report.Steps = append(report.Steps, StepReport{
+			Command:    res.Step.Command,
+			Phase:      res.Phase,
+			Passed:     res.Passed,
+			ExitCode:   res.ExitCode,
+			Failures:   res.Failures,
+			Stdout:     stdout,
+			DurationMS: res.Duration.Round(time.Millisecond).Milliseconds(),
+		})
+	}
+	return report
+}
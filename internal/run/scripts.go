@@ -6,17 +6,39 @@ import (
 
 // Step describes a single command invocation within a run lesson.
 type Step struct {
-	Command        string
-	ExpectExitCode int
-	ExpectStdout   []string
+	Command        string   `json:"command"`
+	ExpectExitCode int      `json:"expectExitCode"`
+	ExpectStdout   []string `json:"expectStdout,omitempty"`
 }
 
 // Script represents a scripted exercise composed of multiple steps.
 type Script struct {
-	ID          string
-	Title       string
-	Description string
-	Steps       []Step
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Steps       []Step `json:"steps"`
+	// Setup steps prepare fixtures before the graded steps run. Teardown
+	// steps always run afterwards, even when setup fails or the run is
+	// cancelled. Neither counts towards the result.
+	Setup    []Step `json:"setup,omitempty"`
+	Teardown []Step `json:"teardown,omitempty"`
+	// Trusted lifts the command allowlist for this script. Destructive
+	// commands are still refused.
+	Trusted bool `json:"-"`
+	// Policy overrides DefaultPolicy when set.
+	Policy *Policy `json:"-"`
+}
+
+// EffectivePolicy returns the policy enforced for the script's steps.
+func (s *Script) EffectivePolicy() Policy {
+	policy := DefaultPolicy
+	if s.Policy != nil {
+		policy = *s.Policy
+	}
+	if s.Trusted {
+		policy.Trusted = true
+	}
+	return policy
 }
 
 var (
@@ -132,9 +154,9 @@ func init() {
 		Description: "Ensure contents.md exists with expected content before staging.",
 		Steps: []Step{
 			{
-				Command:        "git status",
+				Command:        "git status --porcelain",
 				ExpectExitCode: 0,
-				ExpectStdout:   []string{"Untracked files", "contents.md"},
+				ExpectStdout:   []string{"?? contents.md"},
 			},
 			{
 				Command:        "cat contents.md",
@@ -150,9 +172,11 @@ func init() {
 		Description: "Verify contents.md is staged prior to commit.",
 		Steps: []Step{
 			{
-				Command:        "git status",
+				// Only the index side: further edits to the working tree
+				// don't unstage the file.
+				Command:        "git diff --cached --name-status",
 				ExpectExitCode: 0,
-				ExpectStdout:   []string{"Changes to be committed", "new file:", "contents.md"},
+				ExpectStdout:   []string{"A\tcontents.md"},
 			},
 		},
 	})
@@ -173,7 +197,11 @@ func init() {
 	Register(&Script{
 		ID:          "6a",
 		Title:       "Inspect commit metadata",
-		Description: "Review the temporary catfileout.txt output from git cat-file.",
+		Description: "Review the commit metadata git cat-file writes to a temporary catfileout.txt.",
+		// Trusted so teardown may remove the file setup generated.
+		Trusted:  true,
+		Setup:    []Step{{Command: "git cat-file -p HEAD > catfileout.txt"}},
+		Teardown: []Step{{Command: "rm catfileout.txt"}},
 		Steps: []Step{
 			{
 				Command:        "cat catfileout.txt",
@@ -186,7 +214,10 @@ func init() {
 	Register(&Script{
 		ID:          "6b",
 		Title:       "Inspect blob contents",
-		Description: "Validate blobfile.txt captures blob output.",
+		Description: "Validate the blob output git cat-file writes to a temporary blobfile.txt.",
+		Trusted:     true,
+		Setup:       []Step{{Command: "git cat-file -p HEAD:contents.md > blobfile.txt"}},
+		Teardown:    []Step{{Command: "rm blobfile.txt"}},
 		Steps: []Step{
 			{
 				Command:        "cat blobfile.txt",
@@ -394,7 +425,7 @@ func init() {
 				ExpectStdout:   []string{"I: add fear quote", "H: add spice quote", "E: merge add_classics"},
 			},
 			{
-				Command:        "git status --short",
+				Command:        "git status --porcelain",
 				ExpectExitCode: 0,
 				ExpectStdout:   []string{"M  titles.md"},
 			},
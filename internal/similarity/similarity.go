@@ -0,0 +1,239 @@
+// Package similarity flags student repositories that look copied from one
+// another. Each repository is reduced to a fingerprint of its history and
+// files, and every pair of fingerprints is scored by what they share.
+//
+// Evidence shared by most of the class, such as a starter repository's root
+// commit or a commit message the lesson prescribes, says nothing about
+// copying and is ignored.
+package similarity
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+// Signal names a kind of evidence.
+type Signal string
+
+const (
+	// SignalRoot is a shared root commit: one history was cloned from the
+	// other, or both from the same source.
+	SignalRoot Signal = "root-commit"
+	// SignalCommit is a commit with the same message and author time.
+	SignalCommit Signal = "commit"
+	// SignalTree is a commit whose tree, the full snapshot of its files, is
+	// identical.
+	SignalTree Signal = "tree"
+	// SignalBlob is a file at HEAD with identical contents.
+	SignalBlob Signal = "blob"
+	// SignalMessage is an identical commit message at any time.
+	SignalMessage Signal = "message"
+)
+
+// weights is how strongly a full overlap of each signal suggests copying.
+var weights = map[Signal]float64{
+	SignalRoot:    0.9,
+	SignalCommit:  0.9,
+	SignalTree:    0.8,
+	SignalBlob:    0.6,
+	SignalMessage: 0.3,
+}
+
+// signals lists the signals in the order evidence is reported.
+var signals = []Signal{SignalRoot, SignalCommit, SignalTree, SignalBlob, SignalMessage}
+
+// emptyObjects are the empty tree and blob in SHA-1 and SHA-256
+// repositories; every repository can produce them independently.
+var emptyObjects = map[string]bool{
+	"4b825dc642cb6eb9a060e54bf8d69288fbee4904":                         true,
+	"6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321": true,
+	"e69de29bb2d1d6434b8b29ae775ad8c2e48c5391":                         true,
+	"473a0f4c3be8a93681a267e3b1e9a7dcda1185436fe141f7749120a303721813": true,
+}
+
+// Fingerprint holds, for each signal, the keys a repository has, mapped to
+// a label that shows an instructor what matched.
+type Fingerprint map[Signal]map[string]string
+
+// Collect fingerprints repo from every commit reachable from its refs and
+// the files at HEAD.
+func Collect(ctx context.Context, repo *gitutil.Repository) (Fingerprint, error) {
+	fp := Fingerprint{}
+	for _, s := range signals {
+		fp[s] = map[string]string{}
+	}
+	commits, err := repo.Log(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("similarity: %w", err)
+	}
+	for _, c := range commits {
+		label := fmt.Sprintf("%.7s %q", c.Hash, c.Subject)
+		if len(c.Parents) == 0 {
+			fp[SignalRoot][c.Hash] = label
+		}
+		if !emptyObjects[c.Tree] {
+			fp[SignalTree][c.Tree] = label
+		}
+		fp[SignalCommit][c.Subject+"\x00"+c.AuthorTime.Format(time.RFC3339)] = fmt.Sprintf("%q at %s", c.Subject, c.AuthorTime.Format(time.RFC3339))
+		fp[SignalMessage][c.Subject] = fmt.Sprintf("%q", c.Subject)
+	}
+	if len(commits) == 0 {
+		return fp, nil
+	}
+	head, err := repo.Head(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("similarity: %w", err)
+	}
+	if head.Hash == "" {
+		return fp, nil
+	}
+	files, err := repo.Files(ctx, head.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("similarity: %w", err)
+	}
+	for _, f := range files {
+		if f.Size > 0 && !emptyObjects[f.Hash] {
+			fp[SignalBlob][f.Hash] = f.Path
+		}
+	}
+	return fp, nil
+}
+
+// Repo is a fingerprinted repository.
+type Repo struct {
+	Name        string
+	Fingerprint Fingerprint
+}
+
+// Evidence is what a pair shares for one signal. Of is the size of the
+// smaller of the two sets, so Shared/Of is the overlap.
+type Evidence struct {
+	Signal   Signal   `json:"signal"`
+	Shared   int      `json:"shared"`
+	Of       int      `json:"of"`
+	Examples []string `json:"examples"`
+}
+
+// Pair is two repositories and how alike they are. Score runs from 0 to
+// 100.
+type Pair struct {
+	A        string     `json:"a"`
+	B        string     `json:"b"`
+	Score    float64    `json:"score"`
+	Evidence []Evidence `json:"evidence"`
+}
+
+// Report ranks every pair that shares anything, most similar first.
+type Report struct {
+	Compared int `json:"compared"`
+	// Ignored lists, per signal, how many keys were too common in the
+	// class to count.
+	Ignored map[Signal]int `json:"ignored"`
+	Pairs   []Pair         `json:"pairs"`
+}
+
+// Likely is the score from which a pair is reported as a likely copy.
+const Likely = 50
+
+// maxExamples bounds the examples reported per signal.
+const maxExamples = 3
+
+// Compare scores every pair of repos. A key held by more than half of the
+// repositories, and by more than two, is ignored as class-wide.
+func Compare(repos []Repo) Report {
+	report := Report{Compared: len(repos), Ignored: map[Signal]int{}, Pairs: []Pair{}}
+	limit := max(2, len(repos)/2)
+	common := map[Signal]map[string]bool{}
+	for _, s := range signals {
+		counts := map[string]int{}
+		for _, r := range repos {
+			for key := range r.Fingerprint[s] {
+				counts[key]++
+			}
+		}
+		common[s] = map[string]bool{}
+		for key, n := range counts {
+			if n > limit {
+				common[s][key] = true
+			}
+		}
+		if n := len(common[s]); n > 0 {
+			report.Ignored[s] = n
+		}
+	}
+
+	for i := range repos {
+		for j := i + 1; j < len(repos); j++ {
+			if p, ok := comparePair(repos[i], repos[j], common); ok {
+				report.Pairs = append(report.Pairs, p)
+			}
+		}
+	}
+	slices.SortStableFunc(report.Pairs, func(a, b Pair) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return report
+}
+
+func comparePair(a, b Repo, common map[Signal]map[string]bool) (Pair, bool) {
+	p := Pair{A: a.Name, B: b.Name, Evidence: []Evidence{}}
+	unlikely := 1.0
+	for _, s := range signals {
+		as, bs := a.Fingerprint[s], b.Fingerprint[s]
+		var shared []string
+		for _, key := range slices.Sorted(maps.Keys(as)) {
+			if _, ok := bs[key]; ok && !common[s][key] {
+				shared = append(shared, key)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		ev := Evidence{Signal: s, Shared: len(shared), Of: min(len(as), len(bs))}
+		for _, key := range shared[:min(len(shared), maxExamples)] {
+			ev.Examples = append(ev.Examples, as[key])
+		}
+		overlap := float64(ev.Shared) / float64(ev.Of)
+		if s == SignalRoot {
+			// One shared root already means a common history.
+			overlap = 1
+		}
+		unlikely *= 1 - weights[s]*overlap
+		p.Evidence = append(p.Evidence, ev)
+	}
+	if len(p.Evidence) == 0 {
+		return p, false
+	}
+	p.Score = math.Round((1-unlikely)*1000) / 10
+	return p, true
+}
+
+// Describe summarises a pair's evidence on one line.
+func (p Pair) Describe() string {
+	parts := make([]string, 0, len(p.Evidence))
+	for _, ev := range p.Evidence {
+		parts = append(parts, fmt.Sprintf("%d/%d %s", ev.Shared, ev.Of, describeSignal[ev.Signal]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+var describeSignal = map[Signal]string{
+	SignalRoot:    "root commits",
+	SignalCommit:  "commits with the same message and time",
+	SignalTree:    "identical snapshots",
+	SignalBlob:    "identical files",
+	SignalMessage: "commit messages",
+}
@@ -0,0 +1,129 @@
+package similarity
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+func git(t *testing.T, dir, date string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=S", "-c", "user.email=s@x"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+}
+
+func commitFile(t *testing.T, dir, name, content, message, date string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, date, "add", name)
+	git(t, dir, date, "commit", "--quiet", "-m", message)
+}
+
+func fingerprint(t *testing.T, dir string) Fingerprint {
+	t.Helper()
+	repo, err := gitutil.Open(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := Collect(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
+
+func TestCompareRanksCopies(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	class := t.TempDir()
+	alice, carol, dave := filepath.Join(class, "alice"), filepath.Join(class, "carol"), filepath.Join(class, "dave")
+	eve, frank := filepath.Join(class, "eve"), filepath.Join(class, "frank")
+	for _, dir := range []string{alice, carol, dave, eve, frank} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		git(t, dir, "", "init", "--quiet")
+	}
+	commitFile(t, alice, "README.md", "# Alice's notes on git\n", "Add readme", "2026-03-01T10:00:00Z")
+	commitFile(t, alice, "notes.txt", "staging is a draft of the next commit\n", "Add notes", "2026-03-01T10:05:00Z")
+	// bob cloned alice's repository and added one commit of his own.
+	bob := filepath.Join(class, "bob")
+	git(t, class, "", "clone", "--quiet", alice, bob)
+	commitFile(t, bob, "bob.txt", "mine\n", "Add bob", "2026-03-02T09:00:00Z")
+	// carol retyped alice's README in a history of her own.
+	commitFile(t, carol, "README.md", "# Alice's notes on git\n", "Add readme", "2026-03-03T11:00:00Z")
+	commitFile(t, dave, "README.md", "# Dave\n", "Start", "2026-03-04T12:00:00Z")
+	commitFile(t, dave, "empty.txt", "", "Add an empty file", "2026-03-04T12:01:00Z")
+	commitFile(t, eve, "README.md", "# Eve\n", "Add readme", "2026-03-05T08:00:00Z")
+	commitFile(t, frank, "README.md", "# Frank\n", "First commit", "2026-03-05T08:30:00Z")
+
+	repos := []Repo{
+		{Name: "alice", Fingerprint: fingerprint(t, alice)},
+		{Name: "bob", Fingerprint: fingerprint(t, bob)},
+		{Name: "carol", Fingerprint: fingerprint(t, carol)},
+		{Name: "dave", Fingerprint: fingerprint(t, dave)},
+		{Name: "eve", Fingerprint: fingerprint(t, eve)},
+		{Name: "frank", Fingerprint: fingerprint(t, frank)},
+	}
+	report := Compare(repos)
+	if report.Compared != 6 || len(report.Pairs) < 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	top := report.Pairs[0]
+	if top.A != "alice" || top.B != "bob" || top.Score < 95 || top.Evidence[0].Signal != SignalRoot {
+		t.Fatalf("alice and bob should rank first on their shared root, got %+v", top)
+	}
+	if !strings.Contains(top.Describe(), "2/2 commits with the same message and time") {
+		t.Fatalf("describe = %s", top.Describe())
+	}
+	for _, p := range report.Pairs {
+		if p.A == "dave" || p.B == "dave" {
+			t.Fatalf("dave shares nothing but an empty file: %+v", p)
+		}
+		if p.A == "alice" && p.B == "eve" && (p.Score > 10 || p.Evidence[0].Signal != SignalMessage) {
+			t.Fatalf("alice and eve only share a commit message: %+v", p)
+		}
+		// carol's only snapshot matches alice's first one exactly, but their
+		// histories are unrelated.
+		if p.A == "alice" && p.B == "carol" {
+			if p.Score >= top.Score || len(p.Evidence) != 2 || p.Evidence[0].Signal != SignalTree || p.Evidence[1].Examples[0] != "README.md" {
+				t.Fatalf("alice and carol share a snapshot and a file: %+v", p)
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresClassWideKeys(t *testing.T) {
+	fp := func(messages ...string) Fingerprint {
+		f := Fingerprint{SignalMessage: map[string]string{}}
+		for _, m := range messages {
+			f[SignalMessage][m] = m
+		}
+		return f
+	}
+	repos := []Repo{
+		{Name: "a", Fingerprint: fp("Initial commit", "fix typo in readme")},
+		{Name: "b", Fingerprint: fp("Initial commit", "fix typo in readme")},
+		{Name: "c", Fingerprint: fp("Initial commit")},
+		{Name: "d", Fingerprint: fp("Initial commit")},
+	}
+	report := Compare(repos)
+	if report.Ignored[SignalMessage] != 1 || len(report.Pairs) != 1 {
+		t.Fatalf("the prescribed message should be ignored: %+v", report)
+	}
+	if p := report.Pairs[0]; p.A != "a" || p.B != "b" || p.Evidence[0].Shared != 1 || p.Score != 15 {
+		t.Fatalf("unexpected pair %+v", p)
+	}
+}
@@ -0,0 +1,311 @@
+// Package submit packages a student's repository for offline grading: a git
+// bundle of every ref, snapshots of the reflogs, the student's latest
+// results and the environment diagnostics, in one gzipped tar file.
+// Instructors restore submissions with Extract and verify them like any
+// other repository.
+package submit
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/doctor"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/progress"
+)
+
+// Version is the submission format version.
+const Version = 1
+
+// Ext is the file extension of submission files.
+const Ext = ".tscgit"
+
+// Archive entries.
+const (
+	entryManifest = "manifest.json"
+	entryBundle   = "repo.bundle"
+	entryResults  = "results.json"
+	entryDoctor   = "doctor.json"
+	reflogPrefix  = "reflogs/"
+)
+
+// maxEntry bounds the size of any one archive entry, so a corrupt or
+// hostile file cannot exhaust memory when it is opened.
+const maxEntry = 512 << 20
+
+// Manifest describes a submission.
+type Manifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ToolVersion string    `json:"toolVersion"`
+	// Student and Email come from the repository's git identity.
+	Student string `json:"student"`
+	Email   string `json:"email"`
+	// Branch is the branch HEAD was attached to, empty when detached.
+	Branch string `json:"branch,omitempty"`
+	Head   string `json:"head"`
+	// Dirty is set when the working tree had changes that were not
+	// committed and so are not part of the bundle.
+	Dirty bool `json:"dirty"`
+}
+
+// Submission is the content of a submission file.
+type Submission struct {
+	Manifest Manifest
+	// Results are the student's latest lesson and script records.
+	Results []progress.Record
+	// Doctor is the environment report from the student's machine.
+	Doctor doctor.Report
+	// Reflogs maps ref names, such as HEAD or refs/heads/main, to their
+	// reflogs in the format of a .git/logs file, read through git so any
+	// ref storage works.
+	Reflogs map[string][]byte
+	bundle  []byte
+}
+
+// Info is what Create records besides the repository itself.
+type Info struct {
+	ToolVersion string
+	Results     []progress.Record
+	Doctor      doctor.Report
+	Now         time.Time
+}
+
+// Create writes a submission of repo to w.
+func Create(ctx context.Context, repo *gitutil.Repository, info Info, w io.Writer) (*Manifest, error) {
+	head, err := repo.Head(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	if head.Hash == "" {
+		return nil, fmt.Errorf("submit: %w; commit your work first", gitutil.ErrNoCommits)
+	}
+	status, err := repo.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	name, _, _ := repo.Config(ctx, "user.name")
+	email, _, _ := repo.Config(ctx, "user.email")
+	manifest := Manifest{
+		Version:     Version,
+		CreatedAt:   info.Now.UTC().Truncate(time.Second),
+		ToolVersion: info.ToolVersion,
+		Student:     name,
+		Email:       email,
+		Branch:      head.Branch,
+		Head:        head.Hash,
+		Dirty:       !status.Clean(),
+	}
+
+	tmp, err := os.MkdirTemp("", "tscgit-submit-")
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	bundlePath := filepath.Join(tmp, entryBundle)
+	if err := repo.CreateBundle(ctx, bundlePath); err != nil {
+		return nil, fmt.Errorf("submit: create bundle: %w", err)
+	}
+	bundle, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	logs, err := repo.Reflogs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("submit: read reflogs: %w", err)
+	}
+	reflogs := make(map[string][]byte, len(logs))
+	for ref, log := range logs {
+		reflogs[ref] = gitutil.FormatReflog(log)
+	}
+
+	results := info.Results
+	if results == nil {
+		results = []progress.Record{}
+	}
+	s := &Submission{Manifest: manifest, Results: results, Doctor: info.Doctor, Reflogs: reflogs, bundle: bundle}
+	if err := s.write(w); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func (s *Submission) write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: s.Manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	addJSON := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return add(name, append(data, '\n'))
+	}
+
+	err := addJSON(entryManifest, s.Manifest)
+	if err == nil {
+		err = add(entryBundle, s.bundle)
+	}
+	if err == nil {
+		err = addJSON(entryResults, s.Results)
+	}
+	if err == nil {
+		err = addJSON(entryDoctor, s.Doctor)
+	}
+	for _, ref := range slices.Sorted(maps.Keys(s.Reflogs)) {
+		if err != nil {
+			break
+		}
+		err = add(reflogPrefix+ref, s.Reflogs[ref])
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("submit: write: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a submission.
+func Read(r io.Reader) (*Submission, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("submit: not a submission file: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	s := &Submission{Reflogs: map[string][]byte{}}
+	var haveManifest bool
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("submit: read: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxEntry {
+			return nil, fmt.Errorf("submit: %s is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxEntry))
+		if err != nil {
+			return nil, fmt.Errorf("submit: read %s: %w", hdr.Name, err)
+		}
+		switch name := hdr.Name; {
+		case name == entryManifest:
+			if err := json.Unmarshal(data, &s.Manifest); err != nil {
+				return nil, fmt.Errorf("submit: parse manifest: %w", err)
+			}
+			haveManifest = true
+		case name == entryBundle:
+			s.bundle = data
+		case name == entryResults:
+			if err := json.Unmarshal(data, &s.Results); err != nil {
+				return nil, fmt.Errorf("submit: parse results: %w", err)
+			}
+		case name == entryDoctor:
+			if err := json.Unmarshal(data, &s.Doctor); err != nil {
+				return nil, fmt.Errorf("submit: parse doctor report: %w", err)
+			}
+		case strings.HasPrefix(name, reflogPrefix):
+			ref := strings.TrimPrefix(name, reflogPrefix)
+			if !validRef(ref) {
+				return nil, fmt.Errorf("submit: invalid reflog name %q", ref)
+			}
+			s.Reflogs[ref] = data
+		}
+	}
+	if !haveManifest || s.bundle == nil {
+		return nil, errors.New("submit: not a submission file: manifest or bundle missing")
+	}
+	if s.Manifest.Version != Version {
+		return nil, fmt.Errorf("submit: unsupported version %d", s.Manifest.Version)
+	}
+	return s, nil
+}
+
+// Open reads the submission file at path.
+func Open(path string) (*Submission, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// validRef rejects reflog names that would escape the logs directory when
+// restored.
+func validRef(ref string) bool {
+	if ref == "" || path.IsAbs(ref) || strings.Contains(ref, "\\") {
+		return false
+	}
+	return path.Clean(ref) == ref && !strings.HasPrefix(ref, "../") && ref != ".."
+}
+
+// Extract restores the repository into dir, which must not exist or be
+// empty: every ref, HEAD as the student left it, a checked out working
+// tree and the original reflogs.
+func (s *Submission) Extract(ctx context.Context, dir string) (*gitutil.Repository, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("submit: %s is not empty", dir)
+	}
+	tmp, err := os.CreateTemp("", "tscgit-bundle-*.bundle")
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(s.bundle)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+
+	repo, err := gitutil.CloneBundle(ctx, tmp.Name(), dir, s.Manifest.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("submit: restore bundle: %w", err)
+	}
+	gitDir, err := repo.GitDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("submit: %w", err)
+	}
+	for ref, data := range s.Reflogs {
+		p := filepath.Join(gitDir, "logs", filepath.FromSlash(ref))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return nil, fmt.Errorf("submit: restore reflog %s: %w", ref, err)
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			return nil, fmt.Errorf("submit: restore reflog %s: %w", ref, err)
+		}
+	}
+	return repo, nil
+}
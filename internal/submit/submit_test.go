@@ -0,0 +1,162 @@
+package submit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/doctor"
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/progress"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2026-01-02T03:04:05Z", "GIT_COMMITTER_DATE=2026-01-02T03:04:05Z")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// practiceRepo has a main branch, a feature branch and a commit that was
+// reset away and is only reachable from the reflog.
+func practiceRepo(t *testing.T) (dir, lost string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir = t.TempDir()
+	git(t, dir, "init", "--quiet", "--initial-branch=main")
+	git(t, dir, "config", "user.name", "Ada")
+	git(t, dir, "config", "user.email", "ada@example.com")
+	for i, content := range []string{"one\n", "two\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git(t, dir, "add", "notes.txt")
+		git(t, dir, "commit", "--quiet", "-m", []string{"first", "second"}[i])
+	}
+	lost = git(t, dir, "rev-parse", "HEAD")
+	git(t, dir, "reset", "--quiet", "--hard", "HEAD~1")
+	git(t, dir, "branch", "feature")
+	return dir, lost
+}
+
+func TestCreateAndExtract(t *testing.T) {
+	ctx := context.Background()
+	dir, lost := practiceRepo(t)
+	repo, err := gitutil.Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Uncommitted work is not part of the bundle but is flagged.
+	if err := os.WriteFile(filepath.Join(dir, "draft.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	info := Info{
+		ToolVersion: "v1.2.3",
+		Results:     []progress.Record{{Kind: progress.KindLesson, ID: "init-basics", Passed: true}},
+		Doctor:      doctor.Report{OK: true, Findings: []doctor.Finding{{ID: "git", Status: doctor.StatusOK}}},
+		Now:         time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	manifest, err := Create(ctx, repo, info, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Student != "Ada" || manifest.Branch != "main" || !manifest.Dirty {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+
+	s, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Results) != 1 || s.Results[0].ID != "init-basics" || !s.Doctor.OK || s.Manifest.ToolVersion != "v1.2.3" {
+		t.Fatalf("unexpected submission %+v", s)
+	}
+	if _, ok := s.Reflogs["HEAD"]; !ok {
+		t.Fatalf("HEAD reflog missing; have %v", s.Reflogs)
+	}
+
+	out := filepath.Join(t.TempDir(), "restored")
+	restored, err := s.Extract(ctx, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := restored.Head(ctx)
+	if err != nil || head.Branch != "main" || head.Hash != manifest.Head {
+		t.Fatalf("restored HEAD = %+v, %v; want main at %s", head, err, manifest.Head)
+	}
+	if ok, _ := restored.HasBranch(ctx, "feature"); !ok {
+		t.Fatal("feature branch was not restored as a local branch")
+	}
+	if ok, _ := restored.FileExists("notes.txt"); !ok {
+		t.Fatal("working tree was not checked out")
+	}
+	// The reset-away commit is still in the reflog and its objects came
+	// along in the bundle.
+	if got := git(t, out, "rev-parse", "HEAD@{1}"); got != lost {
+		t.Fatalf("HEAD@{1} = %s, want %s", got, lost)
+	}
+	if got := git(t, out, "cat-file", "-t", lost); got != "commit" {
+		t.Fatalf("lost commit is a %q", got)
+	}
+
+	if _, err := s.Extract(ctx, out); err == nil {
+		t.Fatal("extracting into a non-empty directory should fail")
+	}
+}
+
+func TestCreateNeedsACommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "--quiet")
+	repo, err := gitutil.Open(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(context.Background(), repo, Info{}, &bytes.Buffer{}); !gitutil.IsNoCommits(err) {
+		t.Fatalf("want ErrNoCommits, got %v", err)
+	}
+}
+
+func TestReadRejectsBadFiles(t *testing.T) {
+	if _, err := Read(strings.NewReader("PK\x03\x04 not a tarball")); err == nil {
+		t.Fatal("a zip file should be rejected")
+	}
+
+	archive := func(names ...string) *bytes.Buffer {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for _, name := range names {
+			data := []byte("{\"version\":1}")
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))})
+			tw.Write(data)
+		}
+		tw.Close()
+		gz.Close()
+		return &buf
+	}
+	if _, err := Read(archive(entryManifest)); err == nil || !strings.Contains(err.Error(), "bundle missing") {
+		t.Fatalf("missing bundle: %v", err)
+	}
+	if _, err := Read(archive(entryManifest, entryBundle, "reflogs/../../hooks/pre-commit")); err == nil || !strings.Contains(err.Error(), "invalid reflog name") {
+		t.Fatalf("escaping reflog path: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+// Package trace records every external command tscgit runs — git calls from
+// gitutil and shell steps from run scripts — so a misbehaving check can be
+// diagnosed from a log a student attaches to a support ticket.
+package trace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Kinds of traced commands.
+const (
+	KindGit   = "git"
+	KindShell = "shell"
+)
+
+// MaxOutput bounds how many bytes of stdout and stderr each event keeps.
+var MaxOutput = 4096
+
+// Event is one finished command. It is written as a single JSON line.
+type Event struct {
+	Time time.Time `json:"time"`
+	Kind string    `json:"kind"`
+	Argv []string  `json:"argv"`
+	Dir  string    `json:"cwd"`
+	// Env lists the variables set or changed relative to tscgit's own
+	// environment as NAME=value, and removed ones as -NAME.
+	Env        []string `json:"env,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+	// ExitCode is -1 when the command could not be started or was killed.
+	ExitCode  int    `json:"exitCode"`
+	Stdout    string `json:"stdout,omitempty"`
+	Stderr    string `json:"stderr,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Duration returns how long the command ran.
+func (e Event) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// CommandLine is the command as a student would type it: the -c overrides
+// gitutil adds are dropped, as is the shell wrapper around script steps.
+// Argv keeps the full command.
+func (e Event) CommandLine() string {
+	argv := e.Argv
+	switch e.Kind {
+	case KindGit:
+		out := []string{"git"}
+		for i := 1; i < len(argv); i++ {
+			if argv[i] == "-c" {
+				i++
+				continue
+			}
+			out = append(out, argv[i])
+		}
+		return strings.Join(out, " ")
+	case KindShell:
+		if len(argv) > 0 {
+			return argv[len(argv)-1]
+		}
+	}
+	return strings.Join(argv, " ")
+}
+
+// Failure explains why the command failed, using the first line of its
+// stderr, or returns "" when it succeeded.
+func (e Event) Failure() string {
+	if e.Error != "" || e.ExitCode == 0 {
+		return e.Error
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(e.Stderr), "\n")
+	return fmt.Sprintf("exit %d: %s", e.ExitCode, first)
+}
+
+// Recorder writes events as JSON lines and keeps the most recent ones in
+// memory for the TUI debug pane. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	keep   int
+	events []Event
+	err    error
+}
+
+// NewRecorder returns a recorder that writes to w, which may be nil, and
+// remembers the last keep events.
+func NewRecorder(w io.Writer, keep int) *Recorder {
+	r := &Recorder{keep: keep}
+	if w != nil {
+		r.enc = json.NewEncoder(w)
+	}
+	return r
+}
+
+// Record logs ev.
+func (r *Recorder) Record(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc != nil && r.err == nil {
+		if err := r.enc.Encode(ev); err != nil {
+			r.err = fmt.Errorf("trace: write event: %w", err)
+		}
+	}
+	if r.keep <= 0 {
+		return
+	}
+	if len(r.events) == r.keep {
+		r.events = slices.Delete(r.events, 0, 1)
+	}
+	r.events = append(r.events, ev)
+}
+
+// Events returns the remembered events, oldest first.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// Err returns the first error writing the log, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+var active atomic.Pointer[Recorder]
+
+// Start makes r the recorder every traced command reports to. Passing nil
+// turns tracing off.
+func Start(r *Recorder) {
+	active.Store(r)
+}
+
+// Enabled reports whether commands are being traced.
+func Enabled() bool {
+	return active.Load() != nil
+}
+
+// Events returns the events remembered by the active recorder.
+func Events() []Event {
+	if r := active.Load(); r != nil {
+		return r.Events()
+	}
+	return nil
+}
+
+// Command records a finished command with the active recorder. It does
+// nothing when tracing is off, so callers need not check Enabled.
+func Command(kind string, cmd *exec.Cmd, start time.Time, exitCode int, stdout, stderr string, err error) {
+	r := active.Load()
+	if r == nil {
+		return
+	}
+	ev := Event{
+		Time:       start,
+		Kind:       kind,
+		Argv:       slices.Clone(cmd.Args),
+		Dir:        cmd.Dir,
+		DurationMS: time.Since(start).Milliseconds(),
+		ExitCode:   exitCode,
+	}
+	if ev.Dir == "" {
+		ev.Dir, _ = os.Getwd()
+	}
+	if cmd.Env != nil {
+		ev.Env = EnvOverrides(os.Environ(), cmd.Env)
+	}
+	var cut bool
+	ev.Stdout, cut = truncate(stdout)
+	ev.Truncated = cut
+	ev.Stderr, cut = truncate(stderr)
+	ev.Truncated = ev.Truncated || cut
+	if err != nil {
+		var exitErr *exec.ExitError
+		// A non-zero exit is already in ExitCode; keep only other failures.
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() < 0 {
+			ev.Error = err.Error()
+		}
+	}
+	r.Record(ev)
+}
+
+// EnvOverrides lists how env differs from base: NAME=value for variables
+// that were added or changed, -NAME for ones that were removed.
+func EnvOverrides(base, env []string) []string {
+	baseVals := make(map[string]string, len(base))
+	for _, kv := range base {
+		name, value, _ := strings.Cut(kv, "=")
+		baseVals[name] = value
+	}
+	seen := make(map[string]bool, len(env))
+	var out []string
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		seen[name] = true
+		if old, ok := baseVals[name]; !ok || old != value {
+			out = append(out, kv)
+		}
+	}
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if !seen[name] {
+			out = append(out, "-"+name)
+			seen[name] = true
+		}
+	}
+	return out
+}
+
+// truncate cuts s to MaxOutput bytes without splitting a UTF-8 sequence.
+func truncate(s string) (string, bool) {
+	if MaxOutput <= 0 || len(s) <= MaxOutput {
+		return s, false
+	}
+	cut := MaxOutput
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut], true
+}
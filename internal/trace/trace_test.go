@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewRecorder(&buf, 2)
+	Start(r)
+	defer Start(nil)
+
+	oldMax := MaxOutput
+	MaxOutput = 5
+	defer func() { MaxOutput = oldMax }()
+
+	for i, out := range []string{"short", "héllo world", "third"} {
+		cmd := exec.Command("git", "status")
+		cmd.Dir = "/repo"
+		cmd.Env = []string{"LC_ALL=C"}
+		Command(KindGit, cmd, time.Now(), i, out, "", nil)
+	}
+
+	var events []Event
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var ev Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) != 3 {
+		t.Fatalf("want 3 lines, got %d", len(events))
+	}
+	ev := events[1]
+	if ev.Kind != KindGit || ev.Dir != "/repo" || ev.ExitCode != 1 || !slices.Equal(ev.Argv, []string{"git", "status"}) {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+	if ev.Stdout != "héll" || !ev.Truncated {
+		t.Fatalf("stdout = %q, truncated %v", ev.Stdout, ev.Truncated)
+	}
+	if !slices.Contains(ev.Env, "LC_ALL=C") {
+		t.Fatalf("env overrides missing LC_ALL: %q", ev.Env)
+	}
+
+	kept := Events()
+	if len(kept) != 2 || kept[0].ExitCode != 1 || kept[1].ExitCode != 2 {
+		t.Fatalf("recorder should keep the last two events, got %+v", kept)
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandWhenDisabled(t *testing.T) {
+	Start(nil)
+	if Enabled() || Events() != nil {
+		t.Fatal("tracing should be off")
+	}
+	Command(KindShell, exec.Command("sh"), time.Now(), 0, "", "", nil)
+}
+
+func TestEnvOverrides(t *testing.T) {
+	base := []string{"HOME=/home/a", "LANG=de_DE.UTF-8", "PAGER=less"}
+	env := []string{"HOME=/home/a", "PAGER=cat", "LC_ALL=C"}
+	got := strings.Join(EnvOverrides(base, env), " ")
+	if got != "PAGER=cat LC_ALL=C -LANG" {
+		t.Fatalf("EnvOverrides = %q", got)
+	}
+}
+
+func TestEventCommandLine(t *testing.T) {
+	cases := []struct {
+		ev      Event
+		line    string
+		failure string
+	}{
+		{Event{Kind: KindGit, Argv: []string{"/usr/bin/git", "-c", "color.ui=false", "status", "-z"}}, "git status -z", ""},
+		{Event{Kind: KindShell, Argv: []string{"sh", "-c", "cat notes.txt"}, ExitCode: 1, Stderr: "cat: notes.txt: No such file\n"}, "cat notes.txt", "exit 1: cat: notes.txt: No such file"},
+		{Event{Kind: KindShell, Argv: []string{"sh", "-c", "sleep 9"}, ExitCode: -1, Error: "signal: killed"}, "sleep 9", "signal: killed"},
+	}
+	for _, tc := range cases {
+		if got := tc.ev.CommandLine(); got != tc.line {
+			t.Errorf("CommandLine() = %q, want %q", got, tc.line)
+		}
+		if got := tc.ev.Failure(); got != tc.failure {
+			t.Errorf("Failure() = %q, want %q", got, tc.failure)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package browseui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/progress"
+	"github.com/rohit746/tscgit/internal/run"
+	runui "github.com/rohit746/tscgit/internal/ui/run"
+	verifyui "github.com/rohit746/tscgit/internal/ui/verify"
+	"github.com/rohit746/tscgit/internal/verify"
+)
+
+const (
+	columnLessons = iota
+	columnScripts
+)
+
+// item is a row in one of the two lists.
+type item struct {
+	kind   progress.Kind
+	id     string
+	title  string
+	desc   string
+	lesson *lessons.Lesson
+	script *run.Script
+	search string
+}
+
+// child is a verify or run model launched from the browser.
+type child interface {
+	tea.Model
+	Done() bool
+}
+
+// childExitMsg replaces the tea.QuitMsg emitted by a finished child so the
+// browser, rather than the program, handles it.
+type childExitMsg struct{}
+
+// Model drives the interactive lesson browser.
+type Model struct {
+	lessons []item
+	scripts []item
+	repo    *gitutil.Repository
+	repoErr error
+	dir     string
+	store   *progress.Store
+	filter  textinput.Model
+	column  int
+	cursor  [2]int
+	width   int
+	height  int
+	status  string
+	debug   bool
+
+	child       child
+	childItem   item
+	childExited bool
+}
+
+// NewModel builds the browser over every registered lesson and script.
+// Verification lessons run against the repository containing repoPath and
+// scripts run in repoPath itself; store supplies progress badges and
+// receives new results, and may be nil.
+func NewModel(repoPath string, store *progress.Store) *Model {
+	ti := textinput.New()
+	ti.Prompt = "Filter: "
+	ti.Placeholder = "type to search lessons and scripts"
+	ti.Focus()
+
+	m := &Model{store: store, filter: ti, dir: repoPath}
+	m.repo, m.repoErr = gitutil.Open(context.Background(), repoPath)
+
+	for _, lesson := range lessons.List() {
+		m.lessons = append(m.lessons, item{
+			kind:   progress.KindLesson,
+			id:     lesson.ID,
+			title:  lesson.Title,
+			desc:   lesson.Description,
+			lesson: lesson,
+			search: strings.ToLower(lesson.ID + " " + lesson.Title + " " + lesson.Description),
+		})
+	}
+	for _, script := range run.List() {
+		m.scripts = append(m.scripts, item{
+			kind:   progress.KindScript,
+			id:     script.ID,
+			title:  script.Title,
+			desc:   script.Description,
+			script: script,
+			search: strings.ToLower(script.ID + " " + script.Title + " " + script.Description),
+		})
+	}
+	return m
+}
+
+// SetDebug is forwarded to verification lessons launched from the browser.
+func (m *Model) SetDebug(debug bool) {
+	m.debug = debug
+}
+
+// Init starts the filter cursor blinking.
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles Bubble Tea messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width, m.height = size.Width, size.Height
+	}
+
+	if m.child != nil {
+		return m.updateChild(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			if m.filter.Value() != "" {
+				m.filter.SetValue("")
+				m.clampCursors()
+				return m, nil
+			}
+			return m, tea.Quit
+		case "tab", "shift+tab", "left", "right":
+			m.column = 1 - m.column
+			m.clampCursors()
+			return m, nil
+		case "up", "ctrl+p":
+			if m.cursor[m.column] > 0 {
+				m.cursor[m.column]--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.cursor[m.column] < len(m.visible(m.column))-1 {
+				m.cursor[m.column]++
+			}
+			return m, nil
+		case "enter":
+			return m.launch()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.clampCursors()
+	return m, cmd
+}
+
+func (m *Model) updateChild(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case childExitMsg:
+		m.childExited = true
+		m.recordChild()
+		return m, nil
+	case tea.KeyMsg:
+		if m.childExited {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "enter", "esc", "q", "backspace":
+				m.child = nil
+				m.childExited = false
+				return m, textinput.Blink
+			}
+			if msg.String() == "g" || msg.String() == "t" || msg.String() == "d" {
+				// Let a finished lesson or script switch its side pane.
+				next, _ := m.child.Update(msg)
+				m.child = next.(child)
+			}
+			return m, nil
+		}
+	}
+	if m.childExited {
+		return m, nil
+	}
+	next, cmd := m.child.Update(msg)
+	m.child = next.(child)
+	return m, interceptQuit(cmd)
+}
+
+// launch starts the verify or run model for the selected item.
+func (m *Model) launch() (tea.Model, tea.Cmd) {
+	selected, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+	m.status = ""
+
+	var c child
+	switch selected.kind {
+	case progress.KindLesson:
+		if m.repo == nil {
+			m.status = verify.Describe(m.repoErr)
+			return m, nil
+		}
+		vm := verifyui.NewModel(selected.lesson, m.repo)
+		vm.SetDebug(m.debug)
+		// The browser keeps finished lessons on screen itself.
+		vm.SetKeepOpen(false)
+		c = vm
+	default:
+		rm := runui.NewModel(selected.script)
+		rm.SetDir(m.dir)
+		rm.SetKeepOpen(false)
+		c = rm
+	}
+
+	m.child = c
+	m.childItem = selected
+	m.childExited = false
+	if m.width > 0 {
+		next, _ := c.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		m.child = next.(child)
+	}
+	return m, interceptQuit(m.child.Init())
+}
+
+// recordChild saves the finished child's outcome to the progress store.
+func (m *Model) recordChild() {
+	if m.store == nil || !m.child.Done() {
+		return
+	}
+	var rec progress.Record
+	switch c := m.child.(type) {
+	case *verifyui.Model:
+		rec = progress.FromVerify(verify.NewReport(m.childItem.lesson, c.Results()), m.repo.Root)
+	case *runui.Model:
+		rec = progress.FromRun(run.NewReport(m.childItem.script, c.Results()), "")
+	default:
+		return
+	}
+	m.store.Put(rec)
+	if err := m.store.Save(); err != nil {
+		m.status = fmt.Sprintf("Could not save progress: %v", err)
+	}
+}
+
+// interceptQuit rewrites tea.QuitMsg produced by cmd, including inside
+// batches, into childExitMsg.
+func interceptQuit(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case tea.QuitMsg:
+			return childExitMsg{}
+		case tea.BatchMsg:
+			out := make(tea.BatchMsg, len(msg))
+			for i, c := range msg {
+				out[i] = interceptQuit(c)
+			}
+			return out
+		default:
+			return msg
+		}
+	}
+}
+
+func (m *Model) visible(column int) []item {
+	all := m.lessons
+	if column == columnScripts {
+		all = m.scripts
+	}
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if query == "" {
+		return all
+	}
+	out := make([]item, 0, len(all))
+	for _, it := range all {
+		if strings.Contains(it.search, query) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func (m *Model) selected() (item, bool) {
+	items := m.visible(m.column)
+	if len(items) == 0 {
+		return item{}, false
+	}
+	return items[m.cursor[m.column]], true
+}
+
+func (m *Model) clampCursors() {
+	for col := range m.cursor {
+		n := len(m.visible(col))
+		if m.cursor[col] >= n {
+			m.cursor[col] = max(n-1, 0)
+		}
+	}
+}
+
+// View renders the browser, or the active lesson when one is running.
+func (m *Model) View() string {
+	if m.child != nil {
+		view := m.child.View()
+		if m.childExited {
+			view += "\n\n" + helpStyle.Render("Press Enter to return to the list · ctrl+c to quit.")
+		}
+		return view
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("tscgit — Git practice companion"))
+	b.WriteString("\n")
+	b.WriteString(m.filter.View())
+	b.WriteString("\n\n")
+
+	colWidth := 40
+	if m.width > 0 {
+		colWidth = max((m.width-3)/2, 20)
+	}
+	rows := m.listHeight()
+	left := m.renderColumn(columnLessons, "Verification lessons", colWidth, rows)
+	right := m.renderColumn(columnScripts, "Run scripts", colWidth, rows)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, left, "   ", right))
+	b.WriteString("\n\n")
+
+	if selected, ok := m.selected(); ok {
+		b.WriteString(m.renderDetail(selected))
+		b.WriteString("\n")
+	}
+	if m.status != "" {
+		b.WriteString(statusStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓ move · tab switch list · enter start · esc clear filter/quit · ctrl+c quit"))
+
+	return lipgloss.NewStyle().Width(m.width).Render(b.String())
+}
+
+// listHeight leaves room for the header, detail pane and footer.
+func (m *Model) listHeight() int {
+	if m.height == 0 {
+		return 0
+	}
+	return max(m.height-18, 3)
+}
+
+func (m *Model) renderColumn(column int, heading string, width, rows int) string {
+	items := m.visible(column)
+	style := headingStyle
+	if column == m.column {
+		style = activeHeadingStyle
+	}
+
+	var b strings.Builder
+	b.WriteString(style.Render(fmt.Sprintf("%s (%d)", heading, len(items))))
+	b.WriteString("\n")
+	if len(items) == 0 {
+		b.WriteString(helpStyle.Render("  no matches"))
+	}
+
+	start, end := 0, len(items)
+	if rows > 0 && len(items) > rows {
+		start = min(max(m.cursor[column]-rows/2, 0), len(items)-rows)
+		end = start + rows
+	}
+	for i := start; i < end; i++ {
+		it := items[i]
+		marker := "  "
+		if column == m.column && i == m.cursor[column] {
+			marker = cursorStyle.Render("› ")
+		}
+		line := fmt.Sprintf("%s %-4s %s", m.badge(it), it.id, it.title)
+		if column == m.column && i == m.cursor[column] {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(marker + line))
+		b.WriteString("\n")
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+func (m *Model) record(it item) (progress.Record, bool) {
+	if m.store == nil {
+		return progress.Record{}, false
+	}
+	return m.store.Get(it.kind, it.id)
+}
+
+func (m *Model) badge(it item) glyph {
+	rec, ok := m.record(it)
+	switch {
+	case !ok:
+		return newBadge
+	case rec.Passed:
+		return passBadge
+	case rec.Points > 0 || rec.EverPassed:
+		return partialBadge
+	default:
+		return failBadge
+	}
+}
+
+func (m *Model) renderDetail(it item) string {
+	var b strings.Builder
+	b.WriteString(detailTitleStyle.Render(fmt.Sprintf("%s — %s", it.id, it.title)))
+	if rec, ok := m.record(it); ok {
+		b.WriteString(helpStyle.Render(fmt.Sprintf("  last run: %d/%d (%.0f%%), %d attempt(s)", rec.Points, rec.Max, rec.Percent, rec.Attempts)))
+	}
+	b.WriteString("\n")
+	if it.desc != "" {
+		b.WriteString(descStyle.Render(it.desc))
+		b.WriteString("\n")
+	}
+
+	rec, _ := m.record(it)
+	if it.lesson != nil {
+		for _, check := range it.lesson.Checks {
+			fmt.Fprintf(&b, "  %s %s", statusGlyph(rec.Checks[check.ID]), check.Title)
+			if check.Description != "" {
+				b.WriteString(helpStyle.Render(" — " + check.Description))
+			}
+			b.WriteString("\n")
+		}
+		if m.repo == nil {
+			b.WriteString(statusStyle.Render("  " + verify.Describe(m.repoErr)))
+			b.WriteString("\n")
+		}
+	}
+	if it.script != nil {
+		for i, step := range it.script.Steps {
+			fmt.Fprintf(&b, "  %s $ %s", statusGlyph(rec.Checks[fmt.Sprint(i+1)]), step.Command)
+			if len(step.ExpectStdout) > 0 {
+				b.WriteString(helpStyle.Render(" — expects " + strings.Join(step.ExpectStdout, ", ")))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func statusGlyph(status string) glyph {
+	switch status {
+	case verify.StatusPassed:
+		return passBadge
+	case verify.StatusSkipped:
+		return skipBadge
+	case verify.StatusFailed, verify.StatusError:
+		return failBadge
+	default:
+		return newBadge
+	}
+}
+
+var (
+	passBadge    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}), "✔"}
+	failBadge    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}), "✘"}
+	partialBadge = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"}), "◐"}
+	skipBadge    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"}), "⊘"}
+	newBadge     = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "243", Dark: "248"}), "•"}
+
+	titleStyle         = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).MarginBottom(1)
+	headingStyle       = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Bold(true)
+	activeHeadingStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).Bold(true).Underline(true)
+	cursorStyle        = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"}).Bold(true)
+	selectedStyle      = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "255"}).Bold(true)
+	detailTitleStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).Bold(true)
+	descStyle          = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"})
+	statusStyle        = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"})
+	helpStyle          = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Faint(true)
+)
+
+// glyph is a colored status symbol. It renders when printed so color and
+// theme settings applied at startup take effect.
+type glyph struct {
+	style  lipgloss.Style
+	symbol string
+}
+
+func (g glyph) String() string {
+	return g.style.Render(g.symbol)
+}
@@ -0,0 +1,55 @@
+package browseui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rohit746/tscgit/internal/run"
+)
+
+func TestLaunchedScriptRunsInRepoPath(t *testing.T) {
+	run.Register(&run.Script{
+		ID:    "browse-path",
+		Title: "Browse path",
+		Steps: []run.Step{{Command: "echo hi > launched.txt"}},
+	})
+	dir := t.TempDir()
+	m := NewModel(dir, nil)
+	m.filter.SetValue("browse-path")
+	m.column = columnScripts
+	m.clampCursors()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.child == nil {
+		t.Fatal("expected enter to launch the script")
+	}
+	for queue := []tea.Cmd{cmd}; len(queue) > 0; {
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+		switch msg := next().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case childExitMsg, spinner.TickMsg:
+		default:
+			_, cmd := m.Update(msg)
+			queue = append(queue, cmd)
+		}
+	}
+	if !m.child.Done() {
+		t.Fatal("expected the script to finish")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "launched.txt")); err != nil {
+		t.Fatalf("expected the script to run in %s: %v", dir, err)
+	}
+	if _, err := os.Stat("launched.txt"); err == nil {
+		t.Fatal("the script ran in the current directory")
+	}
+}
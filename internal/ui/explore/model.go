@@ -0,0 +1,321 @@
+package exploreui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+)
+
+// Model is an interactive browser for the git object database. It starts at
+// a revision and walks from commits to trees to blobs.
+type Model struct {
+	repo   *gitutil.Repository
+	start  string
+	stack  []*frame
+	err    error
+	height int
+	width  int
+	busy   bool
+}
+
+// frame is one object on the navigation stack.
+type frame struct {
+	label   string
+	object  gitutil.Object
+	headers []string
+	links   []link
+	body    []string
+	cursor  int
+	offset  int
+}
+
+// link is an object referenced by the current one.
+type link struct {
+	label string
+	rev   string
+	name  string
+}
+
+type frameMsg struct {
+	owner *Model
+	frame *frame
+	err   error
+}
+
+// NewModel creates an explorer rooted at rev, or HEAD when rev is empty.
+func NewModel(repo *gitutil.Repository, rev string) *Model {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	return &Model{repo: repo, start: rev}
+}
+
+// Init loads the starting object.
+func (m *Model) Init() tea.Cmd {
+	m.busy = true
+	return m.load(m.start, m.start)
+}
+
+func (m *Model) load(rev, label string) tea.Cmd {
+	repo := m.repo
+	return func() tea.Msg {
+		f, err := readFrame(repo, rev, label)
+		return frameMsg{owner: m, frame: f, err: err}
+	}
+}
+
+// readFrame reads the object named by rev and decodes it for display.
+func readFrame(repo *gitutil.Repository, rev, label string) (*frame, error) {
+	ctx, cancel := lessons.TimeoutContext()
+	defer cancel()
+
+	obj, err := repo.ResolveObject(ctx, rev)
+	if err != nil {
+		return nil, err
+	}
+	f := &frame{label: label, object: obj}
+	switch obj.Type {
+	case gitutil.ObjectCommit:
+		c, err := repo.ReadCommit(ctx, obj.Hash)
+		if err != nil {
+			return nil, err
+		}
+		f.headers = strings.Split(c.Raw, "\n")
+		f.links = append(f.links, link{label: "tree " + c.Tree, rev: c.Tree, name: "tree"})
+		for _, p := range c.Parents {
+			f.links = append(f.links, link{label: "parent " + p, rev: p, name: shortHash(p)})
+		}
+		f.body = strings.Split(strings.TrimRight(c.Message, "\n"), "\n")
+	case gitutil.ObjectTag:
+		t, err := repo.ReadTag(ctx, obj.Hash)
+		if err != nil {
+			return nil, err
+		}
+		f.headers = strings.Split(t.Raw, "\n")
+		f.links = append(f.links, link{label: fmt.Sprintf("object %s (%s)", t.Target, t.TargetType), rev: t.Target, name: shortHash(t.Target)})
+		f.body = strings.Split(strings.TrimRight(t.Message, "\n"), "\n")
+	case gitutil.ObjectTree:
+		entries, err := repo.ReadTree(ctx, obj.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			f.links = append(f.links, link{
+				label: fmt.Sprintf("%s %-6s %s  %s", e.Mode, e.Type, e.Hash, e.Name),
+				rev:   e.Hash,
+				name:  e.Name,
+			})
+		}
+	case gitutil.ObjectBlob:
+		data, err := repo.ReadBlob(ctx, obj.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			f.body = []string{fmt.Sprintf("(binary content, %d bytes)", len(data))}
+		} else {
+			f.body = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		}
+	}
+	return f, nil
+}
+
+// Update handles Bubble Tea messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case frameMsg:
+		if msg.owner != m {
+			return m, nil
+		}
+		m.busy = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.stack = append(m.stack, msg.frame)
+		}
+		return m, nil
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	}
+	f := m.current()
+	if f == nil || m.busy {
+		return m, nil
+	}
+	switch msg.String() {
+	case "up", "k":
+		if len(f.links) > 0 {
+			f.cursor = max(f.cursor-1, 0)
+		} else {
+			f.offset = max(f.offset-1, 0)
+		}
+	case "down", "j":
+		if len(f.links) > 0 {
+			f.cursor = min(f.cursor+1, len(f.links)-1)
+		} else {
+			f.offset = min(f.offset+1, max(len(f.body)-m.bodyHeight(), 0))
+		}
+	case "pgup":
+		f.offset = max(f.offset-m.bodyHeight(), 0)
+	case "pgdown", " ":
+		f.offset = min(f.offset+m.bodyHeight(), max(len(f.body)-m.bodyHeight(), 0))
+	case "enter", "right", "l":
+		if len(f.links) == 0 {
+			return m, nil
+		}
+		target := f.links[f.cursor]
+		m.busy = true
+		m.err = nil
+		return m, m.load(target.rev, target.name)
+	case "backspace", "left", "h", "esc":
+		m.err = nil
+		if len(m.stack) > 1 {
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) current() *frame {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// bodyHeight is how many message or blob lines fit on screen.
+func (m *Model) bodyHeight() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-12, 5)
+}
+
+// View renders the current object.
+func (m *Model) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Object explorer"))
+	b.WriteString("\n")
+
+	crumbs := make([]string, 0, len(m.stack))
+	for _, f := range m.stack {
+		crumbs = append(crumbs, f.label)
+	}
+	b.WriteString(crumbStyle.Render(strings.Join(crumbs, " › ")))
+	b.WriteString("\n\n")
+
+	f := m.current()
+	if f != nil {
+		b.WriteString(typeStyle(f.object.Type).Render(string(f.object.Type)))
+		b.WriteString(" ")
+		b.WriteString(hashStyle.Render(f.object.Hash))
+		b.WriteString(sizeStyle.Render(fmt.Sprintf("  %d bytes", f.object.Size)))
+		b.WriteString("\n\n")
+
+		for _, h := range f.headers {
+			b.WriteString(headerStyle.Render(h))
+			b.WriteString("\n")
+		}
+		if len(f.headers) > 0 {
+			b.WriteString("\n")
+		}
+
+		if f.object.Type == gitutil.ObjectTree && len(f.links) == 0 {
+			b.WriteString(mutedStyle.Render("(empty tree)"))
+			b.WriteString("\n")
+		}
+		for i, l := range f.links {
+			if i == f.cursor {
+				b.WriteString(selectedStyle.Render("› " + l.label))
+			} else {
+				b.WriteString(linkStyle.Render("  " + l.label))
+			}
+			b.WriteString("\n")
+		}
+		if len(f.links) > 0 && len(f.body) > 0 {
+			b.WriteString("\n")
+		}
+
+		end := min(f.offset+m.bodyHeight(), len(f.body))
+		for i := f.offset; i < end; i++ {
+			if f.object.Type == gitutil.ObjectBlob {
+				b.WriteString(mutedStyle.Render(fmt.Sprintf("%4d ", i+1)))
+			}
+			b.WriteString(f.body[i])
+			b.WriteString("\n")
+		}
+		if end < len(f.body) {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("… %d more lines", len(f.body)-end)))
+			b.WriteString("\n")
+		}
+	}
+
+	switch {
+	case m.err != nil:
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(m.err.Error()))
+		b.WriteString("\n")
+	case m.busy:
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("Loading…"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓ move · enter open · backspace back · q quit"))
+	return lipgloss.NewStyle().MaxWidth(m.width).Render(b.String())
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func typeStyle(t gitutil.ObjectType) lipgloss.Style {
+	switch t {
+	case gitutil.ObjectCommit:
+		return commitStyle
+	case gitutil.ObjectTree:
+		return treeStyle
+	case gitutil.ObjectTag:
+		return tagStyle
+	default:
+		return blobStyle
+	}
+}
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"})
+	crumbStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"})
+
+	commitStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"})
+	treeStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"})
+	blobStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "25", Dark: "111"})
+	tagStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"})
+
+	hashStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"})
+	sizeStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"})
+	headerStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"})
+	linkStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"})
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"}).Bold(true)
+	mutedStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"})
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
+	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Faint(true)
+)
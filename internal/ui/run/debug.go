@@ -0,0 +1,46 @@
+package runui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rohit746/tscgit/internal/trace"
+)
+
+// debugRows is how many recent commands the debug pane lists.
+const debugRows = 20
+
+// renderDebug lists the commands recorded by --debug or --trace, newest
+// last, with the reason each failure gave.
+func (m *Model) renderDebug(width int) string {
+	var b strings.Builder
+	clip := lipgloss.NewStyle().MaxWidth(max(width, 20))
+
+	events := trace.Events()
+	b.WriteString(sectionStyle.Render(fmt.Sprintf("Commands (%d)", len(events))))
+	b.WriteString("\n")
+	if len(events) == 0 {
+		b.WriteString(detailStyle.Render("No commands run yet."))
+		return b.String()
+	}
+	if len(events) > debugRows {
+		events = events[len(events)-debugRows:]
+	}
+	for _, ev := range events {
+		mark, style := successGlyph, detailStyle
+		reason := ev.Failure()
+		if reason != "" {
+			mark, style = failGlyph, failDetailStyle
+		}
+		line := fmt.Sprintf("%s %s %s", mark, style.Render(ev.CommandLine()), timingStyle.Render(fmt.Sprintf("%dms", ev.DurationMS)))
+		b.WriteString(clip.Render(line))
+		b.WriteString("\n")
+		if reason != "" {
+			b.WriteString(clip.Render("  " + failDetailStyle.Render(reason)))
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
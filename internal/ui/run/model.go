@@ -11,18 +11,37 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/trace"
 )
 
 // Model drives the Bubble Tea UI for scripted run lessons.
 type Model struct {
-	script  *run.Script
-	spinner spinner.Model
-	results []run.StepResult
-	done    bool
-	width   int
+	script   *run.Script
+	spinner  spinner.Model
+	results  []run.StepResult
+	setup    []run.StepResult
+	teardown []run.StepResult
+	phase    run.Phase
+	quitting bool
+	done     bool
+	width    int
+	// keepOpen leaves the view up after the last step so the debug pane
+	// can still be read.
+	keepOpen  bool
+	showDebug bool
+	// dir is where the script's commands run; empty means the current
+	// directory.
+	dir string
 }
 
+// paneMinWidth is the terminal width needed to show the debug pane beside
+// the steps; narrower terminals stack it below them.
+const paneMinWidth = 100
+
+// stepResultMsg carries the model that produced it so a stale result from
+// an earlier model is ignored when several run in the same program.
 type stepResultMsg struct {
+	owner  *Model
 	result run.StepResult
 }
 
@@ -30,17 +49,58 @@ type stepResultMsg struct {
 func NewModel(script *run.Script) *Model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	return &Model{script: script, spinner: sp}
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"})
+	return &Model{script: script, spinner: sp, phase: run.PhaseSetup, keepOpen: trace.Enabled()}
 }
 
 // Init starts the spinner and kicks off the first command.
 func (m *Model) Init() tea.Cmd {
-	if len(m.script.Steps) == 0 {
-		m.done = true
-		return tea.Quit
+	return m.step()
+}
+
+func (m *Model) step() tea.Cmd {
+	next := m.advance()
+	if m.done {
+		return next
+	}
+	return tea.Batch(m.spinner.Tick, next)
+}
+
+// advance returns the next command to execute, moving through setup, graded
+// steps and teardown in order. Teardown always runs before the program quits.
+func (m *Model) advance() tea.Cmd {
+	if m.phase == run.PhaseSetup {
+		if !m.quitting && !anyFailed(m.setup) && len(m.setup) < len(m.script.Setup) {
+			return m.runStepCmd(run.PhaseSetup, m.script.Setup[len(m.setup)])
+		}
+		m.phase = run.PhaseCheck
+		if m.quitting || anyFailed(m.setup) {
+			m.phase = run.PhaseTeardown
+		}
+	}
+	if m.phase == run.PhaseCheck {
+		if !m.quitting && len(m.results) < len(m.script.Steps) {
+			return m.runStepCmd(run.PhaseCheck, m.script.Steps[len(m.results)])
+		}
+		m.phase = run.PhaseTeardown
+	}
+	if len(m.teardown) < len(m.script.Teardown) {
+		return m.runStepCmd(run.PhaseTeardown, m.script.Teardown[len(m.teardown)])
+	}
+	m.done = true
+	if m.keepOpen && !m.quitting {
+		return nil
+	}
+	return tea.Quit
+}
+
+func anyFailed(results []run.StepResult) bool {
+	for _, res := range results {
+		if !res.Passed {
+			return true
+		}
 	}
-	return tea.Batch(m.spinner.Tick, runStepCmd(m.script, 0))
+	return false
 }
 
 // Update handles Bubble Tea messages.
@@ -59,20 +119,36 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
-			return m, tea.Quit
+			// Let the in-flight command finish so teardown still runs; a
+			// second interrupt exits immediately.
+			if m.done || m.quitting || len(m.script.Teardown) == 0 {
+				return m, tea.Quit
+			}
+			m.quitting = true
+			return m, nil
 		case "enter":
 			if m.done {
 				return m, tea.Quit
 			}
+		case "d":
+			if trace.Enabled() {
+				m.showDebug = !m.showDebug
+			}
 		}
 		return m, nil
 	case stepResultMsg:
-		m.results = append(m.results, msg.result)
-		if len(m.results) == len(m.script.Steps) {
-			m.done = true
-			return m, tea.Quit
+		if msg.owner != m {
+			return m, nil
 		}
-		return m, tea.Batch(m.spinner.Tick, runStepCmd(m.script, len(m.results)))
+		switch msg.result.Phase {
+		case run.PhaseSetup:
+			m.setup = append(m.setup, msg.result)
+		case run.PhaseTeardown:
+			m.teardown = append(m.teardown, msg.result)
+		default:
+			m.results = append(m.results, msg.result)
+		}
+		return m, m.step()
 	default:
 		return m, nil
 	}
@@ -89,6 +165,8 @@ func (m *Model) View() string {
 		b.WriteString("\n\n")
 	}
 
+	m.renderPhase(&b, "Setup", run.PhaseSetup, m.script.Setup, m.setup)
+
 	for i, step := range m.script.Steps {
 		var status string
 		var detail string
@@ -108,7 +186,7 @@ func (m *Model) View() string {
 			b.WriteString(status)
 			b.WriteString(" " + timing)
 		} else {
-			if !m.done && i == len(m.results) {
+			if !m.done && m.phase == run.PhaseCheck && i == len(m.results) {
 				status = pendingStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), step.Command))
 			} else {
 				status = pendingStyle.Render(fmt.Sprintf("%s %s", pendingGlyph, step.Command))
@@ -124,6 +202,8 @@ func (m *Model) View() string {
 		b.WriteString("\n\n")
 	}
 
+	m.renderPhase(&b, "Teardown", run.PhaseTeardown, m.script.Teardown, m.teardown)
+
 	passed := 0
 	for _, res := range m.results {
 		if res.Passed {
@@ -140,14 +220,61 @@ func (m *Model) View() string {
 			b.WriteString(summaryFailStyle.Render(summary))
 		}
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Press Enter or q to exit."))
+		help := "Press Enter or q to exit"
+		if m.keepOpen && trace.Enabled() {
+			help += " · d commands"
+		}
+		b.WriteString(helpStyle.Render(help + "."))
 	} else {
 		b.WriteString(summaryPendingStyle.Render(summary))
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Running… press q to cancel."))
+		if m.quitting {
+			b.WriteString(helpStyle.Render("Cancelling… running teardown. Press q again to exit now."))
+		} else {
+			help := "Running… press q to cancel"
+			if trace.Enabled() {
+				help += " · d commands"
+			}
+			b.WriteString(helpStyle.Render(help + "."))
+		}
 	}
 
-	return lipgloss.NewStyle().Width(m.width).Render(strings.TrimSuffix(b.String(), "\n"))
+	steps := strings.TrimSuffix(b.String(), "\n")
+	if !m.showDebug {
+		return lipgloss.NewStyle().Width(m.width).Render(steps)
+	}
+	if m.width < paneMinWidth {
+		return lipgloss.NewStyle().Width(m.width).Render(steps + "\n\n" + m.renderDebug(m.width))
+	}
+	paneWidth := min(60, m.width*2/5)
+	left := lipgloss.NewStyle().Width(m.width - paneWidth - 3).Render(steps)
+	right := paneStyle.Width(paneWidth).Render(m.renderDebug(paneWidth))
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, "   ", right)
+}
+
+// renderPhase lists ungraded setup or teardown commands under their own heading.
+func (m *Model) renderPhase(b *strings.Builder, heading string, phase run.Phase, steps []run.Step, results []run.StepResult) {
+	if len(steps) == 0 {
+		return
+	}
+	b.WriteString(sectionStyle.Render(heading))
+	b.WriteString("\n")
+	for i, step := range steps {
+		var line string
+		switch {
+		case i < len(results) && !results[i].Passed:
+			line = failStyle.Render(fmt.Sprintf("%s %s", failGlyph, step.Command)) + "\n  " + failDetailStyle.Render(strings.Join(results[i].Failures, "; "))
+		case i < len(results):
+			line = hookDoneStyle.Render(fmt.Sprintf("%s %s", successGlyph, step.Command))
+		case !m.done && m.phase == phase && i == len(results):
+			line = pendingStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), step.Command))
+		default:
+			line = pendingStyle.Render(fmt.Sprintf("%s %s", pendingGlyph, step.Command))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 }
 
 func summarizeStdout(out string) string {
@@ -168,56 +295,88 @@ func stepDescription(step run.Step) string {
 	return fmt.Sprintf("Expecting stdout to include: %s", strings.Join(step.ExpectStdout, ", "))
 }
 
-func runStepCmd(script *run.Script, index int) tea.Cmd {
-	if index >= len(script.Steps) {
-		return nil
-	}
-	step := script.Steps[index]
+func (m *Model) runStepCmd(phase run.Phase, step run.Step) tea.Cmd {
+	policy := m.script.EffectivePolicy()
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-		result := run.RunStep(ctx, step)
-		return stepResultMsg{result: result}
+		result := run.RunStepIn(context.Background(), m.dir, policy, step)
+		result.Phase = phase
+		return stepResultMsg{owner: m, result: result}
 	}
 }
 
-// Results returns the collected step outcomes.
+// SetDir runs the script's commands in dir instead of the current directory.
+func (m *Model) SetDir(dir string) {
+	m.dir = dir
+}
+
+// SetKeepOpen controls whether the view stays up after the last step,
+// until Enter or q. NewModel turns it on when tracing is enabled.
+func (m *Model) SetKeepOpen(keepOpen bool) {
+	m.keepOpen = keepOpen
+}
+
+// PhaseResults returns the setup and teardown outcomes collected so far.
+func (m *Model) PhaseResults() (setup, teardown []run.StepResult) {
+	setup = append([]run.StepResult(nil), m.setup...)
+	teardown = append([]run.StepResult(nil), m.teardown...)
+	return setup, teardown
+}
+
+// Results returns the collected graded step outcomes.
 func (m *Model) Results() []run.StepResult {
 	out := make([]run.StepResult, len(m.results))
 	copy(out, m.results)
 	return out
 }
 
+// Score returns the graded steps passed so far.
+func (m *Model) Score() run.Score {
+	return run.Grade(m.script, m.results)
+}
+
+// Done reports whether every phase finished without being cancelled.
+func (m *Model) Done() bool {
+	return m.done && !m.quitting
+}
+
 // AllPassed reports whether every step succeeded.
 func (m *Model) AllPassed() bool {
-	if len(m.results) != len(m.script.Steps) {
-		return false
-	}
-	for _, res := range m.results {
-		if !res.Passed {
-			return false
-		}
-	}
-	return true
+	return run.AllPassed(m.script, m.results)
 }
 
 var (
-	successGlyph = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Render("✔")
-	failGlyph    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("✘")
-	pendingGlyph = lipgloss.NewStyle().Foreground(lipgloss.Color("248")).Render("•")
+	successGlyph = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}), "✔"}
+	failGlyph    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}), "✘"}
+	pendingGlyph = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "243", Dark: "248"}), "•"}
 
-	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("147")).MarginBottom(1)
-	descStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).MarginBottom(1)
+	descStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"})
 
-	passStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Bold(true)
-	failStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
-	pendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Bold(true)
+	passStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}).Bold(true)
+	failStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
+	pendingStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Bold(true)
 
-	detailStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	failDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
+	detailStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"})
+	failDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
 
-	summaryPassStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Bold(true)
-	summaryFailStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
-	summaryPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Bold(true)
-	helpStyle           = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Faint(true)
+	summaryPassStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}).Bold(true)
+	summaryFailStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
+	summaryPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Bold(true)
+	helpStyle           = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Faint(true)
+
+	sectionStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).Underline(true)
+	hookDoneStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"})
+	timingStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"})
+	paneStyle     = lipgloss.NewStyle()
 )
+
+// glyph is a colored status symbol. It renders when printed so color and
+// theme settings applied at startup take effect.
+type glyph struct {
+	style  lipgloss.Style
+	symbol string
+}
+
+func (g glyph) String() string {
+	return g.style.Render(g.symbol)
+}
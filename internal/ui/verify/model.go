@@ -1,6 +1,8 @@
 package verifyui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,44 +12,168 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/graph"
 	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/run"
+	"github.com/rohit746/tscgit/internal/trace"
 	"github.com/rohit746/tscgit/internal/verify"
 )
 
 // Model drives the Bubble Tea verification view.
 type Model struct {
-	lesson  *lessons.Lesson
-	repo    *gitutil.Repository
-	spinner spinner.Model
-	results []verify.Result
-	width   int
-	done    bool
+	lesson   *lessons.Lesson
+	repo     *gitutil.Repository
+	spinner  spinner.Model
+	results  []verify.Result
+	setup    []verify.HookResult
+	teardown []verify.HookResult
+	stage    stage
+	events   chan tea.Msg
+	abandon  chan struct{}
+	cancel   context.CancelFunc
+	quitting bool
+	debug    bool
+	width    int
+	done     bool
+	// keepOpen leaves the view up after the run finishes so the panes,
+	// in particular the debug pane, can still be read.
+	keepOpen bool
+
+	// pane is the side pane on display; showPane hides it when false.
+	pane      lessons.Panel
+	showPane  bool
+	graph     []graph.Node
+	graphErr  error
+	status    *gitutil.Status
+	tracked   []string
+	statusErr error
 }
 
+// stage tracks which phase of the lesson is currently executing.
+type stage int
+
+const (
+	stageSetup stage = iota
+	stageChecks
+	stageTeardown
+)
+
+// Messages carry the model that produced them so a stale message from an
+// earlier model is ignored when several run in the same program.
 type checkResultMsg struct {
+	owner  *Model
 	result verify.Result
 }
 
+type hookResultMsg struct {
+	owner  *Model
+	result verify.HookResult
+}
+
+type runDoneMsg struct {
+	owner *Model
+}
+
+// snapshotMsg carries the repository state shown in the side panes.
+type snapshotMsg struct {
+	owner     *Model
+	nodes     []graph.Node
+	graphErr  error
+	status    *gitutil.Status
+	tracked   []string
+	statusErr error
+}
+
+// graphLimit bounds how many commits the history pane loads.
+const graphLimit = 40
+
+// paneMinWidth is the terminal width needed to show the side pane beside the
+// checks; narrower terminals stack it below them.
+const paneMinWidth = 100
+
 // NewModel constructs the verification UI model.
 func NewModel(lesson *lessons.Lesson, repo *gitutil.Repository) *Model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"})
 
 	return &Model{
-		lesson:  lesson,
-		repo:    repo,
-		spinner: sp,
+		lesson:   lesson,
+		repo:     repo,
+		spinner:  sp,
+		pane:     lesson.Panel,
+		showPane: true,
+		keepOpen: trace.Enabled(),
 	}
 }
 
-// Init starts the spinner and kicks off the first check.
+// Init starts the spinner and launches verify.Run in the background. Its
+// results stream back to Update through the events channel.
 func (m *Model) Init() tea.Cmd {
-	if len(m.lesson.Checks) == 0 {
-		m.done = true
-		return tea.Quit
+	if len(m.lesson.Setup) == 0 {
+		m.stage = stageChecks
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.events = make(chan tea.Msg)
+	m.abandon = make(chan struct{})
+	emitter := channelEmitter{owner: m, events: m.events, abandon: m.abandon}
+	go func() {
+		defer close(m.events)
+		_, _ = verify.Run(ctx, m.lesson, m.repo, emitter)
+	}()
+	return tea.Batch(m.spinner.Tick, m.waitForEvent(), m.loadSnapshot())
+}
+
+// loadSnapshot reads the history and the index/working tree status for the
+// side panes.
+func (m *Model) loadSnapshot() tea.Cmd {
+	repo := m.repo
+	return func() tea.Msg {
+		ctx, cancel := lessons.TimeoutContext()
+		defer cancel()
+		msg := snapshotMsg{owner: m}
+		msg.nodes, msg.graphErr = graph.Load(ctx, repo, graphLimit)
+		msg.status, msg.statusErr = repo.Status(ctx)
+		if msg.statusErr == nil {
+			msg.tracked, msg.statusErr = repo.TrackedFiles(ctx)
+		}
+		return msg
+	}
+}
+
+// channelEmitter forwards check and hook results to the UI. Once the UI
+// stops listening, abandon is closed so the runner never blocks on a send.
+type channelEmitter struct {
+	owner   *Model
+	events  chan<- tea.Msg
+	abandon <-chan struct{}
+}
+
+func (c channelEmitter) Emit(res verify.Result) {
+	c.send(checkResultMsg{owner: c.owner, result: res})
+}
+
+func (c channelEmitter) EmitHook(res verify.HookResult) {
+	c.send(hookResultMsg{owner: c.owner, result: res})
+}
+
+func (c channelEmitter) send(msg tea.Msg) {
+	select {
+	case c.events <- msg:
+	case <-c.abandon:
+	}
+}
+
+func (m *Model) waitForEvent() tea.Cmd {
+	events := m.events
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return runDoneMsg{owner: m}
+		}
+		return msg
 	}
-	return tea.Batch(m.spinner.Tick, runCheckCmd(m.lesson, m.repo, len(m.results)))
 }
 
 // Update handles Bubble Tea messages.
@@ -66,25 +192,146 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
-			return m, tea.Quit
+			// Cancelling lets verify.Run finish the in-flight checks and run
+			// teardown hooks; a second interrupt exits immediately.
+			if m.done || m.quitting || len(m.lesson.Teardown) == 0 {
+				m.stop()
+				return m, tea.Quit
+			}
+			m.quitting = true
+			m.cancel()
+			return m, nil
 		case "enter":
 			if m.done {
 				return m, tea.Quit
 			}
+		case "g":
+			m.togglePane(lessons.PanelGraph)
+		case "t":
+			m.togglePane(lessons.PanelTrees)
+		case "d":
+			if trace.Enabled() {
+				m.togglePane(paneDebug)
+			}
 		}
 		return m, nil
 	case checkResultMsg:
+		if msg.owner != m {
+			return m, nil
+		}
 		m.results = append(m.results, msg.result)
 		if len(m.results) == len(m.lesson.Checks) {
-			m.done = true
+			m.stage = stageTeardown
+		}
+		return m, m.waitForEvent()
+	case hookResultMsg:
+		if msg.owner != m {
+			return m, nil
+		}
+		if msg.result.Phase == run.PhaseSetup {
+			m.setup = append(m.setup, msg.result)
+			if len(m.setup) == len(m.lesson.Setup) {
+				m.stage = stageChecks
+			}
+		} else {
+			m.stage = stageTeardown
+			m.teardown = append(m.teardown, msg.result)
+		}
+		return m, m.waitForEvent()
+	case runDoneMsg:
+		if msg.owner != m {
+			return m, nil
+		}
+		m.done = true
+		m.stop()
+		// Hooks may have changed the repository, so refresh the panes
+		// before exiting.
+		return m, m.loadSnapshot()
+	case snapshotMsg:
+		if msg.owner != m {
+			return m, nil
+		}
+		m.graph, m.graphErr = msg.nodes, msg.graphErr
+		m.status, m.tracked, m.statusErr = msg.status, msg.tracked, msg.statusErr
+		if m.done && (!m.keepOpen || m.quitting) {
 			return m, tea.Quit
 		}
-		return m, tea.Batch(m.spinner.Tick, runCheckCmd(m.lesson, m.repo, len(m.results)))
+		return m, nil
 	default:
 		return m, nil
 	}
 }
 
+// togglePane shows pane, or hides the side pane if pane is already shown.
+func (m *Model) togglePane(pane lessons.Panel) {
+	if m.showPane && m.currentPane() == pane {
+		m.showPane = false
+		return
+	}
+	m.pane, m.showPane = pane, true
+}
+
+func (m *Model) currentPane() lessons.Panel {
+	if m.pane == "" {
+		return lessons.PanelGraph
+	}
+	return m.pane
+}
+
+// SetKeepOpen controls whether the view stays up after the run finishes,
+// until Enter or q. NewModel turns it on when tracing is enabled.
+func (m *Model) SetKeepOpen(keepOpen bool) {
+	m.keepOpen = keepOpen
+}
+
+// SetDebug controls whether raw errors and panic stack traces are shown
+// alongside the student-facing explanation.
+func (m *Model) SetDebug(debug bool) {
+	m.debug = debug
+}
+
+func (m *Model) describe(err error) string {
+	msg := verify.Describe(err)
+	if !m.debug {
+		return msg
+	}
+	if raw := err.Error(); raw != msg {
+		msg += "\n  " + raw
+	}
+	var panicErr *verify.PanicError
+	if errors.As(err, &panicErr) {
+		msg += "\n" + string(panicErr.Stack)
+	}
+	return msg
+}
+
+// weightLabel annotates checks that are not worth exactly one required point.
+func weightLabel(check lessons.Check) string {
+	switch {
+	case check.Optional:
+		return weightStyle.Render(" (optional)")
+	case check.Bonus:
+		return weightStyle.Render(fmt.Sprintf(" (+%d bonus)", check.Points()))
+	case check.Points() != 1:
+		return weightStyle.Render(fmt.Sprintf(" (%d pts)", check.Points()))
+	}
+	return ""
+}
+
+// stop cancels the background run and releases it from delivering further
+// results. It is safe to call more than once.
+func (m *Model) stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	select {
+	case <-m.abandon:
+	default:
+		close(m.abandon)
+	}
+}
+
 // View renders the verification UI.
 func (m *Model) View() string {
 	var b strings.Builder
@@ -94,26 +341,32 @@ func (m *Model) View() string {
 	b.WriteString(descStyle.Render(m.lesson.Description))
 	b.WriteString("\n\n")
 
+	m.renderHooks(&b, "Setup", m.lesson.Setup, m.setup, m.stage == stageSetup)
+
 	for i, check := range m.lesson.Checks {
 		var status string
 		var detail string
 
 		if i < len(m.results) {
 			res := m.results[i]
-			passed := res.Outcome.Passed && res.Outcome.Err == nil
+			passed := res.Passed()
 			if res.Outcome.Err != nil {
-				detail = res.Outcome.Err.Error()
+				detail = m.describe(res.Outcome.Err)
 			} else if res.Outcome.Message != "" {
 				detail = res.Outcome.Message
 			} else {
 				detail = "Completed."
 			}
 
-			if passed {
-				status = passStyle.Render(fmt.Sprintf("%s %s", successGlyph, check.Title))
+			switch {
+			case passed:
+				status = passStyle.Render(fmt.Sprintf("%s %s", successGlyph, check.Title)) + weightLabel(check)
 				detail = detailStyle.Render(detail)
-			} else {
-				status = failStyle.Render(fmt.Sprintf("%s %s", failGlyph, check.Title))
+			case res.Skipped:
+				status = skipStyle.Render(fmt.Sprintf("%s %s", skipGlyph, check.Title)) + weightLabel(check)
+				detail = warnDetailStyle.Render(detail)
+			default:
+				status = failStyle.Render(fmt.Sprintf("%s %s", failGlyph, check.Title)) + weightLabel(check)
 				if res.Outcome.Err != nil {
 					detail = failDetailStyle.Render(detail)
 				} else {
@@ -121,15 +374,17 @@ func (m *Model) View() string {
 				}
 			}
 
-			duration := lipgloss.NewStyle().Faint(true).Render(res.Duration.Round(10 * time.Millisecond).String())
 			b.WriteString(status)
-			b.WriteString(" " + duration)
+			if !res.Skipped {
+				duration := lipgloss.NewStyle().Faint(true).Render(res.Duration.Round(10 * time.Millisecond).String())
+				b.WriteString(" " + duration)
+			}
 		} else {
-			if !m.done && i == len(m.results) {
-				status = pendingStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), check.Title))
+			if !m.done && !m.quitting && m.stage == stageChecks {
+				status = pendingStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), check.Title)) + weightLabel(check)
 				detail = detailStyle.Render(check.Description)
 			} else {
-				status = pendingStyle.Render(fmt.Sprintf("%s %s", pendingGlyph, check.Title))
+				status = pendingStyle.Render(fmt.Sprintf("%s %s", pendingGlyph, check.Title)) + weightLabel(check)
 				detail = detailStyle.Render(check.Description)
 			}
 			b.WriteString(status)
@@ -142,51 +397,138 @@ func (m *Model) View() string {
 		b.WriteString("\n\n")
 	}
 
+	m.renderHooks(&b, "Teardown", m.lesson.Teardown, m.teardown, m.stage == stageTeardown)
+
 	passed := 0
 	for _, res := range m.results {
-		if res.Outcome.Passed && res.Outcome.Err == nil {
+		if res.Passed() {
 			passed++
 		}
 	}
 	total := len(m.lesson.Checks)
 
-	summary := fmt.Sprintf("%d/%d checks passed", passed, total)
+	score := verify.Grade(m.lesson, m.results)
+	summary := fmt.Sprintf("%d/%d checks passed · %d/%d points (%.0f%%)", passed, total, score.Points, score.Max, score.Percent)
 	if m.done {
-		if passed == total {
+		if m.AllPassed() {
 			b.WriteString(summaryPassStyle.Render(summary))
 		} else {
 			b.WriteString(summaryFailStyle.Render(summary))
 		}
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Press Enter or q to exit."))
+		help := "Press Enter or q to exit"
+		if m.keepOpen {
+			help += " · g history graph · t three trees"
+			if trace.Enabled() {
+				help += " · d commands"
+			}
+		}
+		b.WriteString(helpStyle.Render(help + "."))
 	} else {
 		b.WriteString(summaryPendingStyle.Render(summary))
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Verifying… press q to cancel."))
+		if m.quitting {
+			b.WriteString(helpStyle.Render("Cancelling… running teardown. Press q again to exit now."))
+		} else {
+			help := "Verifying… press q to cancel · g history graph · t three trees"
+			if trace.Enabled() {
+				help += " · d commands"
+			}
+			b.WriteString(helpStyle.Render(help + "."))
+		}
 	}
 
-	return lipgloss.NewStyle().Width(m.width).Render(strings.TrimSuffix(b.String(), "\n"))
+	checks := strings.TrimSuffix(b.String(), "\n")
+	if !m.showPane {
+		return lipgloss.NewStyle().Width(m.width).Render(checks)
+	}
+	if m.width < paneMinWidth {
+		pane := m.renderPane(m.width)
+		return lipgloss.NewStyle().Width(m.width).Render(checks + "\n\n" + pane)
+	}
+	paneWidth := min(60, m.width*2/5)
+	left := lipgloss.NewStyle().Width(m.width - paneWidth - 3).Render(checks)
+	right := paneStyle.Width(paneWidth).Render(m.renderPane(paneWidth))
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, "   ", right)
 }
 
-func runCheckCmd(lesson *lessons.Lesson, repo *gitutil.Repository, index int) tea.Cmd {
-	if index >= len(lesson.Checks) {
-		return nil
+func (m *Model) renderPane(width int) string {
+	switch m.currentPane() {
+	case lessons.PanelTrees:
+		return m.renderTrees(width)
+	case paneDebug:
+		return m.renderDebug(width)
 	}
-	check := lesson.Checks[index]
+	return m.renderGraph(width)
+}
 
-	return func() tea.Msg {
-		ctx, cancel := lessons.TimeoutContext()
-		defer cancel()
+// renderGraph draws the repository history and, when the lesson provides
+// one, the target history students should end up with.
+func (m *Model) renderGraph(width int) string {
+	var b strings.Builder
+	clip := lipgloss.NewStyle().MaxWidth(max(width, 20))
+
+	b.WriteString(sectionStyle.Render("Your history"))
+	b.WriteString("\n")
+	switch {
+	case m.graphErr != nil:
+		b.WriteString(failDetailStyle.Render(m.describe(m.graphErr)))
+		b.WriteString("\n")
+	case m.graph == nil:
+		b.WriteString(pendingStyle.Render("Loading…"))
+		b.WriteString("\n")
+	case len(m.graph) == 0:
+		b.WriteString(detailStyle.Render("No commits yet."))
+		b.WriteString("\n")
+	default:
+		for _, line := range graph.Render(m.graph, graphStyle) {
+			b.WriteString(clip.Render(line))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(m.lesson.Target) > 0 {
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("Target history"))
+		b.WriteString("\n")
+		for _, line := range graph.Render(m.lesson.Target, graphStyle) {
+			b.WriteString(clip.Render(line))
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
 
-		start := time.Now()
-		outcome := check.Verify(ctx, repo)
-		res := verify.Result{
-			Check:    check,
-			Outcome:  outcome,
-			Duration: time.Since(start),
+// renderHooks lists ungraded setup or teardown hooks under their own heading.
+func (m *Model) renderHooks(b *strings.Builder, heading string, hooks []lessons.Hook, results []verify.HookResult, active bool) {
+	if len(hooks) == 0 {
+		return
+	}
+	b.WriteString(sectionStyle.Render(heading))
+	b.WriteString("\n")
+	for i, hook := range hooks {
+		var line string
+		switch {
+		case i < len(results) && results[i].Err != nil:
+			line = failStyle.Render(fmt.Sprintf("%s %s", failGlyph, hook.Title)) + "\n  " + failDetailStyle.Render(m.describe(results[i].Err))
+		case i < len(results):
+			line = hookDoneStyle.Render(fmt.Sprintf("%s %s", successGlyph, hook.Title))
+		case active && !m.done && i == len(results):
+			line = pendingStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), hook.Title))
+		default:
+			line = pendingStyle.Render(fmt.Sprintf("%s %s", pendingGlyph, hook.Title))
 		}
-		return checkResultMsg{result: res}
+		b.WriteString(line)
+		b.WriteString("\n")
 	}
+	b.WriteString("\n")
+}
+
+// HookResults returns the setup and teardown outcomes collected so far.
+func (m *Model) HookResults() (setup, teardown []verify.HookResult) {
+	setup = append([]verify.HookResult(nil), m.setup...)
+	teardown = append([]verify.HookResult(nil), m.teardown...)
+	return setup, teardown
 }
 
 // Results returns a snapshot of the check outcomes collected so far.
@@ -196,37 +538,70 @@ func (m *Model) Results() []verify.Result {
 	return out
 }
 
-// AllPassed reports whether every check finished successfully.
+// Done reports whether every phase finished without being cancelled.
+func (m *Model) Done() bool {
+	return m.done && !m.quitting
+}
+
+// AllPassed reports whether every required check finished successfully.
 func (m *Model) AllPassed() bool {
-	if len(m.results) != len(m.lesson.Checks) {
-		return false
-	}
-	for _, res := range m.results {
-		if !res.Outcome.Passed || res.Outcome.Err != nil {
-			return false
-		}
-	}
-	return true
+	return verify.AllPassed(m.lesson, m.results)
+}
+
+// Score returns the points earned so far.
+func (m *Model) Score() verify.Score {
+	return verify.Grade(m.lesson, m.results)
 }
 
 var (
-	successGlyph = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Render("✔")
-	failGlyph    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("✘")
-	pendingGlyph = lipgloss.NewStyle().Foreground(lipgloss.Color("248")).Render("•")
+	successGlyph = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}), "✔"}
+	failGlyph    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}), "✘"}
+	pendingGlyph = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "243", Dark: "248"}), "•"}
+	skipGlyph    = glyph{lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"}), "⊘"}
+
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).MarginBottom(1)
+	descStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"})
 
-	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("147")).MarginBottom(1)
-	descStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	passStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}).Bold(true)
+	failStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
+	pendingStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Bold(true)
+	skipStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"}).Bold(true)
 
-	passStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Bold(true)
-	failStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
-	pendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Bold(true)
+	detailStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"})
+	warnDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"})
+	failDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
 
-	detailStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	warnDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	failDetailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
+	summaryPassStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}).Bold(true)
+	summaryFailStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "160", Dark: "203"}).Bold(true)
+	summaryPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Bold(true)
+	helpStyle           = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"}).Faint(true)
 
-	summaryPassStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("84")).Bold(true)
-	summaryFailStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Bold(true)
-	summaryPendingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Bold(true)
-	helpStyle           = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Faint(true)
+	weightStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"})
+	sectionStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"}).Underline(true)
+	hookDoneStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "240", Dark: "245"})
+
+	paneStyle       = lipgloss.NewStyle()
+	treeHeaderStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"}).Bold(true)
+	graphStyle      = graph.Style{
+		Node:  renderWith(lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "55", Dark: "147"})),
+		Head:  renderWith(lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "162", Dark: "205"}).Bold(true)),
+		Edge:  renderWith(lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "244"})),
+		Hash:  renderWith(lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "130", Dark: "214"})),
+		Label: renderWith(lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "28", Dark: "84"}).Bold(true)),
+	}
 )
+
+func renderWith(style lipgloss.Style) func(string) string {
+	return func(s string) string { return style.Render(s) }
+}
+
+// glyph is a colored status symbol. It renders when printed so color and
+// theme settings applied at startup take effect.
+type glyph struct {
+	style  lipgloss.Style
+	symbol string
+}
+
+func (g glyph) String() string {
+	return g.style.Render(g.symbol)
+}
@@ -0,0 +1,146 @@
+package verifyui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+// treeRow is one path as it appears in HEAD, the index and the working tree.
+type treeRow struct {
+	path                  string
+	head, index, worktree string
+	staged, unstaged      bool
+	conflict              bool
+}
+
+// threeTrees turns a status into rows for the three trees pane and counts
+// the tracked files that are identical everywhere.
+func threeTrees(status *gitutil.Status, tracked []string) (rows []treeRow, unchanged int) {
+	changed := map[string]bool{}
+	for _, e := range status.Entries {
+		row := treeRow{path: e.Path}
+		switch e.Kind {
+		case gitutil.EntryIgnored:
+			continue
+		case gitutil.EntryUntracked:
+			row.head, row.index, row.worktree = "–", "–", "new"
+			row.unstaged = true
+		case gitutil.EntryUnmerged:
+			row.head, row.index, row.worktree = "✓", "conflict", "conflict"
+			row.conflict = true
+		default:
+			row.head = "✓"
+			if e.Index == gitutil.StatusAdded {
+				row.head = "–"
+			}
+			if e.Kind == gitutil.EntryRenamed {
+				row.path = e.Path + " ← " + e.OrigPath
+			}
+			row.index = codeLabel(e.Index)
+			row.worktree = codeLabel(e.Worktree)
+			row.staged, row.unstaged = e.Staged(), e.Unstaged()
+		}
+		changed[e.Path] = true
+		rows = append(rows, row)
+	}
+	for _, f := range tracked {
+		if !changed[f] {
+			unchanged++
+		}
+	}
+	return rows, unchanged
+}
+
+func codeLabel(c gitutil.StatusCode) string {
+	switch c {
+	case gitutil.StatusUnmodified:
+		return "="
+	case gitutil.StatusModified:
+		return "modified"
+	case gitutil.StatusAdded:
+		return "added"
+	case gitutil.StatusDeleted:
+		return "deleted"
+	case gitutil.StatusRenamed:
+		return "renamed"
+	case gitutil.StatusCopied:
+		return "copied"
+	case gitutil.StatusTypeChange:
+		return "type"
+	default:
+		return c.String()
+	}
+}
+
+// renderTrees draws the HEAD, index and working tree columns for every path
+// that differs between them.
+func (m *Model) renderTrees(width int) string {
+	var b strings.Builder
+	b.WriteString(sectionStyle.Render("Three trees"))
+	b.WriteString("\n")
+
+	switch {
+	case m.statusErr != nil:
+		b.WriteString(failDetailStyle.Render(m.describe(m.statusErr)))
+		return b.String()
+	case m.status == nil:
+		b.WriteString(pendingStyle.Render("Loading…"))
+		return b.String()
+	}
+
+	rows, unchanged := threeTrees(m.status, m.tracked)
+	const cell = 9
+	pathWidth := len("path")
+	for _, r := range rows {
+		pathWidth = max(pathWidth, lipgloss.Width(r.path))
+	}
+	pathWidth = max(min(pathWidth, width-3*(cell+1)), 8)
+
+	header := fmt.Sprintf("%-*s %-*s %-*s %s", pathWidth, "path", cell, "HEAD", cell, "index", "worktree")
+	b.WriteString(treeHeaderStyle.Render(header))
+	b.WriteString("\n")
+	if len(rows) == 0 {
+		b.WriteString(detailStyle.Render("Working tree clean: all three trees match."))
+		b.WriteString("\n")
+	}
+	for _, r := range rows {
+		index, worktree := padCell(r.index, cell), padCell(r.worktree, cell)
+		switch {
+		case r.conflict:
+			index, worktree = failStyle.Render(index), failStyle.Render(worktree)
+		default:
+			if r.staged {
+				index = passStyle.Render(index)
+			}
+			if r.unstaged {
+				worktree = warnDetailStyle.Render(worktree)
+			}
+		}
+		fmt.Fprintf(&b, "%s %s %s %s\n", padCell(truncate(r.path, pathWidth), pathWidth), padCell(r.head, cell), index, worktree)
+	}
+	if unchanged > 0 {
+		b.WriteString(detailStyle.Render(fmt.Sprintf("+ %d unchanged tracked file(s)", unchanged)))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("= same as the tree to its left · – not present"))
+	return b.String()
+}
+
+func padCell(s string, width int) string {
+	if n := lipgloss.Width(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,84 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime/debug"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+)
+
+// PanicError records a panic recovered while running a check or hook.
+type PanicError struct {
+	ID    string
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked: %v", e.ID, e.Value)
+}
+
+// ErrorKind classifies check errors so the UI can explain them.
+type ErrorKind string
+
+const (
+	KindGitMissing ErrorKind = "git-missing"
+	KindNotRepo    ErrorKind = "not-a-repository"
+	KindNoCommits  ErrorKind = "no-commits"
+	KindTimeout    ErrorKind = "timeout"
+	KindInternal   ErrorKind = "internal"
+	KindUnknown    ErrorKind = "unknown"
+)
+
+// Classify maps err to an ErrorKind. It returns the empty kind for nil.
+func Classify(err error) ErrorKind {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &panicErr):
+		return KindInternal
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.Is(err, exec.ErrNotFound):
+		return KindGitMissing
+	case gitutil.IsNotRepository(err):
+		return KindNotRepo
+	case gitutil.IsNoCommits(err):
+		return KindNoCommits
+	default:
+		return KindUnknown
+	}
+}
+
+// Describe returns a student-facing explanation of err. Unclassified errors
+// are returned verbatim.
+func Describe(err error) string {
+	switch Classify(err) {
+	case "":
+		return ""
+	case KindGitMissing:
+		return "Git is not installed or not on your PATH. Install it from https://git-scm.com and reopen your terminal."
+	case KindNotRepo:
+		return "This folder is not a Git repository. Run git init, cd into your practice repo, or pass --path."
+	case KindNoCommits:
+		return "This repository has no commits yet. Stage a file with git add and run git commit first."
+	case KindTimeout:
+		return "Git took too long to answer. Try again; if it keeps happening, rerun with --debug and share the output."
+	case KindInternal:
+		return "This check crashed inside tscgit. Please report it along with the output of --debug."
+	default:
+		return err.Error()
+	}
+}
+
+// recoverCheck converts a panic in the calling goroutine into a PanicError
+// stored in *err. It must be deferred directly for recover to take effect.
+func recoverCheck(id string, err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{ID: id, Value: r, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+)
+
+func TestRunRecoversPanickingCheck(t *testing.T) {
+	lesson := &lessons.Lesson{
+		ID: "panics",
+		Checks: []lessons.Check{
+			{
+				ID: "nil-deref",
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					var repo *gitutil.Repository
+					return lessons.CheckResult{Message: repo.Root}
+				},
+			},
+			{
+				ID: "after",
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					return lessons.CheckResult{Passed: true}
+				},
+			},
+		},
+		Teardown: []lessons.Hook{{ID: "boom", Run: func(context.Context, *gitutil.Repository) error {
+			panic("teardown exploded")
+		}}},
+	}
+
+	rec := &hookRecorder{}
+	results, err := Run(context.Background(), lesson, &gitutil.Repository{}, rec)
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	var panicErr *PanicError
+	if !errors.As(results[0].Outcome.Err, &panicErr) {
+		t.Fatalf("expected PanicError, got %v", results[0].Outcome.Err)
+	}
+	if !strings.Contains(string(panicErr.Stack), "errors_test.go") {
+		t.Fatalf("expected stack trace to point at the check")
+	}
+	if !results[1].Passed() {
+		t.Fatalf("expected later checks to keep running")
+	}
+	if len(rec.hooks) != 1 || Classify(rec.hooks[0].Err) != KindInternal {
+		t.Fatalf("expected teardown panic to be recovered, got %+v", rec.hooks)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{nil, ""},
+		{&PanicError{ID: "x", Value: "boom"}, KindInternal},
+		{fmt.Errorf("git command interrupted: %w", context.DeadlineExceeded), KindTimeout},
+		{fmt.Errorf("git command failed: %w", &exec.Error{Name: "git", Err: exec.ErrNotFound}), KindGitMissing},
+		{fmt.Errorf("gitutil: %w", gitutil.ErrNotRepository), KindNotRepo},
+		{fmt.Errorf("count commits: %w", gitutil.ErrNoCommits), KindNoCommits},
+		{errors.New("fatal: not a git repository (or any of the parent directories): .git"), KindUnknown},
+		{errors.New("something else"), KindUnknown},
+	}
+	for _, tc := range cases {
+		if got := Classify(tc.err); got != tc.want {
+			t.Errorf("Classify(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestDescribeHints(t *testing.T) {
+	cases := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("gitutil: %w", gitutil.ErrNotRepository), "This folder is not a Git repository. Run git init, cd into your practice repo, or pass --path."},
+		{fmt.Errorf("git command interrupted: %w", context.DeadlineExceeded), "Git took too long to answer. Try again; if it keeps happening, rerun with --debug and share the output."},
+		{&PanicError{ID: "x", Value: "boom"}, "This check crashed inside tscgit. Please report it along with the output of --debug."},
+		{errors.New("something else"), "something else"},
+	}
+	for _, tc := range cases {
+		if got := Describe(tc.err); got != tc.want {
+			t.Errorf("Describe(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
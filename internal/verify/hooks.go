@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rohit746/tscgit/internal/gitutil"
+	"github.com/rohit746/tscgit/internal/lessons"
+	"github.com/rohit746/tscgit/internal/run"
+)
+
+// ErrSetupFailed is returned by Run when a setup hook fails and the checks
+// were skipped. It is the error run scripts report, so callers can test for
+// either with one errors.Is.
+var ErrSetupFailed = run.ErrSetupFailed
+
+// Phase identifies whether a hook ran before or after the checks. Hooks use
+// run.PhaseSetup and run.PhaseTeardown.
+type Phase = run.Phase
+
+// HookResult captures the outcome of an ungraded setup or teardown hook.
+type HookResult struct {
+	Hook     lessons.Hook
+	Phase    Phase
+	Err      error
+	Duration time.Duration
+}
+
+// HookEmitter may be implemented by an Emitter that also wants to observe
+// setup and teardown hooks.
+type HookEmitter interface {
+	EmitHook(HookResult)
+}
+
+// RunHook executes a single hook and times it. Panics are recovered into a
+// PanicError.
+func RunHook(ctx context.Context, phase Phase, hook lessons.Hook, repo *gitutil.Repository) (res HookResult) {
+	start := time.Now()
+	res = HookResult{Hook: hook, Phase: phase}
+	defer func() { res.Duration = time.Since(start) }()
+	defer recoverCheck(hook.ID, &res.Err)
+	if hook.Run != nil {
+		res.Err = hook.Run(ctx, repo)
+	}
+	return res
+}
+
+func runSetup(ctx context.Context, lesson *lessons.Lesson, repo *gitutil.Repository, emitter Emitter) error {
+	for _, hook := range lesson.Setup {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		res := RunHook(ctx, run.PhaseSetup, hook, repo)
+		emitHook(emitter, res)
+		if res.Err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrSetupFailed, hook.ID, res.Err)
+		}
+	}
+	return nil
+}
+
+func runTeardown(ctx context.Context, lesson *lessons.Lesson, repo *gitutil.Repository, emitter Emitter) {
+	if len(lesson.Teardown) == 0 {
+		return
+	}
+	tctx, cancel := run.TeardownContext(ctx)
+	defer cancel()
+	for _, hook := range lesson.Teardown {
+		emitHook(emitter, RunHook(tctx, run.PhaseTeardown, hook, repo))
+	}
+}
+
+func emitHook(emitter Emitter, res HookResult) {
+	if he, ok := emitter.(HookEmitter); ok {
+		he.EmitHook(res)
+	}
+}
@@ -3,6 +3,7 @@ package verify
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/rohit746/tscgit/internal/gitutil"
@@ -19,6 +20,14 @@ type Result struct {
 	Check    lessons.Check
 	Outcome  lessons.CheckResult
 	Duration time.Duration
+	// Skipped is set when the check never ran because a check it depends on
+	// did not pass.
+	Skipped bool
+}
+
+// Passed reports whether the check ran and succeeded.
+func (r Result) Passed() bool {
+	return !r.Skipped && r.Outcome.Passed && r.Outcome.Err == nil
 }
 
 // Emitter receives results as they are produced.
@@ -26,34 +35,166 @@ type Emitter interface {
 	Emit(Result)
 }
 
-// Run executes each check in the provided lesson sequentially. The optional
-// emitter is invoked after every check with its result. Execution halts if the
-// context is cancelled.
+// Options tunes how checks are scheduled.
+type Options struct {
+	// Workers bounds how many checks execute concurrently. Values below one
+	// run checks sequentially.
+	Workers int
+	// CheckTimeout bounds each check individually. Zero leaves only the
+	// caller's context in charge.
+	CheckTimeout time.Duration
+}
+
+// DefaultOptions is used by Run.
+var DefaultOptions = Options{Workers: 4, CheckTimeout: lessons.CheckTimeout}
+
+// Run executes the lesson's checks with DefaultOptions. See RunWithOptions.
 func Run(ctx context.Context, lesson *lessons.Lesson, repo *gitutil.Repository, emitter Emitter) ([]Result, error) {
+	return RunWithOptions(ctx, lesson, repo, emitter, DefaultOptions)
+}
+
+// RunWithOptions executes the checks in the provided lesson. Checks whose
+// dependencies have passed run concurrently on a bounded worker pool; checks
+// with a failed or skipped dependency are reported as skipped without running.
+// Results are returned, and passed to the optional emitter, in lesson order
+// regardless of completion order. Setup hooks run first and teardown hooks
+// always run last; an emitter implementing HookEmitter also receives their
+// results. No new checks start once the context is cancelled.
+func RunWithOptions(ctx context.Context, lesson *lessons.Lesson, repo *gitutil.Repository, emitter Emitter, opts Options) ([]Result, error) {
 	if lesson == nil {
 		return nil, ErrLessonNil
 	}
 	if repo == nil {
 		return nil, ErrRepositoryNil
 	}
+	if err := lesson.Validate(); err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+
+	defer runTeardown(ctx, lesson, repo, emitter)
+	if err := runSetup(ctx, lesson, repo, emitter); err != nil {
+		return []Result{}, err
+	}
+
+	return schedule(ctx, lesson.Checks, repo, emitter, opts)
+}
+
+type finishedCheck struct {
+	index  int
+	result Result
+}
+
+func schedule(ctx context.Context, checks []lessons.Check, repo *gitutil.Repository, emitter Emitter, opts Options) ([]Result, error) {
+	n := len(checks)
+	index := make(map[string]int, n)
+	for i, check := range checks {
+		index[check.ID] = i
+	}
+	dependents := make([][]int, n)
+	waiting := make([]int, n)
+	var ready []int
+	for i, check := range checks {
+		for _, dep := range check.DependsOn {
+			dependents[index[dep]] = append(dependents[index[dep]], i)
+		}
+		waiting[i] = len(check.DependsOn)
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
 
-	results := make([]Result, 0, len(lesson.Checks))
+	workers := max(opts.Workers, 1)
+	finished := make(chan finishedCheck, workers)
+	completed := make([]*Result, n)
+	results := make([]Result, 0, n)
 
-	for _, check := range lesson.Checks {
-		select {
-		case <-ctx.Done():
-			return results, ctx.Err()
-		default:
+	var complete func(i int, res Result)
+	complete = func(i int, res Result) {
+		completed[i] = &res
+		for _, d := range dependents[i] {
+			waiting[d]--
+			if waiting[d] > 0 {
+				continue
+			}
+			if blocker := firstBlocker(checks[d], index, completed); blocker != "" {
+				complete(d, Result{
+					Check:   checks[d],
+					Outcome: lessons.CheckResult{Message: fmt.Sprintf("blocked by %s", blocker)},
+					Skipped: true,
+				})
+				continue
+			}
+			ready = append(ready, d)
 		}
+	}
+	flush := func() {
+		for len(results) < n && completed[len(results)] != nil {
+			res := *completed[len(results)]
+			results = append(results, res)
+			if emitter != nil {
+				emitter.Emit(res)
+			}
+		}
+	}
 
-		start := time.Now()
-		outcome := check.Verify(ctx, repo)
-		res := Result{Check: check, Outcome: outcome, Duration: time.Since(start)}
-		results = append(results, res)
-		if emitter != nil {
-			emitter.Emit(res)
+	running := 0
+	for {
+		for running < workers && len(ready) > 0 && ctx.Err() == nil {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func(i int) {
+				finished <- finishedCheck{index: i, result: runCheck(ctx, checks[i], repo, opts.CheckTimeout)}
+			}(i)
 		}
+		if running == 0 {
+			break
+		}
+		f := <-finished
+		running--
+		complete(f.index, f.result)
+		flush()
 	}
 
+	if len(results) < n {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+	}
 	return results, nil
 }
+
+func firstBlocker(check lessons.Check, index map[string]int, completed []*Result) string {
+	for _, dep := range check.DependsOn {
+		if res := completed[index[dep]]; res == nil || !res.Passed() {
+			return dep
+		}
+	}
+	return ""
+}
+
+// runCheck executes a single check. A panic inside the check is recovered
+// and reported as a PanicError so one broken check cannot take down the run.
+func runCheck(ctx context.Context, check lessons.Check, repo *gitutil.Repository, timeout time.Duration) (res Result) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	res.Check = check
+	var panicErr error
+	defer func() {
+		if panicErr != nil {
+			res.Outcome = lessons.CheckResult{Err: panicErr}
+		}
+		res.Duration = time.Since(start)
+	}()
+	defer recoverCheck(check.ID, &panicErr)
+	if check.Verify == nil {
+		res.Outcome = lessons.CheckResult{Err: fmt.Errorf("verify: check %s has no Verify function", check.ID)}
+		return res
+	}
+	res.Outcome = check.Verify(ctx, repo)
+	return res
+}
@@ -3,6 +3,7 @@ package verify
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/rohit746/tscgit/internal/gitutil"
@@ -65,3 +66,119 @@ func TestRunRequiresLessonAndRepo(t *testing.T) {
 		t.Fatalf("expected nil repo error, got %v", err)
 	}
 }
+
+type hookRecorder struct {
+	testEmitter
+	hooks []HookResult
+}
+
+func (h *hookRecorder) EmitHook(res HookResult) {
+	h.hooks = append(h.hooks, res)
+}
+
+func TestRunHooksSurroundChecks(t *testing.T) {
+	var order []string
+	hook := func(name string, err error) lessons.Hook {
+		return lessons.Hook{ID: name, Run: func(context.Context, *gitutil.Repository) error {
+			order = append(order, name)
+			return err
+		}}
+	}
+	lesson := &lessons.Lesson{
+		ID:       "hooks",
+		Setup:    []lessons.Hook{hook("setup", nil)},
+		Teardown: []lessons.Hook{hook("teardown", nil)},
+		Checks: []lessons.Check{{
+			ID: "check",
+			Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+				order = append(order, "check")
+				return lessons.CheckResult{Passed: true}
+			},
+		}},
+	}
+
+	rec := &hookRecorder{}
+	if _, err := Run(context.Background(), lesson, &gitutil.Repository{}, rec); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "setup,check,teardown" {
+		t.Fatalf("unexpected execution order: %s", got)
+	}
+	if len(rec.hooks) != 2 || len(rec.results) != 1 {
+		t.Fatalf("expected hooks and checks to be reported separately, got %d hooks and %d results", len(rec.hooks), len(rec.results))
+	}
+
+	order = nil
+	lesson.Setup = []lessons.Hook{hook("setup", errors.New("boom"))}
+	results, err := Run(context.Background(), lesson, &gitutil.Repository{}, rec)
+	if !errors.Is(err, ErrSetupFailed) {
+		t.Fatalf("expected setup failure, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected checks to be skipped, got %d results", len(results))
+	}
+	if got := strings.Join(order, ","); got != "setup,teardown" {
+		t.Fatalf("expected teardown after failed setup, got %s", got)
+	}
+}
+
+func TestRunSkipsDependentsAndKeepsOrder(t *testing.T) {
+	release := make(chan struct{})
+	lesson := &lessons.Lesson{
+		ID: "deps",
+		Checks: []lessons.Check{
+			{
+				ID: "slow",
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					<-release
+					return lessons.CheckResult{Passed: true}
+				},
+			},
+			{
+				ID: "base",
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					// Only reachable while slow is still running, proving the
+					// two independent checks execute concurrently.
+					close(release)
+					return lessons.CheckResult{Passed: false, Message: "missing"}
+				},
+			},
+			{
+				ID:        "dependent",
+				DependsOn: []string{"base"},
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					t.Error("dependent check should not run")
+					return lessons.CheckResult{}
+				},
+			},
+			{
+				ID:        "transitive",
+				DependsOn: []string{"dependent", "slow"},
+				Verify: func(context.Context, *gitutil.Repository) lessons.CheckResult {
+					t.Error("transitive check should not run")
+					return lessons.CheckResult{}
+				},
+			},
+		},
+	}
+
+	emitter := &testEmitter{}
+	results, err := RunWithOptions(context.Background(), lesson, &gitutil.Repository{}, emitter, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(emitter.results) != 4 {
+		t.Fatalf("expected 4 emitted results, got %d", len(emitter.results))
+	}
+	for i, want := range []string{"slow", "base", "dependent", "transitive"} {
+		if emitter.results[i].Check.ID != want || results[i].Check.ID != want {
+			t.Fatalf("result %d: expected %s, got %s", i, want, results[i].Check.ID)
+		}
+	}
+	if !results[2].Skipped || results[2].Outcome.Message != "blocked by base" {
+		t.Fatalf("expected dependent to be blocked by base, got %+v", results[2])
+	}
+	if !results[3].Skipped || results[3].Outcome.Message != "blocked by dependent" {
+		t.Fatalf("expected transitive to be blocked by dependent, got %+v", results[3])
+	}
+}
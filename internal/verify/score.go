@@ -0,0 +1,139 @@
+package verify
+
+import (
+	"time"
+
+	"github.com/rohit746/tscgit/internal/lessons"
+)
+
+// Score summarises the points earned by a verification run.
+type Score struct {
+	Points  int     `json:"points"`
+	Max     int     `json:"max"`
+	Percent float64 `json:"percent"`
+}
+
+// Grade totals the points earned by results against the maximum available in
+// lesson. Optional checks are ignored and bonus checks add points without
+// raising the maximum, so Points may exceed Max; Percent is capped at 100.
+// Checks missing from results, for example after cancellation, earn nothing.
+func Grade(lesson *lessons.Lesson, results []Result) Score {
+	var score Score
+	if lesson == nil {
+		return score
+	}
+	for _, check := range lesson.Checks {
+		if check.Required() {
+			score.Max += check.Points()
+		}
+	}
+	for _, res := range results {
+		if res.Check.Optional || !res.Passed() {
+			continue
+		}
+		score.Points += res.Check.Points()
+	}
+	switch {
+	case score.Max > 0:
+		score.Percent = min(100, float64(score.Points)*100/float64(score.Max))
+	case len(results) == len(lesson.Checks):
+		score.Percent = 100
+	}
+	return score
+}
+
+// AllPassed reports whether every required check in lesson has a passing
+// result. Optional and bonus checks are not considered.
+func AllPassed(lesson *lessons.Lesson, results []Result) bool {
+	if lesson == nil || len(results) != len(lesson.Checks) {
+		return false
+	}
+	for _, res := range results {
+		if res.Check.Required() && !res.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Status values used in reports.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusError   = "error"
+	StatusSkipped = "skipped"
+)
+
+// Report is the machine-readable summary of a verification run.
+type Report struct {
+	Lesson string        `json:"lesson"`
+	Title  string        `json:"title"`
+	Passed bool          `json:"passed"`
+	Score  Score         `json:"score"`
+	Checks []CheckReport `json:"checks"`
+}
+
+// CheckReport describes a single check within a Report.
+type CheckReport struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorKind  string `json:"errorKind,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	Points     int    `json:"points"`
+	Weight     int    `json:"weight"`
+	Optional   bool   `json:"optional,omitempty"`
+	Bonus      bool   `json:"bonus,omitempty"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// NewReport builds a Report from the results of running lesson.
+func NewReport(lesson *lessons.Lesson, results []Result) Report {
+	report := Report{
+		Passed: AllPassed(lesson, results),
+		Score:  Grade(lesson, results),
+		Checks: make([]CheckReport, 0, len(results)),
+	}
+	if lesson != nil {
+		report.Lesson = lesson.ID
+		report.Title = lesson.Title
+	}
+	for _, res := range results {
+		cr := CheckReport{
+			ID:         res.Check.ID,
+			Title:      res.Check.Title,
+			Status:     StatusOf(res),
+			Message:    res.Outcome.Message,
+			Weight:     res.Check.Points(),
+			Optional:   res.Check.Optional,
+			Bonus:      res.Check.Bonus,
+			DurationMS: res.Duration.Round(time.Millisecond).Milliseconds(),
+		}
+		if err := res.Outcome.Err; err != nil {
+			cr.Error = err.Error()
+			cr.ErrorKind = string(Classify(err))
+			cr.Hint = Describe(err)
+		}
+		if res.Passed() && !res.Check.Optional {
+			cr.Points = res.Check.Points()
+		}
+		report.Checks = append(report.Checks, cr)
+	}
+	return report
+}
+
+// StatusOf classifies a result as passed, failed, error or skipped.
+func StatusOf(res Result) string {
+	switch {
+	case res.Skipped:
+		return StatusSkipped
+	case res.Outcome.Err != nil:
+		return StatusError
+	case res.Outcome.Passed:
+		return StatusPassed
+	default:
+		return StatusFailed
+	}
+}
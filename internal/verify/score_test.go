@@ -0,0 +1,52 @@
+package verify
+
+import (
+	"testing"
+
+	"github.com/rohit746/tscgit/internal/lessons"
+)
+
+func TestGradeWeightsOptionalAndBonus(t *testing.T) {
+	lesson := &lessons.Lesson{
+		ID: "scored",
+		Checks: []lessons.Check{
+			{ID: "a", Weight: 3},
+			{ID: "b"},
+			{ID: "c", Weight: 2, Optional: true},
+			{ID: "d", Weight: 2, Bonus: true},
+		},
+	}
+	pass := lessons.CheckResult{Passed: true}
+	fail := lessons.CheckResult{Passed: false}
+	results := []Result{
+		{Check: lesson.Checks[0], Outcome: pass},
+		{Check: lesson.Checks[1], Outcome: fail},
+		{Check: lesson.Checks[2], Outcome: pass},
+		{Check: lesson.Checks[3], Outcome: fail},
+	}
+
+	score := Grade(lesson, results)
+	if score.Points != 3 || score.Max != 4 || score.Percent != 75 {
+		t.Fatalf("unexpected score: %+v", score)
+	}
+	if AllPassed(lesson, results) {
+		t.Fatalf("expected required check b to fail the lesson")
+	}
+
+	results[1].Outcome = pass
+	results[3].Outcome = pass
+	score = Grade(lesson, results)
+	if score.Points != 6 || score.Max != 4 || score.Percent != 100 {
+		t.Fatalf("expected bonus points capped at 100%%, got %+v", score)
+	}
+
+	results[2].Outcome = fail
+	if !AllPassed(lesson, results) {
+		t.Fatalf("expected optional failure not to fail the lesson")
+	}
+
+	report := NewReport(lesson, results)
+	if report.Checks[2].Status != StatusFailed || report.Checks[2].Points != 0 {
+		t.Fatalf("unexpected optional check report: %+v", report.Checks[2])
+	}
+}